@@ -0,0 +1,139 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// Разрешения ключей доступа.
+const (
+	ScopeSongsRead   = "songs:read"   // Чтение песен, заданий импорта и экспорт
+	ScopeSongsWrite  = "songs:write"  // Создание и изменение песен, импорт
+	ScopeSongsDelete = "songs:delete" // Удаление песен
+	ScopeAdmin       = "admin"        // Резервные копии, ключи доступа и все остальные разрешения
+)
+
+// Scopes перечисляет все известные разрешения.
+var Scopes = []string{ScopeSongsRead, ScopeSongsWrite, ScopeSongsDelete, ScopeAdmin}
+
+// Роли пользователей.
+const (
+	RoleViewer = "viewer" // Чтение песен
+	RoleEditor = "editor" // Чтение и изменение песен, удаление только своих
+	RoleAdmin  = "admin"  // Все действия, включая управление пользователями
+)
+
+// Roles перечисляет все роли пользователей.
+var Roles = []string{RoleViewer, RoleEditor, RoleAdmin}
+
+// roleScopes сопоставляет ролям пользователей разрешения.
+var roleScopes = map[string][]string{
+	RoleViewer: {ScopeSongsRead},
+	RoleEditor: {ScopeSongsRead, ScopeSongsWrite, ScopeSongsDelete},
+	RoleAdmin:  {ScopeAdmin},
+}
+
+// Виды участников запроса.
+const (
+	PrincipalAnonymous = "anonymous" // Запрос без учетных данных при разрешенном анонимном чтении
+	PrincipalAPIKey    = "api_key"   // Запрос с ключом доступа
+	PrincipalUser      = "user"      // Запрос с токеном доступа пользователя
+)
+
+var (
+	// ErrUnauthenticated возвращается, если учетные данные не переданы или недействительны.
+	ErrUnauthenticated = errors.New("invalid or missing credentials")
+	// ErrInvalidScope возвращается для неизвестного разрешения.
+	ErrInvalidScope = errors.New("unknown scope")
+	// ErrInvalidRole возвращается для неизвестной роли.
+	ErrInvalidRole = errors.New("role must be viewer, editor or admin")
+)
+
+// Principal - участник, от имени которого выполняется запрос.
+type Principal struct {
+	Type   string   // anonymous, api_key или user
+	ID     uint     // ID ключа доступа или пользователя
+	Name   string   // Имя ключа доступа или пользователя
+	Role   string   // Роль пользователя
+	Scopes []string // Разрешения
+
+	RateLimits map[string]int // Индивидуальные лимиты запросов по классам вместо лимитов из конфигурации
+}
+
+// UserID возвращает ID пользователя, от имени которого выполняется запрос, или nil для остальных участников.
+func (p *Principal) UserID() *uint {
+	if p == nil || p.Type != PrincipalUser {
+		return nil
+	}
+	id := p.ID
+	return &id
+}
+
+// CanDelete сообщает, может ли участник удалить запись, созданную пользователем ownerID.
+// Редакторы удаляют только свои записи, остальные ограничиваются разрешениями.
+func (p *Principal) CanDelete(ownerID *uint) bool {
+	if p == nil || p.Type != PrincipalUser || p.HasScope(ScopeAdmin) {
+		return true
+	}
+	return ownerID != nil && *ownerID == p.ID
+}
+
+// HasScope сообщает, есть ли у участника разрешение. Разрешение admin включает все остальные.
+func (p *Principal) HasScope(scope string) bool {
+	for _, granted := range p.Scopes {
+		if granted == scope || granted == ScopeAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidScope сообщает, известно ли разрешение.
+func ValidScope(scope string) bool {
+	for _, known := range Scopes {
+		if known == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseScopes разбирает список разрешений через запятую и проверяет, что все они известны.
+func ParseScopes(value string) ([]string, error) {
+	var scopes []string
+	for _, scope := range strings.Split(value, ",") {
+		if scope = strings.TrimSpace(scope); scope == "" {
+			continue
+		}
+		if !ValidScope(scope) {
+			return nil, ErrInvalidScope
+		}
+		scopes = append(scopes, scope)
+	}
+	return scopes, nil
+}
+
+// ValidRole сообщает, известна ли роль.
+func ValidRole(role string) bool {
+	_, ok := roleScopes[role]
+	return ok
+}
+
+// RoleScopes возвращает разрешения роли.
+func RoleScopes(role string) []string {
+	return roleScopes[role]
+}
+
+type principalKey struct{}
+
+// WithPrincipal возвращает контекст с участником запроса.
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFrom возвращает участника запроса из контекста или nil.
+func PrincipalFrom(ctx context.Context) *Principal {
+	principal, _ := ctx.Value(principalKey{}).(*Principal)
+	return principal
+}
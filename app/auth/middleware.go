@@ -0,0 +1,145 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"music-library/app/config"
+	"music-library/app/models"
+)
+
+// HeaderAPIKey - альтернативный заголовку Authorization заголовок с ключом доступа.
+const HeaderAPIKey = "X-API-Key"
+
+// realm - область защиты в заголовке WWW-Authenticate.
+const realm = "music-library"
+
+// Authenticator проверяет учетные данные запроса и возвращает участника.
+// Для недействительных данных возвращается ErrUnauthenticated.
+type Authenticator func(ctx context.Context, credential string) (*Principal, error)
+
+// publicRoutes - маршруты, доступные без учетных данных: проверки состояния, метрики, документация
+// и получение токенов.
+var publicRoutes = map[string]bool{
+	"/healthz":  true,
+	"/readyz":   true,
+	"/status":   true,
+	"/metrics":  true,
+	"/swagger/": true,
+
+	"/auth/register": true,
+	"/auth/login":    true,
+	"/auth/refresh":  true,
+	"/auth/logout":   true,
+}
+
+// RequiredScope возвращает разрешение, необходимое для запроса к маршруту с шаблоном template.
+// Пустая строка означает, что маршрут публичный.
+func RequiredScope(method, template string) string {
+	switch {
+	case publicRoutes[template]:
+		return ""
+	case strings.HasPrefix(template, "/admin/"), template == "/audit", strings.HasPrefix(template, "/webhooks"):
+		return ScopeAdmin
+	case method == http.MethodGet || method == http.MethodHead, template == "/graphql":
+		// Мутации GraphQL проверяют разрешения на изменение сами.
+		return ScopeSongsRead
+	case method == http.MethodDelete:
+		return ScopeSongsDelete
+	default:
+		return ScopeSongsWrite
+	}
+}
+
+// Middleware проверяет ключ доступа или токен пользователя из заголовка Authorization: Bearer
+// (ключ также принимается в X-API-Key) и наличие у участника разрешения, которого требует маршрут. Участник запроса кладется в контекст.
+func Middleware(authenticate Authenticator) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			settings := config.Get().Auth
+			template := ""
+			if route := mux.CurrentRoute(r); route != nil {
+				template, _ = route.GetPathTemplate()
+			}
+			scope := RequiredScope(r.Method, template)
+			if !settings.Enabled || scope == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			credential, ok := credentialFrom(r)
+			if !ok {
+				writeUnauthorized(w, r, "Authorization header must use the Bearer scheme")
+				return
+			}
+
+			var principal *Principal
+			switch {
+			case credential != "":
+				var err error
+				principal, err = authenticate(r.Context(), credential)
+				if errors.Is(err, ErrUnauthenticated) {
+					writeUnauthorized(w, r, "Invalid or expired credentials")
+					return
+				}
+				if err != nil {
+					slog.ErrorContext(r.Context(), "Failed to authenticate request", "error", err)
+					writeError(w, http.StatusInternalServerError, "Failed to authenticate request")
+					return
+				}
+			case settings.AnonymousRead && scope == ScopeSongsRead:
+				principal = &Principal{Type: PrincipalAnonymous, Scopes: []string{ScopeSongsRead}}
+			default:
+				writeUnauthorized(w, r, "Authentication required")
+				return
+			}
+
+			if !principal.HasScope(scope) {
+				slog.InfoContext(r.Context(), "Insufficient scope", "principal", principal.Name, "scope", scope)
+				w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm=%q, error="insufficient_scope", scope=%q`, realm, scope))
+				writeError(w, http.StatusForbidden, "Scope "+scope+" is required")
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(WithPrincipal(r.Context(), principal)))
+		})
+	}
+}
+
+// credentialFrom извлекает ключ доступа или токен из заголовков запроса.
+// Возвращает false, если заголовок Authorization задан не по схеме Bearer.
+func credentialFrom(r *http.Request) (string, bool) {
+	if key := r.Header.Get(HeaderAPIKey); key != "" {
+		return key, true
+	}
+	header := r.Header.Get("Authorization")
+	if header == "" {
+		return "", true
+	}
+	scheme, token, ok := strings.Cut(header, " ")
+	token = strings.TrimSpace(token)
+	if !ok || !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return "", false
+	}
+	return token, true
+}
+
+func writeUnauthorized(w http.ResponseWriter, r *http.Request, message string) {
+	slog.InfoContext(r.Context(), "Unauthenticated request", "reason", message)
+	w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm=%q`, realm))
+	writeError(w, http.StatusUnauthorized, message)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(models.ErrorResponse{
+		Code:    status,
+		Message: message,
+	})
+}
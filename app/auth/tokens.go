@@ -0,0 +1,124 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"music-library/app/config"
+)
+
+// issuer - издатель токенов доступа (iss).
+const issuer = "music-library"
+
+// ErrTokensNotConfigured возвращается при выпуске токена до вызова Setup.
+var ErrTokensNotConfigured = errors.New("token signing is not configured")
+
+// signer хранит ключи подписи токенов доступа.
+type signer struct {
+	keys map[string][]byte // Секреты по идентификатору ключа
+	kid  string            // Ключ, которым подписываются новые токены
+	ttl  time.Duration     // Время жизни токена доступа
+}
+
+var (
+	mu     sync.RWMutex
+	tokens *signer
+)
+
+// claims - содержимое токена доступа.
+type claims struct {
+	Name string `json:"name"`
+	Role string `json:"role"`
+	jwt.RegisteredClaims
+}
+
+// Setup настраивает подпись токенов доступа ключами из конфигурации.
+// Без ключей создается случайный ключ, и после перезапуска пользователям нужно обновить токены.
+func Setup(settings config.AuthConfig) error {
+	keys, err := settings.SigningKeys()
+	if err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		slog.Warn("Token signing keys are not set, using a temporary key; access tokens will not survive a restart",
+			"env", "AUTH_JWT_KEYS")
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return err
+		}
+		keys = []config.SigningKey{{ID: "temporary", Secret: hex.EncodeToString(secret)}}
+	}
+
+	s := &signer{keys: make(map[string][]byte, len(keys)), kid: keys[0].ID, ttl: settings.AccessTokenTTL}
+	for _, key := range keys {
+		s.keys[key.ID] = []byte(key.Secret)
+	}
+	mu.Lock()
+	tokens = s
+	mu.Unlock()
+	slog.Debug("Token signing configured", "kid", s.kid, "keys", len(keys))
+	return nil
+}
+
+// IssueAccessToken выпускает токен доступа пользователя и возвращает его вместе со временем истечения.
+func IssueAccessToken(userID uint, name, role string) (string, time.Time, error) {
+	mu.RLock()
+	s := tokens
+	mu.RUnlock()
+	if s == nil {
+		return "", time.Time{}, ErrTokensNotConfigured
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(s.ttl)
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims{
+		Name: name,
+		Role: role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    issuer,
+			Subject:   strconv.FormatUint(uint64(userID), 10),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	})
+	token.Header["kid"] = s.kid
+	signed, err := token.SignedString(s.keys[s.kid])
+	return signed, expiresAt, err
+}
+
+// ParseAccessToken проверяет подпись и срок действия токена доступа и возвращает пользователя.
+// Токен проверяется ключом из его заголовка kid, поэтому токены, подписанные предыдущим ключом,
+// действуют, пока этот ключ остается в конфигурации.
+func ParseAccessToken(value string) (*Principal, error) {
+	mu.RLock()
+	s := tokens
+	mu.RUnlock()
+	if s == nil {
+		return nil, ErrUnauthenticated
+	}
+
+	var parsed claims
+	_, err := jwt.ParseWithClaims(value, &parsed, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		secret, ok := s.keys[kid]
+		if !ok {
+			return nil, errors.New("unknown signing key")
+		}
+		return secret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithIssuer(issuer), jwt.WithExpirationRequired())
+	if err != nil {
+		return nil, ErrUnauthenticated
+	}
+
+	id, err := strconv.ParseUint(parsed.Subject, 10, 64)
+	if err != nil || !ValidRole(parsed.Role) {
+		return nil, ErrUnauthenticated
+	}
+	return &Principal{Type: PrincipalUser, ID: uint(id), Name: parsed.Name, Role: parsed.Role, Scopes: RoleScopes(parsed.Role)}, nil
+}
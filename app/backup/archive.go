@@ -0,0 +1,330 @@
+package backup
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+)
+
+// FormatVersion - версия формата архива. Увеличивается при несовместимых изменениях структуры архива.
+const FormatVersion = 1
+
+// ManifestFile - имя файла с описанием архива. Он всегда идет первым.
+const ManifestFile = "manifest.json"
+
+// ContentType - MIME-тип архива резервной копии.
+const ContentType = "application/gzip"
+
+// ErrInvalidArchive возвращается, если архив поврежден или не является резервной копией библиотеки.
+var ErrInvalidArchive = errors.New("invalid backup archive")
+
+// Manifest описывает содержимое архива резервной копии.
+type Manifest struct {
+	FormatVersion int         `json:"formatVersion"` // Версия формата архива
+	SchemaVersion int         `json:"schemaVersion"` // Версия схемы базы данных, с которой снята копия
+	CreatedAt     time.Time   `json:"createdAt"`     // Время создания копии
+	Tables        []TableInfo `json:"tables"`        // Таблицы в порядке восстановления
+}
+
+// TableInfo описывает файл с данными одной таблицы.
+type TableInfo struct {
+	Name   string `json:"name"`   // Имя таблицы
+	File   string `json:"file"`   // Имя файла NDJSON в архиве
+	Rows   int    `json:"rows"`   // Количество записей
+	SHA256 string `json:"sha256"` // Контрольная сумма файла
+}
+
+// Table возвращает описание таблицы по имени.
+func (m *Manifest) Table(name string) (TableInfo, bool) {
+	for _, table := range m.Tables {
+		if table.Name == name {
+			return table, true
+		}
+	}
+	return TableInfo{}, false
+}
+
+// FileName возвращает имя файла архива для резервной копии, созданной в момент t.
+func FileName(t time.Time) string {
+	return "music-library-" + t.UTC().Format("20060102-150405") + ".tar.gz"
+}
+
+// Writer собирает таблицы во временном каталоге, чтобы посчитать их контрольные суммы
+// до записи манифеста, и затем выгружает архив потоком.
+type Writer struct {
+	dir      string
+	manifest Manifest
+}
+
+// NewWriter создает архив для схемы базы данных версии schemaVersion.
+func NewWriter(schemaVersion int) (*Writer, error) {
+	dir, err := os.MkdirTemp("", "music-library-backup-*")
+	if err != nil {
+		return nil, err
+	}
+	return &Writer{
+		dir: dir,
+		manifest: Manifest{
+			FormatVersion: FormatVersion,
+			SchemaVersion: schemaVersion,
+			CreatedAt:     time.Now().UTC(),
+		},
+	}, nil
+}
+
+// Table добавляет в архив таблицу. Функция fn записывает строки через encode и возвращает их количество.
+func (w *Writer) Table(name string, fn func(encode func(row interface{}) error) (int, error)) error {
+	info := TableInfo{Name: name, File: name + ".ndjson"}
+	file, err := os.Create(filepath.Join(w.dir, info.File))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	buffered := bufio.NewWriter(io.MultiWriter(file, hash))
+	encoder := json.NewEncoder(buffered)
+	encoder.SetEscapeHTML(false)
+
+	info.Rows, err = fn(func(row interface{}) error {
+		return encoder.Encode(row)
+	})
+	if err != nil {
+		return err
+	}
+	if err := buffered.Flush(); err != nil {
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	info.SHA256 = hex.EncodeToString(hash.Sum(nil))
+	w.manifest.Tables = append(w.manifest.Tables, info)
+	return nil
+}
+
+// Manifest возвращает описание уже добавленных таблиц.
+func (w *Writer) Manifest() Manifest {
+	return w.manifest
+}
+
+// WriteTo записывает сжатый tar-архив: сначала манифест, затем файлы таблиц.
+func (w *Writer) WriteTo(out io.Writer) (int64, error) {
+	counter := &countingWriter{w: out}
+	compressed := gzip.NewWriter(counter)
+	archive := tar.NewWriter(compressed)
+
+	manifest, err := json.MarshalIndent(w.manifest, "", "  ")
+	if err != nil {
+		return counter.n, err
+	}
+	header := &tar.Header{Name: ManifestFile, Mode: 0o644, Size: int64(len(manifest)), ModTime: w.manifest.CreatedAt}
+	if err := archive.WriteHeader(header); err != nil {
+		return counter.n, err
+	}
+	if _, err := archive.Write(manifest); err != nil {
+		return counter.n, err
+	}
+
+	for _, table := range w.manifest.Tables {
+		if err := w.writeFile(archive, table.File); err != nil {
+			return counter.n, err
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return counter.n, err
+	}
+	err = compressed.Close()
+	return counter.n, err
+}
+
+func (w *Writer) writeFile(archive *tar.Writer, name string) error {
+	file, err := os.Open(filepath.Join(w.dir, name))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	header := &tar.Header{Name: name, Mode: 0o644, Size: info.Size(), ModTime: w.manifest.CreatedAt}
+	if err := archive.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err = io.Copy(archive, file)
+	return err
+}
+
+// Close удаляет временные файлы.
+func (w *Writer) Close() error {
+	return os.RemoveAll(w.dir)
+}
+
+// Archive - распакованная и проверенная резервная копия.
+type Archive struct {
+	dir      string
+	Manifest Manifest
+}
+
+// Open распаковывает архив во временный каталог и проверяет манифест, контрольные суммы
+// и количество строк всех таблиц. Данные не читаются в базу, пока архив не проверен целиком.
+func Open(r io.Reader) (*Archive, error) {
+	dir, err := os.MkdirTemp("", "music-library-restore-*")
+	if err != nil {
+		return nil, err
+	}
+	archive := &Archive{dir: dir}
+	if err := archive.extract(r); err != nil {
+		archive.Close()
+		return nil, err
+	}
+	return archive, nil
+}
+
+func (a *Archive) extract(r io.Reader) error {
+	compressed, err := gzip.NewReader(r)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+	}
+	defer compressed.Close()
+	archive := tar.NewReader(compressed)
+
+	header, err := archive.Next()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+	}
+	if header.Name != ManifestFile {
+		return fmt.Errorf("%w: %s must be the first file", ErrInvalidArchive, ManifestFile)
+	}
+	if err := json.NewDecoder(archive).Decode(&a.Manifest); err != nil {
+		return fmt.Errorf("%w: failed to read manifest: %v", ErrInvalidArchive, err)
+	}
+	if a.Manifest.FormatVersion != FormatVersion {
+		return fmt.Errorf("%w: unsupported format version %d", ErrInvalidArchive, a.Manifest.FormatVersion)
+	}
+
+	expected := make(map[string]TableInfo, len(a.Manifest.Tables))
+	for _, table := range a.Manifest.Tables {
+		if table.File != path.Base(table.File) || table.File == ManifestFile {
+			return fmt.Errorf("%w: invalid file name %q", ErrInvalidArchive, table.File)
+		}
+		expected[table.File] = table
+	}
+
+	for {
+		header, err := archive.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+		}
+		table, ok := expected[header.Name]
+		if !ok {
+			return fmt.Errorf("%w: unexpected file %q", ErrInvalidArchive, header.Name)
+		}
+		delete(expected, header.Name)
+		if err := a.extractTable(archive, table); err != nil {
+			return err
+		}
+	}
+
+	for name := range expected {
+		return fmt.Errorf("%w: missing file %q", ErrInvalidArchive, name)
+	}
+	return nil
+}
+
+// extractTable сохраняет файл таблицы и сверяет его контрольную сумму и количество строк с манифестом.
+func (a *Archive) extractTable(r io.Reader, table TableInfo) error {
+	file, err := os.Create(filepath.Join(a.dir, table.File))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	lines := &lineCounter{}
+	if _, err := io.Copy(io.MultiWriter(file, hash, lines), r); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+	}
+	if sum := hex.EncodeToString(hash.Sum(nil)); sum != table.SHA256 {
+		return fmt.Errorf("%w: checksum mismatch for %s", ErrInvalidArchive, table.File)
+	}
+	if lines.n != table.Rows {
+		return fmt.Errorf("%w: %s has %d rows, manifest declares %d", ErrInvalidArchive, table.File, lines.n, table.Rows)
+	}
+	return file.Close()
+}
+
+// Table последовательно передает в fn строки таблицы. Функция decode разбирает строку в переданное значение.
+// Отсутствующая в архиве таблица считается пустой.
+func (a *Archive) Table(name string, fn func(decode func(row interface{}) error) error) error {
+	table, ok := a.Manifest.Table(name)
+	if !ok {
+		return nil
+	}
+
+	file, err := os.Open(filepath.Join(a.dir, table.File))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	decoder := json.NewDecoder(bufio.NewReader(file))
+	for i := 0; i < table.Rows; i++ {
+		err := fn(func(row interface{}) error {
+			if err := decoder.Decode(row); err != nil {
+				return fmt.Errorf("%w: %s row %d: %v", ErrInvalidArchive, table.File, i+1, err)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close удаляет распакованные файлы.
+func (a *Archive) Close() error {
+	return os.RemoveAll(a.dir)
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+type lineCounter struct {
+	n int
+}
+
+func (c *lineCounter) Write(p []byte) (int, error) {
+	for _, b := range p {
+		if b == '\n' {
+			c.n++
+		}
+	}
+	return len(p), nil
+}
@@ -0,0 +1,239 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"music-library/app/auth"
+	"music-library/app/database"
+	"music-library/app/models"
+	"music-library/app/ratelimit"
+	"music-library/app/services"
+)
+
+func init() {
+	register(Command{
+		Name:  "api-keys",
+		Usage: "Manage API keys: create, list, revoke",
+		Run:   runAPIKeys,
+	})
+}
+
+var apiKeysCommands = []Command{
+	{Name: "create", Usage: "Create an API key and print it once", Run: runAPIKeysCreate},
+	{Name: "list", Usage: "List API keys", Run: runAPIKeysList},
+	{Name: "revoke", Usage: "Revoke an API key by ID", Run: runAPIKeysRevoke},
+}
+
+func runAPIKeys(args []string) error {
+	if len(args) > 0 {
+		for _, command := range apiKeysCommands {
+			if command.Name == args[0] {
+				return command.Run(args[1:])
+			}
+		}
+	}
+
+	fmt.Fprintln(os.Stderr, "Usage: music-library api-keys <command> [flags]\n\nCommands:")
+	for _, command := range apiKeysCommands {
+		fmt.Fprintf(os.Stderr, "  %-8s %s\n", command.Name, command.Usage)
+	}
+	if len(args) == 0 {
+		return usageErrorf("api-keys command must be specified")
+	}
+	return usageErrorf("unknown api-keys command %q", args[0])
+}
+
+func runAPIKeysCreate(args []string) error {
+	flags := flag.NewFlagSet("api-keys create", flag.ContinueOnError)
+	output := outputFlag(flags)
+	name := flags.String("name", "", "purpose of the key (required)")
+	scopes := flags.String("scopes", auth.ScopeSongsRead, "comma-separated scopes: "+strings.Join(auth.Scopes, ", "))
+	ttl := flags.Duration("ttl", 0, "key lifetime, e.g. 720h (0 - never expires)")
+	rateLimits := flags.String("rate-limits", "", "requests per rate limit window overriding the defaults, e.g. read=1200,enrich=10")
+	databaseFlags(flags)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: music-library api-keys create -name <name> [flags]")
+		flags.PrintDefaults()
+	}
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if err := checkOutput(*output); err != nil {
+		return err
+	}
+	parsed, err := auth.ParseScopes(*scopes)
+	if err != nil {
+		return usageErrorf("%s", err)
+	}
+	if *ttl < 0 {
+		return usageErrorf("ttl must not be negative")
+	}
+	request := models.CreateAPIKeyRequest{Name: *name, Scopes: parsed}
+	if *ttl > 0 {
+		expires := time.Now().Add(*ttl)
+		request.ExpiresAt = &expires
+	}
+	if request.RateLimits, err = parseRateLimits(*rateLimits); err != nil {
+		return err
+	}
+
+	if err := connect(); err != nil {
+		return err
+	}
+
+	created, err := services.CreateAPIKey(database.DB, request)
+	if errors.Is(err, services.ErrInvalidAPIKey) {
+		return usageErrorf("%s", err)
+	}
+	if err != nil {
+		return err
+	}
+
+	if *output == outputJSON {
+		return printJSON(created)
+	}
+	if err := printAPIKeys([]models.APIKey{created.APIKey}); err != nil {
+		return err
+	}
+	fmt.Printf("\nKey (shown only once): %s\n", created.Key)
+	return nil
+}
+
+func runAPIKeysList(args []string) error {
+	flags := flag.NewFlagSet("api-keys list", flag.ContinueOnError)
+	output := outputFlag(flags)
+	databaseFlags(flags)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: music-library api-keys list [flags]")
+		flags.PrintDefaults()
+	}
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if err := checkOutput(*output); err != nil {
+		return err
+	}
+
+	if err := connect(); err != nil {
+		return err
+	}
+
+	keys, err := services.ListAPIKeys(database.DB)
+	if err != nil {
+		return err
+	}
+	if *output == outputJSON {
+		if keys == nil {
+			keys = []models.APIKey{}
+		}
+		return printJSON(keys)
+	}
+	return printAPIKeys(keys)
+}
+
+func runAPIKeysRevoke(args []string) error {
+	flags := flag.NewFlagSet("api-keys revoke", flag.ContinueOnError)
+	output := outputFlag(flags)
+	databaseFlags(flags)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: music-library api-keys revoke [flags] <id>")
+		flags.PrintDefaults()
+	}
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if err := checkOutput(*output); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return usageErrorf("exactly one API key ID must be specified")
+	}
+	id, err := strconv.ParseUint(flags.Arg(0), 10, 64)
+	if err != nil {
+		return usageErrorf("invalid API key ID %q", flags.Arg(0))
+	}
+
+	if err := connect(); err != nil {
+		return err
+	}
+
+	key, err := services.RevokeAPIKey(database.DB, id)
+	if err != nil {
+		return err
+	}
+	if *output == outputJSON {
+		return printJSON(key)
+	}
+	fmt.Printf("Revoked API key %d: %s\n", key.ID, key.Name)
+	return nil
+}
+
+// printAPIKeys выводит ключи доступа таблицей с их состоянием.
+func printAPIKeys(keys []models.APIKey) error {
+	now := time.Now()
+	table := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "ID\tNAME\tPREFIX\tSCOPES\tRATE LIMITS\tSTATE\tEXPIRES\tLAST USED")
+	for _, key := range keys {
+		state := "active"
+		switch {
+		case key.RevokedAt != nil:
+			state = "revoked"
+		case !key.Active(now):
+			state = "expired"
+		}
+		fmt.Fprintf(table, "%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", key.ID, key.Name, key.Prefix,
+			strings.Join(key.Scopes, ","), formatRateLimits(key.RateLimits), state,
+			formatTime(key.ExpiresAt), formatTime(key.LastUsedAt))
+	}
+	return table.Flush()
+}
+
+// parseRateLimits разбирает лимиты запросов вида "read=1200,enrich=10".
+func parseRateLimits(value string) (map[string]int, error) {
+	var limits map[string]int
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+		class, count, _ := strings.Cut(item, "=")
+		limit, err := strconv.Atoi(count)
+		if !ratelimit.ValidClass(class) || err != nil || limit <= 0 {
+			return nil, usageErrorf("invalid rate limit %q, use read, write or enrich with a positive number", item)
+		}
+		if limits == nil {
+			limits = make(map[string]int)
+		}
+		limits[class] = limit
+	}
+	return limits, nil
+}
+
+// formatRateLimits выводит индивидуальные лимиты ключа или прочерк.
+func formatRateLimits(limits models.RateLimits) string {
+	var items []string
+	for _, class := range ratelimit.Classes {
+		if limit, ok := limits[class]; ok {
+			items = append(items, fmt.Sprintf("%s=%d", class, limit))
+		}
+	}
+	if len(items) == 0 {
+		return "-"
+	}
+	return strings.Join(items, ",")
+}
+
+// formatTime выводит необязательное время в RFC 3339 или прочерк.
+func formatTime(value *time.Time) string {
+	if value == nil {
+		return "-"
+	}
+	return value.Format(time.RFC3339)
+}
@@ -0,0 +1,119 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"music-library/app/backup"
+	"music-library/app/database"
+	"music-library/app/services"
+)
+
+func init() {
+	register(Command{
+		Name:  "backup",
+		Usage: "Create a compressed backup of the whole library",
+		Run:   runBackup,
+	})
+	register(Command{
+		Name:  "restore",
+		Usage: "Restore the library from a backup archive",
+		Run:   runRestore,
+	})
+}
+
+func runBackup(args []string) error {
+	flags := flag.NewFlagSet("backup", flag.ContinueOnError)
+	output := flags.String("o", "", "output file, - for stdout (music-library-<time>.tar.gz by default)")
+	databaseFlags(flags)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: music-library backup [flags]")
+		flags.PrintDefaults()
+	}
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if *output == "" {
+		*output = backup.FileName(time.Now())
+	}
+
+	if err := connect(); err != nil {
+		return err
+	}
+
+	if *output == "-" {
+		_, err := services.CreateBackup(database.DB, os.Stdout)
+		return err
+	}
+
+	// Как и при экспорте, архив появляется под своим именем только после успешной записи.
+	temp, err := os.CreateTemp(filepath.Dir(*output), filepath.Base(*output)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+
+	manifest, err := services.CreateBackup(database.DB, temp)
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(temp.Name(), *output); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Backup written to %s (schema version %d)\n", *output, manifest.SchemaVersion)
+	for _, table := range manifest.Tables {
+		fmt.Fprintf(os.Stderr, "  %-18s %d rows, sha256 %s\n", table.Name, table.Rows, table.SHA256)
+	}
+	return nil
+}
+
+func runRestore(args []string) error {
+	flags := flag.NewFlagSet("restore", flag.ContinueOnError)
+	mode := flags.String("mode", services.RestoreModeEmpty, "empty (keep IDs, all tables must be empty) or merge (add songs and import jobs to the existing library with new IDs)")
+	databaseFlags(flags)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: music-library restore [flags] <archive>")
+		flags.PrintDefaults()
+	}
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return usageErrorf("exactly one archive must be specified")
+	}
+	if !services.ValidRestoreMode(*mode) {
+		return usageErrorf("%s", services.ErrInvalidRestoreMode)
+	}
+
+	file, err := os.Open(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if err := connect(); err != nil {
+		return err
+	}
+
+	result, err := services.RestoreBackup(database.DB, file, *mode)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Restored backup (schema version %d) in %s mode\n", result.SchemaVersion, result.Mode)
+	for _, table := range result.Tables {
+		fmt.Printf("  %-18s %d rows: %d created, %d skipped\n", table.Name, table.Rows, table.Created, table.Skipped)
+	}
+	if result.RemappedIDs > 0 {
+		fmt.Printf("%d records got new IDs\n", result.RemappedIDs)
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"music-library/app/config"
+	"music-library/app/database"
+	"music-library/app/logging"
+	"music-library/app/services"
+)
+
+// Коды завершения процесса.
+const (
+	ExitOK       = 0 // Команда выполнена
+	ExitError    = 1 // Ошибка выполнения
+	ExitUsage    = 2 // Неизвестная команда или неверные аргументы
+	ExitNotFound = 3 // Запрошенная запись не найдена
+	ExitConflict = 4 // Запись уже существует или была изменена
+)
+
+// Command описывает подкоманду командной строки music-library.
+type Command struct {
+	Name  string                    // Имя подкоманды
+	Usage string                    // Краткое описание для справки
+	Run   func(args []string) error // Выполнение подкоманды с оставшимися аргументами
+}
+
+// usageError - ошибка в аргументах команды, завершает процесс с кодом ExitUsage.
+type usageError struct {
+	message string
+}
+
+func (e usageError) Error() string {
+	return e.message
+}
+
+func usageErrorf(format string, args ...interface{}) error {
+	return usageError{message: fmt.Sprintf(format, args...)}
+}
+
+var commands []Command
+
+func register(command Command) {
+	commands = append(commands, command)
+}
+
+// Run выполняет подкоманду, указанную первым аргументом, и возвращает код завершения процесса.
+func Run(args []string) int {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		printUsage()
+		if len(args) == 0 {
+			return ExitUsage
+		}
+		return ExitOK
+	}
+
+	for _, command := range commands {
+		if command.Name != args[0] {
+			continue
+		}
+		err := command.Run(args[1:])
+		if err != nil && !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintln(os.Stderr, "ERROR:", err)
+		}
+		return exitCode(err)
+	}
+
+	fmt.Fprintf(os.Stderr, "Unknown command %q\n\n", args[0])
+	printUsage()
+	return ExitUsage
+}
+
+// exitCode сопоставляет ошибку команды с кодом завершения.
+func exitCode(err error) int {
+	var usage usageError
+	switch {
+	case err == nil, errors.Is(err, flag.ErrHelp):
+		return ExitOK
+	case errors.As(err, &usage), errors.Is(err, config.ErrInvalidConfig):
+		return ExitUsage
+	case errors.Is(err, services.ErrSongNotFound), errors.Is(err, services.ErrImportNotFound),
+		errors.Is(err, services.ErrAPIKeyNotFound), errors.Is(err, services.ErrUserNotFound):
+		return ExitNotFound
+	case errors.Is(err, services.ErrDuplicateSong), errors.Is(err, services.ErrVersionConflict),
+		errors.Is(err, services.ErrDatabaseNotEmpty), errors.Is(err, services.ErrUserExists),
+		errors.Is(err, services.ErrImportRunning), errors.Is(err, services.ErrImportCompleted):
+		return ExitConflict
+	default:
+		return ExitError
+	}
+}
+
+func printUsage() {
+	fmt.Fprintln(os.Stderr, "Usage: music-library [command] [flags]")
+	fmt.Fprintln(os.Stderr, "\nWithout a command the HTTP server is started (same as serve).\n\nCommands:")
+	for _, command := range commands {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", command.Name, command.Usage)
+	}
+	fmt.Fprintln(os.Stderr, "\nRun music-library <command> -h for the command flags.")
+	fmt.Fprintf(os.Stderr, "\nExit codes: %d ok, %d error, %d usage error, %d not found, %d conflict\n",
+		ExitOK, ExitError, ExitUsage, ExitNotFound, ExitConflict)
+}
+
+// parseFlags разбирает флаги команды, превращая ошибки разбора в ошибки использования.
+func parseFlags(flags *flag.FlagSet, args []string) error {
+	flags.SetOutput(os.Stderr)
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return usageError{message: err.Error()}
+	}
+	return nil
+}
+
+// envFlag регистрирует флаг, который переопределяет переменную окружения конфигурации.
+// Значение выставляется в окружение при разборе флагов, поэтому оно имеет приоритет над файлом .env.
+func envFlag(flags *flag.FlagSet, name, env, usage string) {
+	flags.Func(name, usage+" (overrides "+env+")", func(value string) error {
+		return os.Setenv(env, value)
+	})
+}
+
+// envBoolFlag - envFlag для логических настроек, допускающий запись без значения (-flag).
+func envBoolFlag(flags *flag.FlagSet, name, env, usage string) {
+	flags.BoolFunc(name, usage+" (overrides "+env+")", func(value string) error {
+		return os.Setenv(env, value)
+	})
+}
+
+// configFlag регистрирует флаг с путем к файлу конфигурации.
+func configFlag(flags *flag.FlagSet) {
+	envFlag(flags, "config", "CONFIG_FILE", "configuration file in YAML or TOML format")
+}
+
+// databaseFlags регистрирует флаги подключения к базе данных, общие для всех команд, работающих с ней.
+func databaseFlags(flags *flag.FlagSet) {
+	configFlag(flags)
+	envFlag(flags, "database-url", "DATABASE_URL", "PostgreSQL connection string")
+	envBoolFlag(flags, "migrate", "MIGRATE_ON_START", "apply pending migrations before running the command")
+}
+
+// loadConfig загружает конфигурацию и настраивает по ней журнал.
+func loadConfig() (*config.Config, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+	if err := logging.Setup(cfg.Log.Level, cfg.Log.Format); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// connect загружает конфигурацию, подключается к базе данных и проверяет схему так же, как при запуске сервера.
+func connect() error {
+	if _, err := loadConfig(); err != nil {
+		return err
+	}
+	database.ConnectDatabase()
+	database.EnsureSchema()
+	return nil
+}
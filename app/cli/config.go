@@ -0,0 +1,37 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+func init() {
+	register(Command{
+		Name:  "config",
+		Usage: "Print the effective configuration with secrets redacted",
+		Run:   runConfig,
+	})
+}
+
+func runConfig(args []string) error {
+	flags := flag.NewFlagSet("config", flag.ContinueOnError)
+	configFlag(flags)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: music-library config print [flags]")
+		flags.PrintDefaults()
+	}
+	if len(args) == 0 || args[0] != "print" {
+		flags.Usage()
+		return usageErrorf("config action must be print")
+	}
+	if err := parseFlags(flags, args[1:]); err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	return cfg.WriteYAML(os.Stdout)
+}
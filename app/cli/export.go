@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"music-library/app/database"
+	"music-library/app/exporter"
+	"music-library/app/services"
+)
+
+func init() {
+	register(Command{
+		Name:  "export",
+		Usage: "Export songs to CSV, JSON, NDJSON or XLSX",
+		Run:   runExport,
+	})
+}
+
+func runExport(args []string) error {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	format := flags.String("format", "", "file format: csv, json, ndjson or xlsx (detected by -o extension, csv by default)")
+	output := flags.String("o", "", "output file (stdout by default)")
+	var filter services.SongFilter
+	flags.StringVar(&filter.Group, "group", "", "export only songs of this group")
+	flags.StringVar(&filter.Name, "song", "", "export only songs with this name")
+	flags.IntVar(&filter.Limit, "limit", 0, "maximum number of songs (0 - no limit)")
+	flags.IntVar(&filter.Offset, "offset", 0, "number of songs to skip")
+	databaseFlags(flags)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: music-library export [flags]")
+		flags.PrintDefaults()
+	}
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	if *format == "" {
+		*format = strings.TrimPrefix(strings.ToLower(filepath.Ext(*output)), ".")
+		if !exporter.ValidFormat(*format) {
+			*format = exporter.FormatCSV
+		}
+	}
+	if !exporter.ValidFormat(*format) {
+		return usageErrorf("unsupported export format %q", *format)
+	}
+
+	if err := connect(); err != nil {
+		return err
+	}
+
+	if *output == "" {
+		writer, err := exporter.NewWriter(os.Stdout, *format)
+		if err != nil {
+			return err
+		}
+		_, err = services.ExportSongs(database.DB, filter, writer)
+		return err
+	}
+
+	// Файл пишется во временный и переименовывается только после успешной выгрузки,
+	// чтобы плановый дамп не оставлял после сбоя обрезанный файл.
+	temp, err := os.CreateTemp(filepath.Dir(*output), filepath.Base(*output)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+
+	writer, err := exporter.NewWriter(temp, *format)
+	if err != nil {
+		temp.Close()
+		return err
+	}
+	count, err := services.ExportSongs(database.DB, filter, writer)
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(temp.Name(), *output); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Exported %d songs to %s\n", count, *output)
+	return nil
+}
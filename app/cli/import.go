@@ -0,0 +1,89 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"music-library/app/database"
+	"music-library/app/models"
+	"music-library/app/services"
+)
+
+func init() {
+	register(Command{
+		Name:  "import",
+		Usage: "Import songs from a CSV, JSON or NDJSON file",
+		Run:   runImport,
+	})
+}
+
+func runImport(args []string) error {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	format := flags.String("format", "", "file format: csv, json or ndjson (detected by extension by default)")
+	mapping := flags.String("map", "", "column mapping, e.g. group=Artist,song=Title")
+	delimiter := flags.String("delimiter", "", "CSV column delimiter (default ',')")
+	dryRun := flags.Bool("dry-run", false, "show what would be created, updated or skipped without changing data")
+	enrich := flags.Bool("enrich", false, "fetch missing release date, text and link from the external API")
+	onDuplicate := flags.String("on-duplicate", services.OnDuplicateSkip, "what to do with songs already in the library or earlier in the file (same group and title, ignoring case): skip or update")
+	resume := flags.Uint("resume", 0, "resume the import job with this ID instead of starting a new one")
+	databaseFlags(flags)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: music-library import [flags] <file>")
+		flags.PrintDefaults()
+	}
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if *resume == 0 && flags.NArg() != 1 {
+		flags.Usage()
+		return usageErrorf("exactly one file must be specified")
+	}
+
+	if err := connect(); err != nil {
+		return err
+	}
+
+	var job *models.ImportJob
+	var err error
+	if *resume != 0 {
+		job, err = services.GetImportJob(database.DB, *resume)
+	} else {
+		var source []byte
+		source, err = os.ReadFile(flags.Arg(0))
+		if err != nil {
+			return err
+		}
+		job, err = services.CreateImportJob(database.DB, source, services.ImportOptions{
+			FileName:    filepath.Base(flags.Arg(0)),
+			Format:      *format,
+			Mapping:     *mapping,
+			Delimiter:   *delimiter,
+			DryRun:      *dryRun,
+			Enrich:      *enrich,
+			OnDuplicate: *onDuplicate,
+		})
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Import job %d: %d records\n", job.ID, job.TotalRows)
+	err = services.RunImportJob(database.DB, job, func(row models.ImportRowResult) {
+		if job.DryRun || row.Action == services.ImportActionFailed {
+			fmt.Printf("  record %d: %s %s - %s %s\n", row.Row, row.Action, row.Group, row.Name, row.Error)
+		}
+	})
+
+	if errors.Is(err, services.ErrImportRunning) || errors.Is(err, services.ErrImportCompleted) {
+		return err
+	}
+	fmt.Printf("Processed %d/%d: %d created, %d updated, %d skipped, %d failed\n",
+		job.ProcessedRows, job.TotalRows, job.CreatedRows, job.UpdatedRows, job.SkippedRows, job.FailedRows)
+	if err != nil {
+		return fmt.Errorf("import stopped, resume with --resume %d: %w", job.ID, err)
+	}
+	return nil
+}
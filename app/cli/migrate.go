@@ -0,0 +1,104 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+
+	"music-library/app/database"
+)
+
+func init() {
+	register(Command{
+		Name:  "migrate",
+		Usage: "Apply or revert database migrations: up, down [N], status, to N",
+		Run:   runMigrate,
+	})
+}
+
+func runMigrate(args []string) error {
+	flags := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	configFlag(flags)
+	envFlag(flags, "database-url", "DATABASE_URL", "PostgreSQL connection string")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: music-library migrate up|down [N]|status|to N")
+		fmt.Fprintln(flags.Output(), "  up        apply all pending migrations")
+		fmt.Fprintln(flags.Output(), "  down [N]  revert the last N applied migrations (1 by default)")
+		fmt.Fprintln(flags.Output(), "  status    list migrations and whether they are applied")
+		fmt.Fprintln(flags.Output(), "  to N      migrate up or down to version N (0 reverts everything)")
+	}
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return usageErrorf("migrate action must be specified")
+	}
+
+	action, rest := flags.Arg(0), flags.Args()[1:]
+	number := func(defaultValue int) (int, error) {
+		if len(rest) == 0 && defaultValue >= 0 {
+			return defaultValue, nil
+		}
+		if len(rest) != 1 {
+			return 0, usageErrorf("migrate %s expects one number", action)
+		}
+		value, err := strconv.Atoi(rest[0])
+		if err != nil || value < 0 {
+			return 0, usageErrorf("invalid migration number %q", rest[0])
+		}
+		return value, nil
+	}
+
+	// Схема здесь не проверяется: migrate как раз и приводит ее в актуальное состояние.
+	if _, err := loadConfig(); err != nil {
+		return err
+	}
+	database.ConnectDatabase()
+
+	var err error
+	switch action {
+	case "up":
+		err = database.MigrateUp(database.DB)
+	case "down":
+		var steps int
+		if steps, err = number(1); err == nil {
+			err = database.MigrateDown(database.DB, steps)
+		}
+	case "to":
+		var version int
+		if version, err = number(-1); err == nil {
+			err = database.MigrateTo(database.DB, version)
+		}
+	case "status":
+		return printMigrationStatus()
+	default:
+		flags.Usage()
+		return usageErrorf("unknown migrate action %q", action)
+	}
+	if err != nil {
+		return err
+	}
+
+	version, err := database.CurrentVersion(database.DB)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Database schema is at version %d (latest %d)\n", version, database.SchemaVersion())
+	return nil
+}
+
+func printMigrationStatus() error {
+	statuses, err := database.MigrationStatuses(database.DB)
+	if err != nil {
+		return err
+	}
+	for _, status := range statuses {
+		state := "pending"
+		if status.AppliedAt != nil {
+			state = "applied " + status.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		fmt.Printf("%04d  %-30s %s\n", status.Version, status.Name, state)
+	}
+	return nil
+}
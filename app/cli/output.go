@@ -0,0 +1,54 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"music-library/app/models"
+)
+
+// Форматы вывода результатов команд.
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+)
+
+// outputFlag регистрирует флаг --output с форматом вывода результата.
+func outputFlag(flags *flag.FlagSet) *string {
+	return flags.String("output", outputTable, "output format: table or json")
+}
+
+func checkOutput(output string) error {
+	if output != outputTable && output != outputJSON {
+		return usageErrorf("unsupported output format %q, use table or json", output)
+	}
+	return nil
+}
+
+// printJSON выводит значение в stdout в виде JSON с отступами.
+func printJSON(value interface{}) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(value)
+}
+
+// printSongs выводит песни таблицей или массивом JSON.
+func printSongs(output string, songs []models.Song) error {
+	if output == outputJSON {
+		if songs == nil {
+			songs = []models.Song{}
+		}
+		return printJSON(songs)
+	}
+
+	table := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "ID\tGROUP\tSONG\tRELEASE DATE\tVERSION")
+	for _, song := range songs {
+		fmt.Fprintf(table, "%d\t%s\t%s\t%s\t%d\n", song.ID, song.Group, song.Name, song.ReleaseDate, song.Version)
+	}
+	return table.Flush()
+}
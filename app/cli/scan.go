@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+
+	"music-library/app/database"
+	"music-library/app/models"
+	"music-library/app/services"
+)
+
+func init() {
+	register(Command{
+		Name:  "scan",
+		Usage: "Scan a directory of MP3, FLAC and Ogg files and add their songs to the library",
+		Run:   runScan,
+	})
+}
+
+func runScan(args []string) error {
+	flags := flag.NewFlagSet("scan", flag.ContinueOnError)
+	full := flags.Bool("full", false, "re-read all files, not only those changed since the last scan")
+	dryRun := flags.Bool("dry-run", false, "show what would be created or updated without changing data")
+	databaseFlags(flags)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: music-library scan [flags] <directory>")
+		flags.PrintDefaults()
+	}
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return usageErrorf("exactly one directory must be specified")
+	}
+
+	if err := connect(); err != nil {
+		return err
+	}
+
+	opts := services.ScanOptions{Full: *full, DryRun: *dryRun}
+	summary, err := services.ScanLibrary(database.DB, flags.Arg(0), opts, func(file models.ScanFileResult) {
+		if file.Action != services.ScanActionUnchanged {
+			fmt.Printf("  %s: %s %s\n", file.Action, file.Path, file.Error)
+		}
+	})
+
+	fmt.Printf("Scanned %d files: %d created, %d updated, %d moved, %d unchanged, %d skipped, %d failed\n",
+		summary.Scanned, summary.Created, summary.Updated, summary.Moved, summary.Unchanged, summary.Skipped, summary.Failed)
+	if err != nil {
+		return err
+	}
+	if summary.Failed > 0 {
+		return fmt.Errorf("%d files could not be scanned", summary.Failed)
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+
+	"music-library/app/database"
+	"music-library/app/mockapi"
+	"music-library/app/models"
+	"music-library/app/services"
+)
+
+func init() {
+	register(Command{
+		Name:  "seed",
+		Usage: "Fill the library with sample songs known to the mock API",
+		Run:   runSeed,
+	})
+}
+
+func runSeed(args []string) error {
+	flags := flag.NewFlagSet("seed", flag.ContinueOnError)
+	output := outputFlag(flags)
+	databaseFlags(flags)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: music-library seed [flags]")
+		flags.PrintDefaults()
+	}
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if err := checkOutput(*output); err != nil {
+		return err
+	}
+
+	if err := connect(); err != nil {
+		return err
+	}
+
+	// Уже существующие песни пропускаются, поэтому команду можно запускать повторно.
+	var created []models.Song
+	for _, song := range mockapi.Songs {
+		err := services.CreateSong(database.DB, &song)
+		if errors.Is(err, services.ErrDuplicateSong) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		created = append(created, song)
+	}
+	return printSongs(*output, created)
+}
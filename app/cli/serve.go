@@ -0,0 +1,203 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"music-library/app/auth"
+	"music-library/app/config"
+	"music-library/app/database"
+	"music-library/app/grpcapi"
+	"music-library/app/mockapi"
+	"music-library/app/ratelimit"
+	"music-library/app/routes"
+	"music-library/app/services"
+	"music-library/app/tracing"
+)
+
+func init() {
+	register(Command{
+		Name:  "serve",
+		Usage: "Start the HTTP and gRPC APIs (and the mock external API unless disabled)",
+		Run:   runServe,
+	})
+	register(Command{
+		Name:  "mock-api",
+		Usage: "Start only the mock external API",
+		Run:   runMockAPI,
+	})
+}
+
+func runServe(args []string) error {
+	flags := flag.NewFlagSet("serve", flag.ContinueOnError)
+	envFlag(flags, "addr", "SERVER_ADDR", "address of the HTTP API")
+	envFlag(flags, "mock-api-addr", "MOCK_API_ADDR", "address of the mock external API")
+	envBoolFlag(flags, "mock-api", "MOCK_API_ENABLED", "start the mock external API")
+	envFlag(flags, "grpc-addr", "GRPC_ADDR", "address of the gRPC API")
+	envBoolFlag(flags, "grpc", "GRPC_ENABLED", "start the gRPC API")
+	databaseFlags(flags)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: music-library serve [flags]")
+		flags.PrintDefaults()
+	}
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	slog.Info("Starting the music library application")
+	if err := connect(); err != nil {
+		return err
+	}
+
+	if err := auth.Setup(config.Get().Auth); err != nil {
+		return err
+	}
+
+	shutdownTracing, err := tracing.Setup(context.Background(), config.Get().Tracing)
+	if err != nil {
+		return err
+	}
+
+	services.StartWebhookDispatcher(database.DB)
+	services.StartEventFeed(database.DB)
+
+	// Лимиты частоты запросов общие для HTTP и gRPC API.
+	limits := ratelimit.NewMemoryStore()
+	settings := config.Get().HTTP
+	api := &http.Server{
+		Addr:              settings.Addr,
+		Handler:           routes.RegisterRoutes(limits),
+		ReadTimeout:       settings.ReadTimeout,
+		ReadHeaderTimeout: settings.ReadHeaderTimeout,
+		WriteTimeout:      settings.WriteTimeout,
+		IdleTimeout:       settings.IdleTimeout,
+	}
+	api.RegisterOnShutdown(services.CloseEventStreams)
+	servers := []server{api}
+	slog.Info("Server started", "addr", settings.Addr)
+	if grpcSettings := config.Get().GRPC; grpcSettings.Enabled {
+		servers = append(servers, grpcapi.NewServer(grpcSettings, services.Authenticator(database.DB), limits))
+		slog.Info("gRPC server started", "addr", grpcSettings.Addr, "reflection", grpcSettings.Reflection)
+	}
+	if config.Get().MockAPI.Enabled {
+		servers = append(servers, newMockAPIServer())
+	}
+
+	return runServers(servers, func(ctx context.Context) {
+		slog.Info("Shutdown: waiting for background workers")
+		if err := services.StopWorkers(ctx); err != nil {
+			slog.Warn("Shutdown: background workers interrupted", "error", err)
+		}
+
+		slog.Info("Shutdown: closing database connections")
+		if err := database.Close(); err != nil {
+			slog.Warn("Shutdown: failed to close database connections", "error", err)
+		}
+
+		slog.Info("Shutdown: flushing traces")
+		if err := shutdownTracing(ctx); err != nil {
+			slog.Warn("Shutdown: failed to flush traces", "error", err)
+		}
+	})
+}
+
+func runMockAPI(args []string) error {
+	flags := flag.NewFlagSet("mock-api", flag.ContinueOnError)
+	envFlag(flags, "addr", "MOCK_API_ADDR", "address of the mock external API")
+	configFlag(flags)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: music-library mock-api [flags]")
+		flags.PrintDefaults()
+	}
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	if _, err := loadConfig(); err != nil {
+		return err
+	}
+	return runServers([]server{newMockAPIServer()}, nil)
+}
+
+func newMockAPIServer() *http.Server {
+	settings := config.Get()
+	slog.Info("Mock API server started", "addr", settings.MockAPI.Addr)
+	return &http.Server{
+		Addr:              settings.MockAPI.Addr,
+		Handler:           mockapi.Handler(),
+		ReadHeaderTimeout: settings.HTTP.ReadHeaderTimeout,
+		IdleTimeout:       settings.HTTP.IdleTimeout,
+	}
+}
+
+// server - сервер, который запускает и останавливает runServers: *http.Server или *grpcapi.Server.
+type server interface {
+	ListenAndServe() error
+	Shutdown(ctx context.Context) error
+	Close() error
+}
+
+// serverAddr возвращает адрес сервера для сообщений журнала.
+func serverAddr(s server) string {
+	switch s := s.(type) {
+	case *http.Server:
+		return s.Addr
+	case *grpcapi.Server:
+		return s.Addr
+	default:
+		return ""
+	}
+}
+
+// runServers запускает серверы и ждет SIGINT или SIGTERM либо падения одного из серверов.
+// Затем серверы перестают принимать соединения и дожидаются текущих запросов, после чего
+// вызывается onShutdown. Все этапы остановки укладываются в http.shutdownTimeout.
+func runServers(servers []server, onShutdown func(ctx context.Context)) error {
+	signals, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	errs := make(chan error, len(servers))
+	for _, server := range servers {
+		go func() {
+			if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+				errs <- fmt.Errorf("server at %s: %w", serverAddr(server), err)
+			}
+		}()
+	}
+
+	var serveErr error
+	select {
+	case <-signals.Done():
+		slog.Info("Shutdown: received stop signal")
+	case serveErr = <-errs:
+		slog.Error("Shutdown: server failed", "error", serveErr)
+	}
+	stop()
+
+	timeout := config.Get().HTTP.ShutdownTimeout
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	started := time.Now()
+
+	slog.Info("Shutdown: draining in-flight requests", "deadline", timeout.String())
+	for _, server := range servers {
+		if err := server.Shutdown(ctx); err != nil {
+			slog.Warn("Shutdown: server did not drain in time", "addr", serverAddr(server), "error", err)
+			server.Close()
+		}
+	}
+
+	if onShutdown != nil {
+		onShutdown(ctx)
+	}
+	slog.Info("Shutdown: completed", "duration", time.Since(started).Round(time.Millisecond).String())
+	return serveErr
+}
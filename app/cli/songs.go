@@ -0,0 +1,163 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+
+	"music-library/app/database"
+	"music-library/app/models"
+	"music-library/app/services"
+)
+
+func init() {
+	register(Command{
+		Name:  "songs",
+		Usage: "Manage songs: list, add, delete",
+		Run:   runSongs,
+	})
+}
+
+var songsCommands = []Command{
+	{Name: "list", Usage: "List songs with optional filters", Run: runSongsList},
+	{Name: "add", Usage: "Add a song", Run: runSongsAdd},
+	{Name: "delete", Usage: "Delete a song by ID", Run: runSongsDelete},
+}
+
+func runSongs(args []string) error {
+	if len(args) > 0 {
+		for _, command := range songsCommands {
+			if command.Name == args[0] {
+				return command.Run(args[1:])
+			}
+		}
+	}
+
+	fmt.Fprintln(os.Stderr, "Usage: music-library songs <command> [flags]\n\nCommands:")
+	for _, command := range songsCommands {
+		fmt.Fprintf(os.Stderr, "  %-8s %s\n", command.Name, command.Usage)
+	}
+	if len(args) == 0 {
+		return usageErrorf("songs command must be specified")
+	}
+	return usageErrorf("unknown songs command %q", args[0])
+}
+
+func runSongsList(args []string) error {
+	flags := flag.NewFlagSet("songs list", flag.ContinueOnError)
+	output := outputFlag(flags)
+	filter := services.SongFilter{}
+	flags.StringVar(&filter.Group, "group", "", "only songs of this group")
+	flags.StringVar(&filter.Name, "song", "", "only songs with this name")
+	flags.IntVar(&filter.Limit, "limit", 10, "maximum number of songs (0 - no limit)")
+	flags.IntVar(&filter.Offset, "offset", 0, "number of songs to skip")
+	databaseFlags(flags)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: music-library songs list [flags]")
+		flags.PrintDefaults()
+	}
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if err := checkOutput(*output); err != nil {
+		return err
+	}
+
+	if err := connect(); err != nil {
+		return err
+	}
+
+	var songs []models.Song
+	if err := services.ApplySongFilter(database.DB, filter).Order("id").Find(&songs).Error; err != nil {
+		return err
+	}
+	return printSongs(*output, songs)
+}
+
+func runSongsAdd(args []string) error {
+	flags := flag.NewFlagSet("songs add", flag.ContinueOnError)
+	output := outputFlag(flags)
+	var song models.Song
+	flags.StringVar(&song.Group, "group", "", "group or artist (required)")
+	flags.StringVar(&song.Name, "song", "", "song name (required)")
+	flags.StringVar(&song.ReleaseDate, "release-date", "", "release date")
+	flags.StringVar(&song.Text, "text", "", "song text")
+	flags.StringVar(&song.Link, "link", "", "link to the song")
+	enrich := flags.Bool("enrich", false, "fetch release date, text and link from the external API")
+	databaseFlags(flags)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: music-library songs add -group <group> -song <name> [flags]")
+		flags.PrintDefaults()
+	}
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if err := checkOutput(*output); err != nil {
+		return err
+	}
+	if err := services.ValidateSong(song); err != nil {
+		return usageErrorf("%s", err)
+	}
+
+	if err := connect(); err != nil {
+		return err
+	}
+
+	if *enrich {
+		if err := services.EnrichSong(context.Background(), &song); err != nil {
+			return err
+		}
+	}
+	if err := services.CreateSong(database.DB, &song); err != nil {
+		return err
+	}
+	return printSongs(*output, []models.Song{song})
+}
+
+func runSongsDelete(args []string) error {
+	flags := flag.NewFlagSet("songs delete", flag.ContinueOnError)
+	output := outputFlag(flags)
+	ifMatch := flags.String("if-match", "", "delete only if the song ETag matches")
+	databaseFlags(flags)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: music-library songs delete [flags] <id>")
+		flags.PrintDefaults()
+	}
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if err := checkOutput(*output); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return usageErrorf("exactly one song ID must be specified")
+	}
+	id, err := strconv.ParseUint(flags.Arg(0), 10, 64)
+	if err != nil {
+		return usageErrorf("invalid song ID %q", flags.Arg(0))
+	}
+
+	if err := connect(); err != nil {
+		return err
+	}
+
+	song, err := services.GetSong(database.DB, id)
+	if err != nil {
+		return err
+	}
+	if *ifMatch != "" && !services.MatchETag(*ifMatch, song.ETag) {
+		return fmt.Errorf("%w: current ETag is %s", services.ErrVersionConflict, song.ETag)
+	}
+	if err := services.DeleteSong(database.DB, song); err != nil {
+		return err
+	}
+
+	if *output == outputJSON {
+		return printJSON(song)
+	}
+	fmt.Printf("Deleted song %d: %s - %s\n", song.ID, song.Group, song.Name)
+	return nil
+}
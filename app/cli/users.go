@@ -0,0 +1,176 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"music-library/app/auth"
+	"music-library/app/database"
+	"music-library/app/models"
+	"music-library/app/services"
+)
+
+func init() {
+	register(Command{
+		Name:  "users",
+		Usage: "Manage users: create, list, set-role",
+		Run:   runUsers,
+	})
+}
+
+var usersCommands = []Command{
+	{Name: "create", Usage: "Create a user, e.g. the first admin", Run: runUsersCreate},
+	{Name: "list", Usage: "List users", Run: runUsersList},
+	{Name: "set-role", Usage: "Change the role of a user by ID", Run: runUsersSetRole},
+}
+
+func runUsers(args []string) error {
+	if len(args) > 0 {
+		for _, command := range usersCommands {
+			if command.Name == args[0] {
+				return command.Run(args[1:])
+			}
+		}
+	}
+
+	fmt.Fprintln(os.Stderr, "Usage: music-library users <command> [flags]\n\nCommands:")
+	for _, command := range usersCommands {
+		fmt.Fprintf(os.Stderr, "  %-8s %s\n", command.Name, command.Usage)
+	}
+	if len(args) == 0 {
+		return usageErrorf("users command must be specified")
+	}
+	return usageErrorf("unknown users command %q", args[0])
+}
+
+func runUsersCreate(args []string) error {
+	flags := flag.NewFlagSet("users create", flag.ContinueOnError)
+	output := outputFlag(flags)
+	username := flags.String("username", "", "login name (required)")
+	role := flags.String("role", auth.RoleViewer, "role: "+strings.Join(auth.Roles, ", "))
+	passwordStdin := flags.Bool("password-stdin", false, "read the password from the first line of stdin")
+	password := flags.String("password", "", "password (prefer -password-stdin to keep it out of the shell history)")
+	databaseFlags(flags)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: music-library users create -username <name> -password-stdin [flags]")
+		flags.PrintDefaults()
+	}
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if err := checkOutput(*output); err != nil {
+		return err
+	}
+	if *passwordStdin {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return usageErrorf("failed to read password from stdin: %s", err)
+		}
+		*password = strings.TrimRight(line, "\r\n")
+	}
+
+	if err := connect(); err != nil {
+		return err
+	}
+
+	user, err := services.CreateUser(database.DB, *username, *password, *role)
+	if errors.Is(err, services.ErrInvalidUser) || errors.Is(err, auth.ErrInvalidRole) {
+		return usageErrorf("%s", err)
+	}
+	if err != nil {
+		return err
+	}
+	if *output == outputJSON {
+		return printJSON(user)
+	}
+	return printUsers([]models.User{user})
+}
+
+func runUsersList(args []string) error {
+	flags := flag.NewFlagSet("users list", flag.ContinueOnError)
+	output := outputFlag(flags)
+	databaseFlags(flags)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: music-library users list [flags]")
+		flags.PrintDefaults()
+	}
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if err := checkOutput(*output); err != nil {
+		return err
+	}
+
+	if err := connect(); err != nil {
+		return err
+	}
+
+	users, err := services.ListUsers(database.DB)
+	if err != nil {
+		return err
+	}
+	if *output == outputJSON {
+		if users == nil {
+			users = []models.User{}
+		}
+		return printJSON(users)
+	}
+	return printUsers(users)
+}
+
+func runUsersSetRole(args []string) error {
+	flags := flag.NewFlagSet("users set-role", flag.ContinueOnError)
+	output := outputFlag(flags)
+	databaseFlags(flags)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: music-library users set-role [flags] <id> <%s>\n", strings.Join(auth.Roles, "|"))
+		flags.PrintDefaults()
+	}
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if err := checkOutput(*output); err != nil {
+		return err
+	}
+	if flags.NArg() != 2 {
+		flags.Usage()
+		return usageErrorf("user ID and role must be specified")
+	}
+	id, err := strconv.ParseUint(flags.Arg(0), 10, 64)
+	if err != nil {
+		return usageErrorf("invalid user ID %q", flags.Arg(0))
+	}
+	if !auth.ValidRole(flags.Arg(1)) {
+		return usageErrorf("%s", auth.ErrInvalidRole)
+	}
+
+	if err := connect(); err != nil {
+		return err
+	}
+
+	user, err := services.SetUserRole(database.DB, id, flags.Arg(1))
+	if err != nil {
+		return err
+	}
+	if *output == outputJSON {
+		return printJSON(user)
+	}
+	fmt.Printf("User %d (%s) is now %s\n", user.ID, user.Username, user.Role)
+	return nil
+}
+
+// printUsers выводит пользователей таблицей.
+func printUsers(users []models.User) error {
+	table := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "ID\tUSERNAME\tROLE\tCREATED")
+	for _, user := range users {
+		fmt.Fprintf(table, "%d\t%s\t%s\t%s\n", user.ID, user.Username, user.Role, formatTime(&user.CreatedAt))
+	}
+	return table.Flush()
+}
@@ -1,25 +1,443 @@
 package config
 
 import (
-	"github.com/joho/godotenv"
-	"log"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net"
+	"net/url"
 	"os"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
 )
 
-func LoadConfig() {
-	log.Println("DEBUG: Attempting to load .env file")
-	if err := godotenv.Load(); err != nil {
-		log.Fatal("ERROR: Unable to load .env file:", err)
+// ErrInvalidConfig возвращается, если конфигурация не прошла разбор или проверку.
+var ErrInvalidConfig = errors.New("invalid configuration")
+
+// Config содержит все настройки приложения.
+// Тег key задает имя параметра в файле конфигурации, env - переменную окружения,
+// secret - способ скрытия значения в выводе config print (true - целиком, url - только пароль).
+type Config struct {
+	HTTP      HTTPConfig      `key:"http"`
+	Database  DatabaseConfig  `key:"database"`
+	Provider  ProviderConfig  `key:"provider"`
+	MockAPI   MockAPIConfig   `key:"mockApi"`
+	Limits    LimitsConfig    `key:"limits"`
+	Features  FeaturesConfig  `key:"features"`
+	Health    HealthConfig    `key:"health"`
+	Log       LogConfig       `key:"log"`
+	Tracing   TracingConfig   `key:"tracing"`
+	Auth      AuthConfig      `key:"auth"`
+	RateLimit RateLimitConfig `key:"rateLimit"`
+	Webhooks  WebhooksConfig  `key:"webhooks"`
+	Events    EventsConfig    `key:"events"`
+	GraphQL   GraphQLConfig   `key:"graphql"`
+	GRPC      GRPCConfig      `key:"grpc"`
+}
+
+// HTTPConfig - настройки HTTP API.
+type HTTPConfig struct {
+	Addr              string        `key:"addr" env:"SERVER_ADDR"`                           // Адрес, на котором слушает HTTP API
+	ReadTimeout       time.Duration `key:"readTimeout" env:"HTTP_READ_TIMEOUT"`              // Максимальное время чтения запроса
+	ReadHeaderTimeout time.Duration `key:"readHeaderTimeout" env:"HTTP_READ_HEADER_TIMEOUT"` // Максимальное время чтения заголовков
+	WriteTimeout      time.Duration `key:"writeTimeout" env:"HTTP_WRITE_TIMEOUT"`            // Максимальное время записи ответа
+	IdleTimeout       time.Duration `key:"idleTimeout" env:"HTTP_IDLE_TIMEOUT"`              // Время жизни простаивающего keep-alive соединения
+	ShutdownTimeout   time.Duration `key:"shutdownTimeout" env:"HTTP_SHUTDOWN_TIMEOUT"`      // Время на завершение запросов при остановке
+}
+
+// DatabaseConfig - настройки подключения к PostgreSQL.
+type DatabaseConfig struct {
+	URL                  string        `key:"url" env:"DATABASE_URL" secret:"url"`               // Строка подключения
+	MaxOpenConns         int           `key:"maxOpenConns" env:"DB_MAX_OPEN_CONNS"`              // Максимум открытых соединений (0 - без ограничения)
+	MaxIdleConns         int           `key:"maxIdleConns" env:"DB_MAX_IDLE_CONNS"`              // Максимум простаивающих соединений
+	ConnMaxLifetime      time.Duration `key:"connMaxLifetime" env:"DB_CONN_MAX_LIFETIME"`        // Время жизни соединения (0 - без ограничения)
+	ConnMaxIdleTime      time.Duration `key:"connMaxIdleTime" env:"DB_CONN_MAX_IDLE_TIME"`       // Время простоя соединения до закрытия
+	MigrateOnStart       bool          `key:"migrateOnStart" env:"MIGRATE_ON_START"`             // Применять недостающие миграции при запуске
+	RequireCurrentSchema bool          `key:"requireCurrentSchema" env:"REQUIRE_CURRENT_SCHEMA"` // Не запускаться, если схема отстает от миграций
+	SlowQueryThreshold   time.Duration `key:"slowQueryThreshold" env:"DB_SLOW_QUERY_THRESHOLD"`  // Запросы дольше пишутся в журнал как медленные (0 - не отмечать)
+}
+
+// ProviderConfig - настройки внешнего API с данными песен.
+type ProviderConfig struct {
+	Name    string        `key:"name" env:"PROVIDER_NAME"`       // Имя API в метриках и трассировке
+	URL     string        `key:"url" env:"PROVIDER_URL"`         // Базовый адрес API, запрос идет на <url>/info
+	Timeout time.Duration `key:"timeout" env:"PROVIDER_TIMEOUT"` // Таймаут одного запроса
+}
+
+// MockAPIConfig - настройки тестового внешнего API.
+type MockAPIConfig struct {
+	Enabled bool   `key:"enabled" env:"MOCK_API_ENABLED"` // Запускать вместе с сервером
+	Addr    string `key:"addr" env:"MOCK_API_ADDR"`       // Адрес, на котором он слушает
+}
+
+// LimitsConfig - ограничения на размер запросов и выборок.
+type LimitsConfig struct {
+	DefaultPageSize        int   `key:"defaultPageSize" env:"DEFAULT_PAGE_SIZE"`               // Размер страницы GET /songs без limit
+	MaxPageSize            int   `key:"maxPageSize" env:"MAX_PAGE_SIZE"`                       // Максимальный limit GET /songs (0 - без ограничения)
+	TextPageSize           int   `key:"textPageSize" env:"TEXT_PAGE_SIZE"`                     // Куплетов на странице текста песни
+	BatchMaxItems          int   `key:"batchMaxItems" env:"BATCH_MAX_ITEMS"`                   // Элементов в одном пакетном запросе
+	BatchEnrichConcurrency int   `key:"batchEnrichConcurrency" env:"BATCH_ENRICH_CONCURRENCY"` // Параллельных запросов к внешнему API при пакетной загрузке
+	ImportMaxBytes         int64 `key:"importMaxBytes" env:"IMPORT_MAX_BYTES"`                 // Размер файла POST /imports
+	RestoreMaxBytes        int64 `key:"restoreMaxBytes" env:"RESTORE_MAX_BYTES"`               // Размер архива POST /admin/restore
+}
+
+// FeaturesConfig - переключатели поведения API.
+type FeaturesConfig struct {
+	RequireIfMatch bool `key:"requireIfMatch" env:"REQUIRE_IF_MATCH"` // Обязателен ли If-Match для изменяющих запросов
+}
+
+// HealthConfig - настройки проверок /readyz и /status.
+type HealthConfig struct {
+	CheckTimeout      time.Duration `key:"checkTimeout" env:"HEALTH_CHECK_TIMEOUT"`            // Таймаут одной проверки
+	NonCriticalChecks string        `key:"nonCriticalChecks" env:"HEALTH_NON_CRITICAL_CHECKS"` // Проверки через запятую, отказ которых не влияет на /readyz
+}
+
+// LogConfig - настройки журнала.
+type LogConfig struct {
+	Level  string `key:"level" env:"LOG_LEVEL"`   // debug, info, warn или error
+	Format string `key:"format" env:"LOG_FORMAT"` // json или text
+}
+
+// TracingConfig - настройки трассировки OpenTelemetry.
+type TracingConfig struct {
+	Exporter    string  `key:"exporter" env:"TRACING_EXPORTER"`        // none, otlp или stdout
+	Endpoint    string  `key:"endpoint" env:"TRACING_OTLP_ENDPOINT"`   // Адрес приема трасс OTLP/HTTP
+	ServiceName string  `key:"serviceName" env:"OTEL_SERVICE_NAME"`    // Имя сервиса в трассах
+	SampleRatio float64 `key:"sampleRatio" env:"TRACING_SAMPLE_RATIO"` // Доля трассируемых запросов без родительской трассы
+}
+
+// AuthConfig - настройки доступа к API.
+// Ключи подписи задаются списком "id:secret,id:secret": первым ключом подписываются новые токены,
+// остальные только проверяются. Для смены ключа новый ставится первым, а старый удаляется
+// после истечения AccessTokenTTL, поэтому пользователям не приходится входить заново.
+type AuthConfig struct {
+	Enabled         bool          `key:"enabled" env:"AUTH_ENABLED"`                   // Требовать ключ доступа или токен
+	AnonymousRead   bool          `key:"anonymousRead" env:"AUTH_ANONYMOUS_READ"`      // Разрешить чтение без учетных данных
+	Registration    bool          `key:"registration" env:"AUTH_REGISTRATION"`         // Разрешить регистрацию пользователей через API
+	JWTKeys         string        `key:"jwtKeys" env:"AUTH_JWT_KEYS" secret:"true"`    // Ключи подписи токенов доступа
+	AccessTokenTTL  time.Duration `key:"accessTokenTtl" env:"AUTH_ACCESS_TOKEN_TTL"`   // Время жизни токена доступа
+	RefreshTokenTTL time.Duration `key:"refreshTokenTtl" env:"AUTH_REFRESH_TOKEN_TTL"` // Время жизни токена обновления
+}
+
+// RateLimitConfig - ограничение частоты запросов клиента (ключа доступа, пользователя или IP-адреса).
+// Лимиты задаются числом запросов за Window отдельно для чтения, изменений и запросов,
+// обращающихся к внешнему API. Для ключа доступа лимиты можно переопределить при его создании.
+type RateLimitConfig struct {
+	Enabled    bool          `key:"enabled" env:"RATE_LIMIT_ENABLED"`        // Ограничивать частоту запросов
+	Window     time.Duration `key:"window" env:"RATE_LIMIT_WINDOW"`          // Период, к которому относятся лимиты
+	Read       int           `key:"read" env:"RATE_LIMIT_READ"`              // Запросов на чтение за период
+	Write      int           `key:"write" env:"RATE_LIMIT_WRITE"`            // Запросов на изменение за период
+	Enrich     int           `key:"enrich" env:"RATE_LIMIT_ENRICH"`          // Запросов с обращением к внешнему API за период
+	TrustProxy bool          `key:"trustProxy" env:"RATE_LIMIT_TRUST_PROXY"` // Брать адрес клиента из X-Forwarded-For
+}
+
+// WebhooksConfig - доставка событий песен подписчикам. События сохраняются в исходящую очередь
+// вместе с изменением песни, а фоновый обработчик доставляет их и повторяет неудачные попытки
+// с экспоненциально растущей паузой.
+type WebhooksConfig struct {
+	Enabled      bool          `key:"enabled" env:"WEBHOOKS_ENABLED"`            // Запускать доставку событий
+	PollInterval time.Duration `key:"pollInterval" env:"WEBHOOKS_POLL_INTERVAL"` // Как часто проверять очередь доставок
+	Timeout      time.Duration `key:"timeout" env:"WEBHOOKS_TIMEOUT"`            // Таймаут одного запроса к подписчику
+	Concurrency  int           `key:"concurrency" env:"WEBHOOKS_CONCURRENCY"`    // Одновременных запросов к подписчикам
+	MaxAttempts  int           `key:"maxAttempts" env:"WEBHOOKS_MAX_ATTEMPTS"`   // Попыток доставки до отказа
+	RetryBackoff time.Duration `key:"retryBackoff" env:"WEBHOOKS_RETRY_BACKOFF"` // Пауза перед первым повтором, дальше удваивается
+	MaxBackoff   time.Duration `key:"maxBackoff" env:"WEBHOOKS_MAX_BACKOFF"`     // Наибольшая пауза между попытками
+}
+
+// EventsConfig - поток событий песен GET /events. Новые события читаются из журнала событий
+// с интервалом PollInterval и раздаются подключенным клиентам. Клиент, не успевающий принимать
+// события, отключается и продолжает с заголовком Last-Event-ID по журналу.
+type EventsConfig struct {
+	PollInterval time.Duration `key:"pollInterval" env:"EVENTS_POLL_INTERVAL"` // Как часто проверять журнал событий
+	Heartbeat    time.Duration `key:"heartbeat" env:"EVENTS_HEARTBEAT"`        // Интервал комментариев, поддерживающих соединение
+	BufferSize   int           `key:"bufferSize" env:"EVENTS_BUFFER_SIZE"`     // Событий в очереди клиента до его отключения
+	WriteTimeout time.Duration `key:"writeTimeout" env:"EVENTS_WRITE_TIMEOUT"` // Максимальное время записи одного события клиенту
+}
+
+// GraphQLConfig - ограничения запросов к /graphql. Запрос, превышающий глубину или сложность,
+// отклоняется до выполнения. Сложность - число запрашиваемых полей, где поля списков песен
+// умножаются на размер страницы.
+type GraphQLConfig struct {
+	MaxDepth      int  `key:"maxDepth" env:"GRAPHQL_MAX_DEPTH"`           // Наибольшая вложенность полей
+	MaxComplexity int  `key:"maxComplexity" env:"GRAPHQL_MAX_COMPLEXITY"` // Наибольшая сложность запроса
+	Introspection bool `key:"introspection" env:"GRAPHQL_INTROSPECTION"`  // Разрешить запросы схемы (__schema, __type)
+}
+
+// GRPCConfig - gRPC API, который работает в том же процессе, что и HTTP API, и использует
+// те же ключи доступа и токены.
+type GRPCConfig struct {
+	Enabled    bool   `key:"enabled" env:"GRPC_ENABLED"`       // Запускать вместе с HTTP API
+	Addr       string `key:"addr" env:"GRPC_ADDR"`             // Адрес, на котором слушает gRPC API
+	Reflection bool   `key:"reflection" env:"GRPC_REFLECTION"` // Включить сервис reflection для grpcurl и подобных клиентов
+}
+
+// SigningKey - ключ подписи токенов доступа.
+type SigningKey struct {
+	ID     string // Идентификатор ключа (kid в заголовке токена)
+	Secret string // Секрет HMAC
+}
+
+// minSigningKeyLength - минимальная длина секрета HS256.
+const minSigningKeyLength = 32
+
+// SigningKeys разбирает ключи подписи токенов. Первым идет ключ, которым подписываются новые токены.
+func (a AuthConfig) SigningKeys() ([]SigningKey, error) {
+	var keys []SigningKey
+	seen := make(map[string]bool)
+	for _, item := range strings.Split(a.JWTKeys, ",") {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+		id, secret, ok := strings.Cut(item, ":")
+		if !ok || id == "" {
+			return nil, fmt.Errorf("key must have the form id:secret")
+		}
+		if len(secret) < minSigningKeyLength {
+			return nil, fmt.Errorf("secret of key %q must be at least %d characters", id, minSigningKeyLength)
+		}
+		if seen[id] {
+			return nil, fmt.Errorf("duplicate key id %q", id)
+		}
+		seen[id] = true
+		keys = append(keys, SigningKey{ID: id, Secret: secret})
+	}
+	return keys, nil
+}
+
+// NonCritical сообщает, отмечена ли проверка как некритичная.
+func (h HealthConfig) NonCritical(name string) bool {
+	for _, check := range strings.Split(h.NonCriticalChecks, ",") {
+		if strings.TrimSpace(check) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Default возвращает конфигурацию по умолчанию.
+func Default() *Config {
+	return &Config{
+		HTTP: HTTPConfig{
+			Addr:              ":8000",
+			ReadTimeout:       30 * time.Second,
+			ReadHeaderTimeout: 10 * time.Second,
+			WriteTimeout:      5 * time.Minute,
+			IdleTimeout:       2 * time.Minute,
+			ShutdownTimeout:   30 * time.Second,
+		},
+		Database: DatabaseConfig{
+			MaxOpenConns:         20,
+			MaxIdleConns:         10,
+			ConnMaxLifetime:      30 * time.Minute,
+			ConnMaxIdleTime:      5 * time.Minute,
+			RequireCurrentSchema: true,
+			SlowQueryThreshold:   200 * time.Millisecond,
+		},
+		Provider: ProviderConfig{
+			Name:    "song-info",
+			URL:     "http://localhost:8081",
+			Timeout: 10 * time.Second,
+		},
+		MockAPI: MockAPIConfig{
+			Enabled: true,
+			Addr:    ":8081",
+		},
+		Limits: LimitsConfig{
+			DefaultPageSize:        10,
+			TextPageSize:           1000,
+			BatchMaxItems:          1000,
+			BatchEnrichConcurrency: 4,
+			ImportMaxBytes:         32 << 20,
+			RestoreMaxBytes:        1 << 30,
+		},
+		Features: FeaturesConfig{
+			RequireIfMatch: true,
+		},
+		Health: HealthConfig{
+			CheckTimeout:      2 * time.Second,
+			NonCriticalChecks: "provider",
+		},
+		Log: LogConfig{
+			Level:  "info",
+			Format: "json",
+		},
+		Tracing: TracingConfig{
+			Exporter:    "none",
+			Endpoint:    "http://localhost:4318/v1/traces",
+			ServiceName: "music-library",
+			SampleRatio: 1,
+		},
+		Auth: AuthConfig{
+			Enabled:         true,
+			Registration:    true,
+			AccessTokenTTL:  15 * time.Minute,
+			RefreshTokenTTL: 30 * 24 * time.Hour,
+		},
+		RateLimit: RateLimitConfig{
+			Enabled: true,
+			Window:  time.Minute,
+			Read:    600,
+			Write:   120,
+			Enrich:  30,
+		},
+		Webhooks: WebhooksConfig{
+			Enabled:      true,
+			PollInterval: time.Second,
+			Timeout:      10 * time.Second,
+			Concurrency:  4,
+			MaxAttempts:  8,
+			RetryBackoff: 30 * time.Second,
+			MaxBackoff:   time.Hour,
+		},
+		Events: EventsConfig{
+			PollInterval: time.Second,
+			Heartbeat:    15 * time.Second,
+			BufferSize:   256,
+			WriteTimeout: 10 * time.Second,
+		},
+		GraphQL: GraphQLConfig{
+			MaxDepth:      10,
+			MaxComplexity: 2000,
+			Introspection: true,
+		},
+		GRPC: GRPCConfig{
+			Enabled:    true,
+			Addr:       ":9090",
+			Reflection: true,
+		},
 	}
-	log.Println("INFO: Successfully loaded .env file")
 }
 
-func GetDatabaseURL() string {
-	dbURL := os.Getenv("DATABASE_URL")
-	if dbURL == "" {
-		log.Println("WARNING: DATABASE_URL is not set")
-	} else {
-		log.Println("DEBUG: Retrieved DATABASE_URL")
+var current = Default()
+
+// Get возвращает текущую конфигурацию. До вызова Load это конфигурация по умолчанию.
+func Get() *Config {
+	return current
+}
+
+// Load собирает конфигурацию по возрастанию приоритета: значения по умолчанию, файл из CONFIG_FILE
+// (YAML или TOML), файл .env, переменные окружения. Флаги командной строки выставляют переменные
+// окружения до вызова Load и поэтому имеют наивысший приоритет.
+// Возвращает все ошибки разбора и проверки сразу.
+func Load() (*Config, error) {
+	slog.Debug("Loading configuration")
+	// Файл .env необязателен: в контейнерах переменные окружения задает оркестратор.
+	// Уже заданные переменные окружения он не переопределяет.
+	if err := godotenv.Load(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("failed to load .env file: %w", err)
+	}
+
+	cfg := Default()
+	var errs []error
+	if path := os.Getenv("CONFIG_FILE"); path != "" {
+		errs = append(errs, cfg.loadFile(path)...)
+	}
+	errs = append(errs, cfg.loadEnv()...)
+	errs = append(errs, cfg.Validate()...)
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("%w:\n%w", ErrInvalidConfig, errors.Join(errs...))
+	}
+
+	current = cfg
+	slog.Info("Configuration loaded")
+	return cfg, nil
+}
+
+// Validate проверяет согласованность настроек и возвращает все найденные ошибки.
+func (c *Config) Validate() []error {
+	var errs []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	_, _, err := net.SplitHostPort(c.HTTP.Addr)
+	check(err == nil, "http.addr: invalid address %q", c.HTTP.Addr)
+	_, _, err = net.SplitHostPort(c.MockAPI.Addr)
+	check(err == nil, "mockApi.addr: invalid address %q", c.MockAPI.Addr)
+	_, _, err = net.SplitHostPort(c.GRPC.Addr)
+	check(err == nil, "grpc.addr: invalid address %q", c.GRPC.Addr)
+	check(c.HTTP.ReadTimeout > 0, "http.readTimeout must be positive")
+	check(c.HTTP.ReadHeaderTimeout > 0, "http.readHeaderTimeout must be positive")
+	check(c.HTTP.WriteTimeout > 0, "http.writeTimeout must be positive")
+	check(c.HTTP.IdleTimeout > 0, "http.idleTimeout must be positive")
+	check(c.HTTP.ShutdownTimeout > 0, "http.shutdownTimeout must be positive")
+
+	check(c.Database.MaxOpenConns >= 0, "database.maxOpenConns must not be negative")
+	check(c.Database.MaxIdleConns >= 0, "database.maxIdleConns must not be negative")
+	check(c.Database.MaxOpenConns == 0 || c.Database.MaxIdleConns <= c.Database.MaxOpenConns,
+		"database.maxIdleConns must not exceed database.maxOpenConns")
+	check(c.Database.ConnMaxLifetime >= 0, "database.connMaxLifetime must not be negative")
+	check(c.Database.ConnMaxIdleTime >= 0, "database.connMaxIdleTime must not be negative")
+	check(c.Database.SlowQueryThreshold >= 0, "database.slowQueryThreshold must not be negative")
+
+	provider, err := url.Parse(c.Provider.URL)
+	check(err == nil && (provider.Scheme == "http" || provider.Scheme == "https") && provider.Host != "",
+		"provider.url: must be an absolute http(s) URL, got %q", c.Provider.URL)
+	check(c.Provider.Timeout > 0, "provider.timeout must be positive")
+
+	check(c.Limits.DefaultPageSize >= 0, "limits.defaultPageSize must not be negative")
+	check(c.Limits.MaxPageSize >= 0, "limits.maxPageSize must not be negative")
+	check(c.Limits.MaxPageSize == 0 || c.Limits.DefaultPageSize <= c.Limits.MaxPageSize,
+		"limits.defaultPageSize must not exceed limits.maxPageSize")
+	check(c.Limits.TextPageSize > 0, "limits.textPageSize must be positive")
+	check(c.Limits.BatchMaxItems > 0, "limits.batchMaxItems must be positive")
+	check(c.Limits.BatchEnrichConcurrency > 0, "limits.batchEnrichConcurrency must be positive")
+	check(c.Limits.ImportMaxBytes > 0, "limits.importMaxBytes must be positive")
+	check(c.Limits.RestoreMaxBytes > 0, "limits.restoreMaxBytes must be positive")
+
+	check(c.Health.CheckTimeout > 0, "health.checkTimeout must be positive")
+
+	switch strings.ToLower(c.Log.Level) {
+	case "debug", "info", "warn", "error":
+	default:
+		check(false, "log.level: must be debug, info, warn or error, got %q", c.Log.Level)
+	}
+	switch c.Tracing.Exporter {
+	case "none", "stdout":
+	case "otlp":
+		endpoint, err := url.Parse(c.Tracing.Endpoint)
+		check(err == nil && (endpoint.Scheme == "http" || endpoint.Scheme == "https") && endpoint.Host != "",
+			"tracing.endpoint: must be an absolute http(s) URL, got %q", c.Tracing.Endpoint)
+	default:
+		check(false, "tracing.exporter: must be none, otlp or stdout, got %q", c.Tracing.Exporter)
+	}
+	check(c.Tracing.ServiceName != "", "tracing.serviceName must not be empty")
+	check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "tracing.sampleRatio must be between 0 and 1")
+
+	_, err = c.Auth.SigningKeys()
+	check(err == nil, "auth.jwtKeys: %v", err)
+	check(c.Auth.AccessTokenTTL > 0, "auth.accessTokenTtl must be positive")
+	check(c.Auth.RefreshTokenTTL > c.Auth.AccessTokenTTL, "auth.refreshTokenTtl must exceed auth.accessTokenTtl")
+
+	check(c.RateLimit.Window > 0, "rateLimit.window must be positive")
+	check(c.RateLimit.Read > 0, "rateLimit.read must be positive")
+	check(c.RateLimit.Write > 0, "rateLimit.write must be positive")
+	check(c.RateLimit.Enrich > 0, "rateLimit.enrich must be positive")
+
+	check(c.Webhooks.PollInterval > 0, "webhooks.pollInterval must be positive")
+	check(c.Webhooks.Timeout > 0, "webhooks.timeout must be positive")
+	check(c.Webhooks.Concurrency > 0, "webhooks.concurrency must be positive")
+	check(c.Webhooks.MaxAttempts > 0, "webhooks.maxAttempts must be positive")
+	check(c.Webhooks.RetryBackoff > 0, "webhooks.retryBackoff must be positive")
+	check(c.Webhooks.MaxBackoff >= c.Webhooks.RetryBackoff, "webhooks.maxBackoff must not be less than webhooks.retryBackoff")
+
+	check(c.Events.PollInterval > 0, "events.pollInterval must be positive")
+	check(c.Events.Heartbeat > 0, "events.heartbeat must be positive")
+	check(c.Events.BufferSize > 0, "events.bufferSize must be positive")
+	check(c.Events.WriteTimeout > 0, "events.writeTimeout must be positive")
+
+	check(c.GraphQL.MaxDepth > 0, "graphql.maxDepth must be positive")
+	check(c.GraphQL.MaxComplexity > 0, "graphql.maxComplexity must be positive")
+
+	switch strings.ToLower(c.Log.Format) {
+	case "json", "text":
+	default:
+		check(false, "log.format: must be json or text, got %q", c.Log.Format)
 	}
-	return dbURL
+	return errs
 }
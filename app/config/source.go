@@ -0,0 +1,214 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// setting - один параметр конфигурации, найденный по тегам полей Config.
+type setting struct {
+	key    string        // Полное имя в файле, например http.addr
+	env    string        // Переменная окружения
+	secret string        // Способ скрытия значения
+	value  reflect.Value // Поле структуры
+}
+
+// settings перечисляет параметры конфигурации в порядке объявления полей.
+func (c *Config) settings() []setting {
+	var result []setting
+	var walk func(v reflect.Value, prefix string)
+	walk = func(v reflect.Value, prefix string) {
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			key := prefix + field.Tag.Get("key")
+			if field.Type.Kind() == reflect.Struct && field.Type != reflect.TypeOf(time.Duration(0)) {
+				walk(v.Field(i), key+".")
+				continue
+			}
+			result = append(result, setting{
+				key:    key,
+				env:    field.Tag.Get("env"),
+				secret: field.Tag.Get("secret"),
+				value:  v.Field(i),
+			})
+		}
+	}
+	walk(reflect.ValueOf(c).Elem(), "")
+	return result
+}
+
+// loadFile применяет параметры из файла YAML или TOML. Формат определяется по расширению.
+func (c *Config) loadFile(path string) []error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return []error{fmt.Errorf("failed to read config file: %w", err)}
+	}
+
+	raw := map[string]interface{}{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, &raw)
+	case ".toml":
+		err = toml.Unmarshal(content, &raw)
+	default:
+		return []error{fmt.Errorf("config file %s: unsupported format, use .yaml, .yml or .toml", path)}
+	}
+	if err != nil {
+		return []error{fmt.Errorf("config file %s: %w", path, err)}
+	}
+
+	values := map[string]interface{}{}
+	flatten(raw, "", values)
+
+	var errs []error
+	for _, s := range c.settings() {
+		value, ok := values[s.key]
+		if !ok {
+			continue
+		}
+		delete(values, s.key)
+		if err := setValue(s.value, fmt.Sprint(value)); err != nil {
+			errs = append(errs, fmt.Errorf("config file %s: %s: %w", path, s.key, err))
+		}
+	}
+
+	unknown := make([]string, 0, len(values))
+	for key := range values {
+		unknown = append(unknown, key)
+	}
+	sort.Strings(unknown)
+	for _, key := range unknown {
+		errs = append(errs, fmt.Errorf("config file %s: unknown setting %s", path, key))
+	}
+	return errs
+}
+
+// flatten превращает вложенные секции файла в ключи вида section.name.
+func flatten(raw map[string]interface{}, prefix string, values map[string]interface{}) {
+	for key, value := range raw {
+		if nested, ok := value.(map[string]interface{}); ok {
+			flatten(nested, prefix+key+".", values)
+			continue
+		}
+		values[prefix+key] = value
+	}
+}
+
+// loadEnv применяет параметры из переменных окружения.
+func (c *Config) loadEnv() []error {
+	var errs []error
+	for _, s := range c.settings() {
+		value, ok := os.LookupEnv(s.env)
+		if s.env == "" || !ok || value == "" {
+			continue
+		}
+		if err := setValue(s.value, value); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", s.env, err))
+		}
+	}
+	return errs
+}
+
+// setValue разбирает строковое значение в поле конфигурации.
+func setValue(field reflect.Value, value string) error {
+	if field.Type() == reflect.TypeOf(time.Duration(0)) {
+		duration, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", value)
+		}
+		field.SetInt(int64(duration))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", value)
+		}
+		field.SetBool(enabled)
+	case reflect.Int, reflect.Int64:
+		number, err := strconv.ParseInt(value, 10, field.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", value)
+		}
+		field.SetInt(number)
+	case reflect.Float64:
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", value)
+		}
+		field.SetFloat(number)
+	default:
+		return fmt.Errorf("unsupported setting type %s", field.Type())
+	}
+	return nil
+}
+
+// dsnPassword находит пароль в строке подключения вида "host=... password=...".
+var dsnPassword = regexp.MustCompile(`(password=)(\S+)`)
+
+// redact скрывает значение секретного параметра.
+func redact(s setting, value string) string {
+	if value == "" {
+		return value
+	}
+	switch s.secret {
+	case "":
+		return value
+	case "url":
+		if parsed, err := url.Parse(value); err == nil && parsed.Scheme != "" {
+			return parsed.Redacted()
+		}
+		return dsnPassword.ReplaceAllString(value, "${1}xxxxx")
+	default:
+		return "xxxxx"
+	}
+}
+
+// WriteYAML выводит конфигурацию в формате YAML. Секретные значения скрываются.
+func (c *Config) WriteYAML(w io.Writer) error {
+	root := &yaml.Node{Kind: yaml.MappingNode}
+	sections := map[string]*yaml.Node{}
+	for _, s := range c.settings() {
+		section, name, _ := strings.Cut(s.key, ".")
+		node, ok := sections[section]
+		if !ok {
+			node = &yaml.Node{Kind: yaml.MappingNode}
+			sections[section] = node
+			root.Content = append(root.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: section}, node)
+		}
+
+		value := &yaml.Node{Kind: yaml.ScalarNode}
+		switch v := s.value.Interface().(type) {
+		case time.Duration:
+			value.Value, value.Tag = v.String(), "!!str"
+		case string:
+			value.Value, value.Tag = redact(s, v), "!!str"
+		default:
+			value.Value = fmt.Sprint(v)
+		}
+		node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: name}, value)
+	}
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(root); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
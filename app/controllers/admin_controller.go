@@ -0,0 +1,158 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"music-library/app/backup"
+	"music-library/app/config"
+	"music-library/app/models"
+	"music-library/app/services"
+)
+
+// GetBackup выгружает резервную копию библиотеки.
+// @Summary Резервная копия библиотеки
+// @Description Возвращает сжатый tar-архив с манифестом (версия формата, версия схемы, контрольные суммы)
+// @Description и файлами NDJSON всех таблиц базы: песни, задания импорта, ревизии, журналы изменений и событий,
+// @Description подписки с доставками, пользователи, токены обновления и ключи доступа (вместе с хешами паролей, ключей и секретами подписок).
+// @Produce application/gzip
+// @Success 200 {file} file "Архив резервной копии"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /admin/backup [get]
+func GetBackup(w http.ResponseWriter, r *http.Request) {
+	slog.DebugContext(r.Context(), "Received request to create backup")
+
+	w.Header().Set("Content-Type", backup.ContentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, backup.FileName(time.Now())))
+
+	out := &responseCounter{w: w}
+	if _, err := services.CreateBackup(requestDB(r), out); err != nil {
+		slog.InfoContext(r.Context(), "Failed to create backup", "error", err)
+		if out.written {
+			// Архив уже передается клиенту, сообщить об ошибке можно только обрывом ответа.
+			panic(http.ErrAbortHandler)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Del("Content-Disposition")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to create backup",
+		})
+	}
+}
+
+// RestoreBackup восстанавливает библиотеку из резервной копии.
+// @Summary Восстановление из резервной копии
+// @Description Принимает архив, созданный GET /admin/backup или командой backup, в теле запроса или в поле file формы.
+// @Description Архив проверяется целиком до изменения данных.
+// @Description В режиме empty все таблицы базы, включая пользователей и ключи доступа, должны быть пустыми, а записи сохраняют ID.
+// @Description При включенной авторизации в базе уже есть администратор, поэтому в пустую базу восстанавливают командой restore.
+// @Description В режиме merge песни и задания импорта добавляются с новыми ID, песни с той же группой и названием пропускаются,
+// @Description а остальные таблицы архива не переносятся.
+// @Accept application/gzip
+// @Accept multipart/form-data
+// @Produce json
+// @Param mode query string false "Режим восстановления: empty (по умолчанию) или merge"
+// @Param file formData file false "Архив резервной копии"
+// @Success 200 {object} models.RestoreResult "Итог восстановления"
+// @Failure 400 {object} models.ErrorResponse "Ошибка в запросе"
+// @Failure 409 {object} models.ErrorResponse "Режим empty, а база данных не пуста"
+// @Failure 413 {object} models.ErrorResponse "Архив слишком большой"
+// @Failure 422 {object} models.ErrorResponse "Архив поврежден или снят с более новой схемы"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /admin/restore [post]
+func RestoreBackup(w http.ResponseWriter, r *http.Request) {
+	slog.DebugContext(r.Context(), "Received request to restore backup")
+
+	mode := r.URL.Query().Get("mode")
+	if mode == "" {
+		mode = services.RestoreModeEmpty
+	}
+	if !services.ValidRestoreMode(mode) {
+		slog.InfoContext(r.Context(), "Invalid restore mode", "mode", mode)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: services.ErrInvalidRestoreMode.Error(),
+		})
+		return
+	}
+
+	source, err := openBackupUpload(w, r)
+	if err != nil {
+		slog.InfoContext(r.Context(), "Failed to read backup archive", "error", err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Failed to read backup archive",
+		})
+		return
+	}
+	defer source.Close()
+
+	result, err := services.RestoreBackup(requestDB(r), source, mode)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		status, message := http.StatusInternalServerError, "Failed to restore backup"
+		switch {
+		case errors.As(err, &maxBytesErr):
+			status, message = http.StatusRequestEntityTooLarge, fmt.Sprintf("Backup archive must not exceed %d bytes", config.Get().Limits.RestoreMaxBytes)
+		case errors.Is(err, services.ErrDatabaseNotEmpty):
+			status, message = http.StatusConflict, err.Error()
+		case errors.Is(err, services.ErrInvalidBackup), errors.Is(err, services.ErrUnsupportedSchema):
+			status, message = http.StatusUnprocessableEntity, err.Error()
+		}
+		slog.InfoContext(r.Context(), "Failed to restore backup", "error", err)
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(models.ErrorResponse{
+			Code:    status,
+			Message: message,
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// openBackupUpload возвращает поток архива из формы multipart/form-data или из тела запроса.
+func openBackupUpload(w http.ResponseWriter, r *http.Request) (io.ReadCloser, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, config.Get().Limits.RestoreMaxBytes)
+
+	if !strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		return r.Body, nil
+	}
+
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		part, err := reader.NextPart()
+		if err != nil {
+			return nil, err
+		}
+		if part.FormName() == "file" {
+			return part, nil
+		}
+		part.Close()
+	}
+}
+
+// responseCounter запоминает, начата ли уже передача ответа клиенту.
+type responseCounter struct {
+	w       io.Writer
+	written bool
+}
+
+func (c *responseCounter) Write(p []byte) (int, error) {
+	c.written = true
+	return c.w.Write(p)
+}
@@ -0,0 +1,120 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"music-library/app/models"
+	"music-library/app/services"
+)
+
+// CreateAPIKey создает ключ доступа к API.
+// @Summary Создание ключа доступа
+// @Description Создает ключ с указанными разрешениями: songs:read, songs:write, songs:delete или admin.
+// @Description В rateLimits можно задать собственные лимиты запросов за период для классов read, write и enrich.
+// @Description Ключ возвращается только в этом ответе, в базе данных хранится лишь его хеш.
+// @Accept json
+// @Produce json
+// @Param key body models.CreateAPIKeyRequest true "Параметры ключа"
+// @Success 201 {object} models.CreatedAPIKey "Созданный ключ"
+// @Failure 400 {object} models.ErrorResponse "Ошибка в запросе"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /admin/api-keys [post]
+func CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	var request models.CreateAPIKeyRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		slog.InfoContext(r.Context(), "Failed to decode request body for creating API key")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid request payload",
+		})
+		return
+	}
+
+	created, err := services.CreateAPIKey(requestDB(r), request)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidAPIKey) {
+			slog.InfoContext(r.Context(), "Invalid API key request", "name", request.Name, "scopes", request.Scopes)
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(models.ErrorResponse{
+				Code:    http.StatusBadRequest,
+				Message: "Name must not be empty, scopes must be songs:read, songs:write, songs:delete or admin and rate limits positive numbers for read, write or enrich",
+			})
+			return
+		}
+		slog.InfoContext(r.Context(), "Failed to create API key", "error", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to create API key",
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(created)
+}
+
+// GetAPIKeys возвращает список ключей доступа.
+// @Summary Список ключей доступа
+// @Description Возвращает все ключи доступа, включая отозванные и просроченные. Сами ключи не возвращаются.
+// @Produce json
+// @Success 200 {array} models.APIKey "Ключи доступа"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /admin/api-keys [get]
+func GetAPIKeys(w http.ResponseWriter, r *http.Request) {
+	keys, err := services.ListAPIKeys(requestDB(r))
+	if err != nil {
+		slog.InfoContext(r.Context(), "Failed to retrieve API keys", "error", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to retrieve API keys",
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(keys)
+}
+
+// RevokeAPIKey отзывает ключ доступа.
+// @Summary Отзыв ключа доступа
+// @Description Отзывает ключ доступа, после чего запросы с ним отклоняются. Повторный отзыв ничего не меняет.
+// @Produce json
+// @Param id path string true "ID ключа"
+// @Success 204 "Ключ отозван"
+// @Failure 404 {object} models.ErrorResponse "Ключ не найден"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /admin/api-keys/{id} [delete]
+func RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	slog.DebugContext(r.Context(), "Received request to revoke API key", "id", id)
+
+	if _, err := services.RevokeAPIKey(requestDB(r), id); err != nil {
+		if errors.Is(err, services.ErrAPIKeyNotFound) {
+			slog.InfoContext(r.Context(), "API key not found", "id", id)
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(models.ErrorResponse{
+				Code:    http.StatusNotFound,
+				Message: "API key not found",
+			})
+			return
+		}
+		slog.InfoContext(r.Context(), "Failed to revoke API key", "id", id, "error", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to revoke API key",
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,139 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"music-library/app/config"
+	"music-library/app/models"
+	"music-library/app/services"
+)
+
+// GetSongHistory возвращает историю изменений песни.
+// @Summary История изменений песни
+// @Description Возвращает записи журнала изменений песни от новых к старым: кто, когда и в каком запросе
+// @Description создал, изменил, удалил или восстановил песню, с прежними и новыми значениями полей.
+// @Description История удаленной песни остается доступной.
+// @Produce json
+// @Param id path string true "ID песни"
+// @Param limit query int false "Максимальное количество записей"
+// @Param offset query int false "Количество пропускаемых записей"
+// @Success 200 {array} models.AuditEntry "Записи журнала"
+// @Failure 400 {object} models.ErrorResponse "Ошибка в запросе"
+// @Failure 404 {object} models.ErrorResponse "Песня не найдена"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /songs/{id}/history [get]
+func GetSongHistory(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	slog.DebugContext(r.Context(), "Received request for song history", "id", id)
+
+	songID, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		writeAuditError(w, r, http.StatusBadRequest, "Invalid song ID", err)
+		return
+	}
+	filter, ok := parseAuditFilter(w, r)
+	if !ok {
+		return
+	}
+
+	entries, err := services.SongHistory(requestDB(r), uint(songID), filter.Limit, filter.Offset)
+	if errors.Is(err, services.ErrSongNotFound) {
+		writeAuditError(w, r, http.StatusNotFound, "Song not found", err)
+		return
+	}
+	if err != nil {
+		writeAuditError(w, r, http.StatusInternalServerError, "Failed to retrieve song history", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(entries)
+}
+
+// GetAudit возвращает журнал изменений песен.
+// @Summary Журнал изменений
+// @Description Возвращает записи журнала изменений всех песен от новых к старым с фильтрами по участнику,
+// @Description операции, песне и интервалу времени [from, to).
+// @Produce json
+// @Param actor query string false "Участник: user:<id>, api_key:<id> или system"
+// @Param operation query string false "Операция: create, update, delete или restore"
+// @Param songId query int false "ID песни"
+// @Param from query string false "Начало интервала в RFC 3339"
+// @Param to query string false "Конец интервала в RFC 3339"
+// @Param limit query int false "Максимальное количество записей"
+// @Param offset query int false "Количество пропускаемых записей"
+// @Success 200 {array} models.AuditEntry "Записи журнала"
+// @Failure 400 {object} models.ErrorResponse "Ошибка в запросе"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /audit [get]
+func GetAudit(w http.ResponseWriter, r *http.Request) {
+	filter, ok := parseAuditFilter(w, r)
+	if !ok {
+		return
+	}
+	query := r.URL.Query()
+	filter.Actor = query.Get("actor")
+	filter.Operation = query.Get("operation")
+	if value := query.Get("songId"); value != "" {
+		songID, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			writeAuditError(w, r, http.StatusBadRequest, "Invalid songId", err)
+			return
+		}
+		filter.SongID = uint(songID)
+	}
+	for name, target := range map[string]**time.Time{"from": &filter.From, "to": &filter.To} {
+		value := query.Get(name)
+		if value == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			writeAuditError(w, r, http.StatusBadRequest, "Invalid "+name+", use RFC 3339", err)
+			return
+		}
+		*target = &parsed
+	}
+
+	entries, err := services.ListAudit(requestDB(r), filter)
+	if errors.Is(err, services.ErrInvalidAuditFilter) {
+		writeAuditError(w, r, http.StatusBadRequest, "Operation must be create, update, delete or restore", err)
+		return
+	}
+	if err != nil {
+		writeAuditError(w, r, http.StatusInternalServerError, "Failed to retrieve audit log", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(entries)
+}
+
+// parseAuditFilter разбирает пагинацию журнала с теми же ограничениями размера страницы, что и у списка песен.
+func parseAuditFilter(w http.ResponseWriter, r *http.Request) (services.AuditFilter, bool) {
+	limits := config.Get().Limits
+	page, ok := parseSongFilter(w, r, limits.DefaultPageSize)
+	if !ok {
+		return services.AuditFilter{}, false
+	}
+	if limits.MaxPageSize > 0 && (page.Limit <= 0 || page.Limit > limits.MaxPageSize) {
+		page.Limit = limits.MaxPageSize
+	}
+	return services.AuditFilter{Limit: page.Limit, Offset: page.Offset}, true
+}
+
+// writeAuditError отвечает ошибкой запроса к журналу изменений.
+func writeAuditError(w http.ResponseWriter, r *http.Request, status int, message string, err error) {
+	slog.InfoContext(r.Context(), message, "error", err)
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(models.ErrorResponse{
+		Code:    status,
+		Message: message,
+	})
+}
@@ -0,0 +1,284 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"music-library/app/auth"
+	"music-library/app/config"
+	"music-library/app/models"
+	"music-library/app/services"
+)
+
+// Register регистрирует пользователя с ролью viewer.
+// @Summary Регистрация пользователя
+// @Description Создает пользователя с ролью viewer. Роль меняет администратор через PATCH /admin/users/{id}.
+// @Accept json
+// @Produce json
+// @Param credentials body models.Credentials true "Имя пользователя и пароль"
+// @Success 201 {object} models.User "Зарегистрированный пользователь"
+// @Failure 400 {object} models.ErrorResponse "Ошибка в запросе"
+// @Failure 403 {object} models.ErrorResponse "Регистрация отключена"
+// @Failure 409 {object} models.ErrorResponse "Имя пользователя занято"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /auth/register [post]
+func Register(w http.ResponseWriter, r *http.Request) {
+	if !config.Get().Auth.Registration {
+		slog.InfoContext(r.Context(), "Registration is disabled")
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(models.ErrorResponse{
+			Code:    http.StatusForbidden,
+			Message: "Registration is disabled",
+		})
+		return
+	}
+
+	var credentials models.Credentials
+	if !decodeAuthRequest(w, r, &credentials) {
+		return
+	}
+
+	user, err := services.CreateUser(requestDB(r), credentials.Username, credentials.Password, auth.RoleViewer)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidUser):
+			slog.InfoContext(r.Context(), "Invalid registration request", "username", credentials.Username)
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(models.ErrorResponse{
+				Code:    http.StatusBadRequest,
+				Message: "Username must be 3-64 letters, digits, '.', '_' or '-' and password 8-72 bytes long",
+			})
+		case errors.Is(err, services.ErrUserExists):
+			slog.InfoContext(r.Context(), "Username is already taken", "username", credentials.Username)
+			w.WriteHeader(http.StatusConflict)
+			json.NewEncoder(w).Encode(models.ErrorResponse{
+				Code:    http.StatusConflict,
+				Message: "Username is already taken",
+			})
+		default:
+			slog.InfoContext(r.Context(), "Failed to register user", "error", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(models.ErrorResponse{
+				Code:    http.StatusInternalServerError,
+				Message: "Failed to register user",
+			})
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(user)
+}
+
+// Login выдает токены пользователю.
+// @Summary Вход пользователя
+// @Description Проверяет пароль и возвращает короткоживущий токен доступа JWT и одноразовый токен обновления.
+// @Accept json
+// @Produce json
+// @Param credentials body models.Credentials true "Имя пользователя и пароль"
+// @Success 200 {object} models.TokenResponse "Токены"
+// @Failure 400 {object} models.ErrorResponse "Ошибка в запросе"
+// @Failure 401 {object} models.ErrorResponse "Неверное имя пользователя или пароль"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /auth/login [post]
+func Login(w http.ResponseWriter, r *http.Request) {
+	var credentials models.Credentials
+	if !decodeAuthRequest(w, r, &credentials) {
+		return
+	}
+
+	tokens, err := services.Login(requestDB(r), credentials.Username, credentials.Password)
+	writeTokens(w, r, tokens, err)
+}
+
+// RefreshToken обменивает токен обновления на новую пару токенов.
+// @Summary Обновление токенов
+// @Description Выдает новый токен доступа и новый токен обновления, прежний токен обновления перестает действовать.
+// @Description Повторное использование обмененного токена закрывает все сессии пользователя.
+// @Accept json
+// @Produce json
+// @Param token body models.RefreshRequest true "Токен обновления"
+// @Success 200 {object} models.TokenResponse "Токены"
+// @Failure 400 {object} models.ErrorResponse "Ошибка в запросе"
+// @Failure 401 {object} models.ErrorResponse "Токен обновления недействителен"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /auth/refresh [post]
+func RefreshToken(w http.ResponseWriter, r *http.Request) {
+	var request models.RefreshRequest
+	if !decodeAuthRequest(w, r, &request) {
+		return
+	}
+
+	tokens, err := services.RefreshSession(requestDB(r), request.RefreshToken)
+	writeTokens(w, r, tokens, err)
+}
+
+// Logout закрывает сессию пользователя.
+// @Summary Выход пользователя
+// @Description Удаляет токен обновления. Выданный токен доступа действует до истечения срока.
+// @Accept json
+// @Produce json
+// @Param token body models.RefreshRequest true "Токен обновления"
+// @Success 204 "Сессия закрыта"
+// @Failure 400 {object} models.ErrorResponse "Ошибка в запросе"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /auth/logout [post]
+func Logout(w http.ResponseWriter, r *http.Request) {
+	var request models.RefreshRequest
+	if !decodeAuthRequest(w, r, &request) {
+		return
+	}
+
+	if err := services.Logout(requestDB(r), request.RefreshToken); err != nil {
+		slog.InfoContext(r.Context(), "Failed to delete refresh token", "error", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to log out",
+		})
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetIdentity возвращает участника, от имени которого выполняется запрос.
+// @Summary Текущий участник
+// @Description Возвращает вид участника (user, api_key или anonymous), его имя, роль и разрешения.
+// @Produce json
+// @Success 200 {object} models.Identity "Участник запроса"
+// @Failure 401 {object} models.ErrorResponse "Учетные данные не переданы или недействительны"
+// @Router /auth/me [get]
+func GetIdentity(w http.ResponseWriter, r *http.Request) {
+	principal := auth.PrincipalFrom(r.Context())
+	identity := models.Identity{Type: auth.PrincipalAnonymous, Scopes: auth.Scopes}
+	if principal != nil {
+		identity = models.Identity{
+			Type:   principal.Type,
+			ID:     principal.ID,
+			Name:   principal.Name,
+			Role:   principal.Role,
+			Scopes: principal.Scopes,
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(identity)
+}
+
+// GetUsers возвращает список пользователей.
+// @Summary Список пользователей
+// @Produce json
+// @Success 200 {array} models.User "Пользователи"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /admin/users [get]
+func GetUsers(w http.ResponseWriter, r *http.Request) {
+	users, err := services.ListUsers(requestDB(r))
+	if err != nil {
+		slog.InfoContext(r.Context(), "Failed to retrieve users", "error", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to retrieve users",
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(users)
+}
+
+// UpdateUser меняет роль пользователя.
+// @Summary Изменение роли пользователя
+// @Description Новая роль попадает в токен доступа пользователя при следующем обновлении токенов.
+// @Accept json
+// @Produce json
+// @Param id path string true "ID пользователя"
+// @Param user body models.UpdateUserRequest true "Новая роль"
+// @Success 200 {object} models.User "Пользователь"
+// @Failure 400 {object} models.ErrorResponse "Ошибка в запросе"
+// @Failure 404 {object} models.ErrorResponse "Пользователь не найден"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /admin/users/{id} [patch]
+func UpdateUser(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	var request models.UpdateUserRequest
+	if !decodeAuthRequest(w, r, &request) {
+		return
+	}
+
+	user, err := services.SetUserRole(requestDB(r), id, request.Role)
+	if err != nil {
+		switch {
+		case errors.Is(err, auth.ErrInvalidRole):
+			slog.InfoContext(r.Context(), "Invalid user role", "id", id, "role", request.Role)
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(models.ErrorResponse{
+				Code:    http.StatusBadRequest,
+				Message: "Role must be viewer, editor or admin",
+			})
+		case errors.Is(err, services.ErrUserNotFound):
+			slog.InfoContext(r.Context(), "User not found", "id", id)
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(models.ErrorResponse{
+				Code:    http.StatusNotFound,
+				Message: "User not found",
+			})
+		default:
+			slog.InfoContext(r.Context(), "Failed to update user", "id", id, "error", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(models.ErrorResponse{
+				Code:    http.StatusInternalServerError,
+				Message: "Failed to update user",
+			})
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(user)
+}
+
+// decodeAuthRequest разбирает тело запроса и отвечает 400, если оно некорректно.
+func decodeAuthRequest(w http.ResponseWriter, r *http.Request, request interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+		slog.InfoContext(r.Context(), "Failed to decode request body", "error", err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid request payload",
+		})
+		return false
+	}
+	return true
+}
+
+// writeTokens отвечает выданными токенами или ошибкой входа.
+func writeTokens(w http.ResponseWriter, r *http.Request, tokens models.TokenResponse, err error) {
+	if errors.Is(err, services.ErrInvalidCredentials) {
+		slog.InfoContext(r.Context(), "Invalid credentials")
+		w.Header().Set("WWW-Authenticate", `Bearer realm="music-library"`)
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(models.ErrorResponse{
+			Code:    http.StatusUnauthorized,
+			Message: "Invalid username, password or refresh token",
+		})
+		return
+	}
+	if err != nil {
+		slog.InfoContext(r.Context(), "Failed to issue tokens", "error", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to issue tokens",
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(tokens)
+}
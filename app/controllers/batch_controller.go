@@ -0,0 +1,181 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"music-library/app/config"
+	"music-library/app/models"
+	"music-library/app/services"
+)
+
+// CreateSongsBatch создает несколько песен за один запрос.
+// @Summary Пакетное добавление песен
+// @Description Принимает массив песен (application/json) или поток NDJSON (application/x-ndjson).
+// @Description Для каждой песни параллельно запрашиваются данные во внешнем API, результат возвращается по индексам входных данных.
+// @Description В режиме atomic пакет сохраняется целиком или не сохраняется вовсе, в режиме best-effort каждая песня сохраняется независимо.
+// @Accept json
+// @Accept application/x-ndjson
+// @Produce json
+// @Param mode query string false "Режим выполнения: atomic или best-effort (по умолчанию)"
+// @Param songs body []models.Song true "Песни для добавления"
+// @Success 200 {object} models.BatchResponse "Результаты по каждому элементу"
+// @Failure 400 {object} models.ErrorResponse "Ошибка в запросе"
+// @Failure 422 {object} models.BatchResponse "Пакет отменен в режиме atomic"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /songs:batch [post]
+func CreateSongsBatch(w http.ResponseWriter, r *http.Request) {
+	slog.DebugContext(r.Context(), "Received request to create songs batch")
+
+	var songs []models.Song
+	opts, ok := decodeBatchRequest(w, r, &songs)
+	if !ok {
+		return
+	}
+	opts.Enrich = true
+
+	results, committed, err := services.CreateSongs(requestDB(r), songs, opts)
+	writeBatchResponse(w, r, opts, committed, results, err)
+}
+
+// UpdateSongsBatch обновляет несколько песен за один запрос.
+// @Summary Пакетное обновление песен
+// @Description Принимает массив изменений вида {"id", "ifMatch", "changes"} (application/json) или поток NDJSON.
+// @Description ifMatch каждого элемента проверяется так же, как заголовок If-Match в UpdateSong.
+// @Accept json
+// @Accept application/x-ndjson
+// @Produce json
+// @Param mode query string false "Режим выполнения: atomic или best-effort (по умолчанию)"
+// @Param items body []models.BatchUpdateItem true "Изменения песен"
+// @Success 200 {object} models.BatchResponse "Результаты по каждому элементу"
+// @Failure 400 {object} models.ErrorResponse "Ошибка в запросе"
+// @Failure 422 {object} models.BatchResponse "Пакет отменен в режиме atomic"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /songs:batch [put]
+func UpdateSongsBatch(w http.ResponseWriter, r *http.Request) {
+	slog.DebugContext(r.Context(), "Received request to update songs batch")
+
+	var items []models.BatchUpdateItem
+	opts, ok := decodeBatchRequest(w, r, &items)
+	if !ok {
+		return
+	}
+
+	results, committed, err := services.UpdateSongs(requestDB(r), items, opts)
+	writeBatchResponse(w, r, opts, committed, results, err)
+}
+
+// DeleteSongsBatch удаляет несколько песен за один запрос.
+// @Summary Пакетное удаление песен
+// @Description Принимает массив элементов вида {"id", "ifMatch"} (application/json) или поток NDJSON.
+// @Accept json
+// @Accept application/x-ndjson
+// @Produce json
+// @Param mode query string false "Режим выполнения: atomic или best-effort (по умолчанию)"
+// @Param items body []models.BatchDeleteItem true "Удаляемые песни"
+// @Success 200 {object} models.BatchResponse "Результаты по каждому элементу"
+// @Failure 400 {object} models.ErrorResponse "Ошибка в запросе"
+// @Failure 422 {object} models.BatchResponse "Пакет отменен в режиме atomic"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /songs:batch [delete]
+func DeleteSongsBatch(w http.ResponseWriter, r *http.Request) {
+	slog.DebugContext(r.Context(), "Received request to delete songs batch")
+
+	var items []models.BatchDeleteItem
+	opts, ok := decodeBatchRequest(w, r, &items)
+	if !ok {
+		return
+	}
+
+	results, committed, err := services.DeleteSongs(requestDB(r), items, opts)
+	writeBatchResponse(w, r, opts, committed, results, err)
+}
+
+// decodeBatchRequest разбирает режим и тело пакетного запроса.
+// Возвращает false, если клиенту уже отправлен ответ с ошибкой.
+func decodeBatchRequest[T any](w http.ResponseWriter, r *http.Request, items *[]T) (services.BatchOptions, bool) {
+	opts := services.BatchOptions{Concurrency: config.Get().Limits.BatchEnrichConcurrency}
+
+	mode, ok := services.ParseBatchMode(r.URL.Query().Get("mode"))
+	if !ok {
+		slog.InfoContext(r.Context(), "Invalid batch mode", "mode", r.URL.Query().Get("mode"))
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Mode must be atomic or best-effort",
+		})
+		return opts, false
+	}
+	opts.Mode = mode
+
+	if err := decodeBatchBody(r, items); err != nil {
+		slog.InfoContext(r.Context(), "Failed to decode batch request body", "error", err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid request payload: " + err.Error(),
+		})
+		return opts, false
+	}
+
+	if len(*items) == 0 || len(*items) > config.Get().Limits.BatchMaxItems {
+		slog.InfoContext(r.Context(), "Invalid batch size", "size", len(*items))
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("Batch must contain from 1 to %d items", config.Get().Limits.BatchMaxItems),
+		})
+		return opts, false
+	}
+	return opts, true
+}
+
+// decodeBatchBody читает элементы пакета из JSON-массива или из NDJSON-потока.
+func decodeBatchBody[T any](r *http.Request, items *[]T) error {
+	decoder := json.NewDecoder(r.Body)
+	if !strings.Contains(r.Header.Get("Content-Type"), "ndjson") {
+		return decoder.Decode(items)
+	}
+
+	maxItems := config.Get().Limits.BatchMaxItems
+	for line := 1; ; line++ {
+		var item T
+		if err := decoder.Decode(&item); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+		*items = append(*items, item)
+		if len(*items) > maxItems {
+			return nil
+		}
+	}
+}
+
+// writeBatchResponse отправляет результаты пакетной операции.
+func writeBatchResponse(w http.ResponseWriter, r *http.Request, opts services.BatchOptions, committed bool, results []models.BatchItemResult, err error) {
+	if err != nil {
+		slog.InfoContext(r.Context(), "Failed to process batch", "error", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to process batch",
+		})
+		return
+	}
+
+	response := services.NewBatchResponse(opts, committed, results)
+	slog.DebugContext(r.Context(), "Processed batch", "summary", response.Summary)
+
+	w.Header().Set("Content-Type", "application/json")
+	if !committed {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+	}
+	json.NewEncoder(w).Encode(response)
+}
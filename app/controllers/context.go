@@ -0,0 +1,22 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+
+	"gorm.io/gorm"
+	"music-library/app/database"
+)
+
+// requestDB возвращает подключение к базе данных, привязанное к контексту запроса:
+// запросы к базе отменяются вместе с ним и попадают в журнал с ID запроса.
+// Фоновые задания, переживающие запрос, должны использовать backgroundDB.
+func requestDB(r *http.Request) *gorm.DB {
+	return database.DB.WithContext(r.Context())
+}
+
+// backgroundDB возвращает подключение для фоновой работы, начатой запросом: она не отменяется
+// с завершением запроса, но сохраняет его ID в журнале.
+func backgroundDB(r *http.Request) *gorm.DB {
+	return database.DB.WithContext(context.WithoutCancel(r.Context()))
+}
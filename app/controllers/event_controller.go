@@ -0,0 +1,171 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"music-library/app/config"
+	"music-library/app/models"
+	"music-library/app/services"
+)
+
+// eventReplayPageSize - сколько пропущенных событий читается из журнала за один запрос.
+const eventReplayPageSize = 500
+
+// eventStreamRetry - через сколько миллисекунд клиент переподключается к потоку после разрыва.
+const eventStreamRetry = 3000
+
+// StreamEvents отдает поток событий песен в формате Server-Sent Events.
+// @Summary Поток событий песен
+// @Description Отдает события song.created, song.updated, song.deleted и song.enriched по мере изменений в формате
+// @Description text/event-stream: id - ID события в журнале, event - тип, data - models.SongEvent в JSON.
+// @Description С заголовком Last-Event-ID (или параметром lastEventId) поток сначала отдает пропущенные события
+// @Description из журнала, без него - только новые. Пока событий нет, раз в events.heartbeat приходит комментарий.
+// @Description Клиент, не успевающий принимать события, и клиенты останавливающегося сервиса отключаются
+// @Description и продолжают с Last-Event-ID.
+// @Produce text/event-stream
+// @Param Last-Event-ID header int false "ID последнего полученного события"
+// @Param lastEventId query int false "ID последнего полученного события, если заголовок задать нельзя"
+// @Param type query string false "Типы событий через запятую"
+// @Param group query string false "Группа, без учета регистра"
+// @Success 200 {object} models.SongEvent "Поток событий"
+// @Failure 400 {object} models.ErrorResponse "Ошибка в запросе"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /events [get]
+func StreamEvents(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := services.EventFilter{Artist: query.Get("group")}
+	if types := query.Get("type"); types != "" {
+		for _, eventType := range strings.Split(types, ",") {
+			eventType = strings.TrimSpace(eventType)
+			if !slices.Contains(services.SongEventTypes, eventType) {
+				writeEventError(w, r, http.StatusBadRequest, "Type must be song.created, song.updated, song.deleted or song.enriched", nil)
+				return
+			}
+			filter.Types = append(filter.Types, eventType)
+		}
+	}
+
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = query.Get("lastEventId")
+	}
+	var last uint
+	if lastEventID != "" {
+		id, err := strconv.ParseUint(lastEventID, 10, 64)
+		if err != nil {
+			writeEventError(w, r, http.StatusBadRequest, "Invalid Last-Event-ID", err)
+			return
+		}
+		last = uint(id)
+	}
+
+	// Подписка оформляется до чтения журнала, чтобы не потерять события между чтением и подпиской.
+	subscription, unsubscribe := services.SubscribeSongEvents()
+	defer unsubscribe()
+
+	db := requestDB(r)
+	if lastEventID == "" {
+		latest, err := services.LatestSongEventID(db)
+		if err != nil {
+			writeEventError(w, r, http.StatusInternalServerError, "Failed to read song events", err)
+			return
+		}
+		last = latest
+	}
+
+	settings := config.Get().Events
+	stream := &eventStream{w: w, controller: http.NewResponseController(w), writeTimeout: settings.WriteTimeout}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	if err := stream.write(fmt.Sprintf("retry: %d\n\n", eventStreamRetry)); err != nil {
+		return
+	}
+	slog.DebugContext(r.Context(), "Event stream opened", "last_event_id", last, "types", filter.Types, "group", filter.Artist)
+
+	for {
+		events, err := services.ListSongEvents(db, last, filter, eventReplayPageSize)
+		if err != nil {
+			slog.InfoContext(r.Context(), "Failed to replay song events", "error", err)
+			return
+		}
+		for i := range events {
+			if err := stream.send(&events[i]); err != nil {
+				return
+			}
+			last = events[i].ID
+		}
+		if len(events) < eventReplayPageSize {
+			break
+		}
+	}
+
+	heartbeat := time.NewTicker(settings.Heartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-subscription.Closed:
+			slog.InfoContext(r.Context(), "Event stream closed by server", "last_event_id", last)
+			return
+		case event := <-subscription.Events:
+			if event.ID <= last || !filter.Match(&event) {
+				continue
+			}
+			if err := stream.send(&event); err != nil {
+				return
+			}
+			last = event.ID
+		case <-heartbeat.C:
+			if err := stream.write(": heartbeat\n\n"); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// eventStream пишет события клиенту, ограничивая время каждой записи: клиент, который не читает поток,
+// отключается по таймауту, а не держит обработчик бесконечно.
+type eventStream struct {
+	w            http.ResponseWriter
+	controller   *http.ResponseController
+	writeTimeout time.Duration
+}
+
+// send отправляет событие в формате Server-Sent Events.
+func (s *eventStream) send(event *models.SongEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return s.write(fmt.Sprintf("id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data))
+}
+
+// write записывает фрагмент потока и сразу отправляет его клиенту.
+func (s *eventStream) write(chunk string) error {
+	// Срок записи переопределяет http.writeTimeout сервера, который иначе оборвал бы длинный поток.
+	s.controller.SetWriteDeadline(time.Now().Add(s.writeTimeout))
+	if _, err := fmt.Fprint(s.w, chunk); err != nil {
+		return err
+	}
+	return s.controller.Flush()
+}
+
+// writeEventError отвечает ошибкой запроса к потоку событий.
+func writeEventError(w http.ResponseWriter, r *http.Request, status int, message string, err error) {
+	slog.InfoContext(r.Context(), message, "error", err)
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(models.ErrorResponse{
+		Code:    status,
+		Message: message,
+	})
+}
@@ -0,0 +1,66 @@
+package controllers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"music-library/app/exporter"
+	"music-library/app/models"
+	"music-library/app/services"
+)
+
+// ExportSongs выгружает каталог песен в файл выбранного формата.
+// @Summary Экспорт каталога
+// @Description Выгружает песни с теми же фильтрами, что и GetSongs, в формате CSV, JSON, NDJSON или XLSX.
+// @Description Песни читаются из базы данных построчно и сразу отправляются клиенту. По умолчанию выгружается весь каталог.
+// @Produce text/csv
+// @Produce json
+// @Produce application/x-ndjson
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param format query string false "Формат файла: csv (по умолчанию), json, ndjson или xlsx"
+// @Param group query string false "Группа"
+// @Param song query string false "Название песни"
+// @Param limit query int false "Максимальное количество песен"
+// @Param offset query int false "Количество пропускаемых песен"
+// @Success 200 {file} file "Файл экспорта"
+// @Failure 400 {object} models.ErrorResponse "Ошибка в запросе"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /songs/export [get]
+func ExportSongs(w http.ResponseWriter, r *http.Request) {
+	slog.DebugContext(r.Context(), "Received request to export songs")
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = exporter.FormatCSV
+	}
+	if !exporter.ValidFormat(format) {
+		slog.InfoContext(r.Context(), "Invalid export format", "format", format)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Format must be csv, json, ndjson or xlsx",
+		})
+		return
+	}
+
+	filter, ok := parseSongFilter(w, r, 0)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", exporter.ContentType(format))
+	w.Header().Set("Content-Disposition", `attachment; filename="`+exporter.FileName(format, time.Now())+`"`)
+
+	writer, err := exporter.NewWriter(w, format)
+	if err == nil {
+		var count int
+		count, err = services.ExportSongs(requestDB(r), filter, writer)
+		slog.DebugContext(r.Context(), "Exported songs", "count", count)
+	}
+	if err != nil {
+		// Заголовки и часть файла уже могли быть отправлены, поэтому ошибку остается только залогировать.
+		slog.InfoContext(r.Context(), "Failed to export songs", "error", err)
+	}
+}
@@ -0,0 +1,195 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+	"music-library/app/models"
+	"music-library/app/services"
+)
+
+// songFieldColumns сопоставляет JSON-поля песни с колонками таблицы songs.
+var songFieldColumns = map[string]string{
+	"id":          "id",
+	"createdAt":   "created_at",
+	"updatedAt":   "updated_at",
+	"deletedAt":   "deleted_at",
+	"group":       "artist",
+	"song":        "name",
+	"releaseDate": "release_date",
+	"text":        "text",
+	"link":        "link",
+	"album":       "album",
+	"filePath":    "file_path",
+	"fileSize":    "file_size",
+	"fileModTime": "file_mod_time",
+	"contentHash": "content_hash",
+	"duration":    "duration",
+	"bitrate":     "bitrate",
+	"version":     "version",
+	"createdBy":   "created_by",
+	"updatedBy":   "updated_by",
+	"etag":        "",
+}
+
+// songIncludes перечисляет связанные ресурсы, которые можно встроить в ответ через include=.
+// Ресурсы загружаются через db запроса, чтобы запросы к базе сохраняли его контекст.
+var songIncludes = map[string]func(db *gorm.DB, song models.Song) (interface{}, error){
+	"artist":     artistInclude,
+	"album":      albumInclude,
+	"enrichment": enrichmentInclude,
+}
+
+// unsupportedIncludes - связанные ресурсы, для которых в библиотеке нет данных.
+var unsupportedIncludes = map[string]string{
+	"translations": "song translations are not stored in the library",
+}
+
+// ArtistInclude описывает исполнителя, встраиваемого в песню через include=artist.
+// @Description Исполнитель песни
+type ArtistInclude struct {
+	Name      string `json:"name"`      // Название группы или исполнителя
+	SongCount int64  `json:"songCount"` // Количество песен исполнителя в библиотеке
+}
+
+// AlbumInclude описывает альбом, встраиваемый в песню через include=album.
+// @Description Альбом песни
+type AlbumInclude struct {
+	Name      string `json:"name"`      // Название альбома
+	Artist    string `json:"artist"`    // Группа или исполнитель альбома
+	SongCount int64  `json:"songCount"` // Количество песен альбома в библиотеке
+}
+
+// EnrichmentInclude описывает состояние обогащения песни данными внешнего API.
+// @Description Статус обогащения песни
+type EnrichmentInclude struct {
+	Status  string   `json:"status"`            // complete, partial или missing
+	Missing []string `json:"missing,omitempty"` // Поля, не заполненные внешним API
+}
+
+// parseList разбирает параметр вида "a,b,c" в список без пустых элементов.
+func parseList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseFields проверяет параметр fields= и возвращает колонки для выборки из базы.
+// ID и версия выбираются всегда, так как по ним вычисляется ETag.
+func parseFields(value string) (fields []string, columns []string, err error) {
+	fields = parseList(value)
+	if len(fields) == 0 {
+		return nil, nil, nil
+	}
+
+	columns = []string{"id", "version"}
+	for _, field := range fields {
+		column, ok := songFieldColumns[field]
+		if !ok {
+			return nil, nil, fmt.Errorf("unknown field: %s", field)
+		}
+		if column != "" && column != "id" && column != "version" {
+			columns = append(columns, column)
+		}
+	}
+	return fields, columns, nil
+}
+
+// parseIncludes проверяет параметр include= на поддерживаемые связанные ресурсы.
+func parseIncludes(value string) ([]string, error) {
+	includes := parseList(value)
+	for _, include := range includes {
+		if reason, ok := unsupportedIncludes[include]; ok {
+			return nil, fmt.Errorf("unsupported include: %s, %s", include, reason)
+		}
+		if _, ok := songIncludes[include]; !ok {
+			return nil, fmt.Errorf("unsupported include: %s", include)
+		}
+	}
+	return includes, nil
+}
+
+// projectSong оставляет в представлении песни только запрошенные поля и добавляет связанные ресурсы.
+// Без fields и include песня возвращается целиком.
+func projectSong(db *gorm.DB, song models.Song, fields []string, includes []string) (interface{}, error) {
+	if len(fields) == 0 && len(includes) == 0 {
+		return song, nil
+	}
+
+	raw, err := json.Marshal(song)
+	if err != nil {
+		return nil, err
+	}
+	var full map[string]interface{}
+	if err := json.Unmarshal(raw, &full); err != nil {
+		return nil, err
+	}
+
+	projected := full
+	if len(fields) > 0 {
+		projected = make(map[string]interface{}, len(fields)+len(includes))
+		for _, field := range fields {
+			if value, ok := full[field]; ok {
+				projected[field] = value
+			}
+		}
+	}
+
+	for _, include := range includes {
+		value, err := songIncludes[include](db, song)
+		if err != nil {
+			return nil, err
+		}
+		projected[include] = value
+	}
+	return projected, nil
+}
+
+// artistInclude загружает исполнителя песни вместе с количеством его песен.
+func artistInclude(db *gorm.DB, song models.Song) (interface{}, error) {
+	if song.Group == "" {
+		if err := db.Model(&models.Song{}).Select("artist").Where("id = ?", song.ID).Scan(&song.Group).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	artist := ArtistInclude{Name: song.Group}
+	if err := db.Model(&models.Song{}).Where("artist = ?", song.Group).Count(&artist.SongCount).Error; err != nil {
+		return nil, err
+	}
+	return artist, nil
+}
+
+// albumInclude загружает альбом песни вместе с количеством его песен. Для песни без альбома возвращается nil.
+func albumInclude(db *gorm.DB, song models.Song) (interface{}, error) {
+	var stored models.Song
+	if err := db.Model(&models.Song{}).Select("artist", "album").Where("id = ?", song.ID).Scan(&stored).Error; err != nil {
+		return nil, err
+	}
+	if stored.Album == "" {
+		return nil, nil
+	}
+
+	album := AlbumInclude{Name: stored.Album, Artist: stored.Group}
+	if err := db.Model(&models.Song{}).Where("artist = ? AND album = ?", stored.Group, stored.Album).Count(&album.SongCount).Error; err != nil {
+		return nil, err
+	}
+	return album, nil
+}
+
+// enrichmentInclude определяет, какие данные внешнего API уже есть у песни.
+func enrichmentInclude(db *gorm.DB, song models.Song) (interface{}, error) {
+	var stored models.SongDetail
+	if err := db.Model(&models.Song{}).Select("release_date", "text", "link").Where("id = ?", song.ID).Scan(&stored).Error; err != nil {
+		return nil, err
+	}
+
+	status, missing := services.Enrichment(stored)
+	return EnrichmentInclude{Status: status, Missing: missing}, nil
+}
@@ -0,0 +1,37 @@
+package controllers
+
+import (
+	"net/http"
+	"sync"
+
+	"music-library/app/graph"
+)
+
+// graphQLHandler исполняет запросы GraphQL. Создается при первом запросе, когда конфигурация уже загружена.
+var graphQLHandler = sync.OnceValue(graph.NewHandler)
+
+// GraphQL выполняет запрос GraphQL к каталогу песен.
+// @Summary Запрос GraphQL
+// @Description Выполняет запрос или мутацию GraphQL по схеме app/graph/schema.graphqls: список песен с фильтрами,
+// @Description поиском и пагинацией, песня по ID с исполнителем, статусом обогащения и куплетами постранично,
+// @Description мутации addSong, updateSong и deleteSong. Маршрут требует songs:read, мутации дополнительно
+// @Description проверяют songs:write или songs:delete. GET принимает только запросы без мутаций.
+// @Description Запросы глубже graphql.maxDepth или сложнее graphql.maxComplexity отклоняются до выполнения
+// @Description с кодом DEPTH_LIMIT_EXCEEDED или COMPLEXITY_LIMIT_EXCEEDED.
+// @Description Ошибки возвращаются в поле errors с кодом в extensions.code. Запросы методом POST учитываются
+// @Description в ограничении частоты изменений rateLimit.write, методом GET - в ограничении чтения.
+// @Description Каждая мутация addSong обращается к внешнему API и дополнительно расходует лимит rateLimit.enrich,
+// @Description как отдельный POST /songs. Когда он исчерпан, мутация завершается ошибкой с кодом RATE_LIMITED
+// @Description и числом секунд до появления токена в extensions.retryAfter.
+// @Accept json
+// @Produce json
+// @Param query query string false "Запрос для метода GET"
+// @Param variables query string false "Переменные запроса в JSON для метода GET"
+// @Success 200 {object} object "Ответ GraphQL с полями data и errors"
+// @Failure 400 {object} object "Тело запроса не разобрано"
+// @Failure 422 {object} object "Запрос не прошел проверку по схеме"
+// @Router /graphql [get]
+// @Router /graphql [post]
+func GraphQL(w http.ResponseWriter, r *http.Request) {
+	graphQLHandler().ServeHTTP(w, r)
+}
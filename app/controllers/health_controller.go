@@ -0,0 +1,96 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"runtime"
+	"time"
+
+	"music-library/app/database"
+	"music-library/app/health"
+	"music-library/app/models"
+	"music-library/app/services"
+)
+
+// Состояния готовности сервиса.
+const (
+	readinessReady    = "ready"
+	readinessNotReady = "not_ready"
+)
+
+// Healthz сообщает, что процесс жив. Зависимости не проверяются.
+// @Summary Проверка жизнеспособности
+// @Description Отвечает 200, пока процесс способен обрабатывать запросы. Зависимости не проверяются.
+// @Produce json
+// @Success 200 {object} models.HealthResponse "Процесс работает"
+// @Router /healthz [get]
+func Healthz(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(models.HealthResponse{Status: "ok"})
+}
+
+// Readyz проверяет, готов ли сервис принимать запросы.
+// @Summary Проверка готовности
+// @Description Проверяет доступность базы данных, актуальность схемы и доступность внешнего API.
+// @Description Отказ некритичных проверок (health.nonCriticalChecks) не делает сервис неготовым.
+// @Produce json
+// @Success 200 {object} models.ReadinessResponse "Сервис готов"
+// @Failure 503 {object} models.ReadinessResponse "Критичная зависимость недоступна"
+// @Router /readyz [get]
+func Readyz(w http.ResponseWriter, r *http.Request) {
+	checks, ready := health.RunChecks(r.Context(), services.HealthChecks(database.DB))
+
+	response := models.ReadinessResponse{Status: readinessReady, Checks: checks}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if !ready {
+		response.Status = readinessNotReady
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+// Status возвращает подробное состояние сервиса.
+// @Summary Состояние сервиса
+// @Description Возвращает версию сборки, время работы, статистику пула соединений с базой данных
+// @Description и результаты проверок зависимостей с их задержками.
+// @Produce json
+// @Success 200 {object} models.StatusResponse "Состояние сервиса"
+// @Router /status [get]
+func Status(w http.ResponseWriter, r *http.Request) {
+	checks, ready := health.RunChecks(r.Context(), services.HealthChecks(database.DB))
+
+	uptime := health.Uptime()
+	response := models.StatusResponse{
+		Status:        readinessReady,
+		Version:       health.Version,
+		Revision:      health.Revision(),
+		GoVersion:     runtime.Version(),
+		StartedAt:     health.StartedAt(),
+		Uptime:        uptime.Round(time.Second).String(),
+		UptimeSeconds: int64(uptime.Seconds()),
+		SchemaVersion: database.SchemaVersion(),
+		Checks:        checks,
+	}
+	if !ready {
+		response.Status = readinessNotReady
+	}
+	if sqlDB, err := database.DB.DB(); err == nil {
+		stats := sqlDB.Stats()
+		response.Database = models.DBPoolStats{
+			MaxOpenConnections: stats.MaxOpenConnections,
+			OpenConnections:    stats.OpenConnections,
+			InUse:              stats.InUse,
+			Idle:               stats.Idle,
+			WaitCount:          stats.WaitCount,
+			WaitDuration:       stats.WaitDuration.String(),
+			MaxIdleClosed:      stats.MaxIdleClosed,
+			MaxLifetimeClosed:  stats.MaxLifetimeClosed,
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,300 @@
+package controllers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"music-library/app/config"
+	"music-library/app/importer"
+	"music-library/app/models"
+	"music-library/app/services"
+)
+
+// CreateImport создает задание импорта каталога из файла CSV, JSON или NDJSON.
+// @Summary Импорт каталога из файла
+// @Description Принимает файл в теле запроса или в поле file формы multipart/form-data.
+// @Description В пробном запуске (dryRun=true) данные не изменяются, а в ответе перечислено, что будет создано, обновлено или пропущено.
+// @Description Иначе задание выполняется в фоне, его прогресс доступен через GET /imports/{id}.
+// @Accept text/csv
+// @Accept json
+// @Accept application/x-ndjson
+// @Accept multipart/form-data
+// @Produce json
+// @Param format query string false "Формат файла: csv, json или ndjson (по умолчанию по имени файла или Content-Type)"
+// @Param fileName query string false "Имя файла, если он передан в теле запроса"
+// @Param mapping query string false "Сопоставление полей песни с колонками, например group=Artist,song=Title"
+// @Param delimiter query string false "Разделитель колонок CSV, по умолчанию запятая"
+// @Param dryRun query bool false "Пробный запуск без изменения данных"
+// @Param enrich query bool false "Запрашивать данные новых песен во внешнем API"
+// @Param onDuplicate query string false "Что делать с песнями, которые уже есть в библиотеке или выше в файле (та же группа и название без учета регистра): skip (по умолчанию) или update. В отличие от POST /songs, импорт не создает дубликатов"
+// @Param file formData file false "Файл импорта"
+// @Success 200 {object} models.ImportReport "Результат пробного запуска"
+// @Success 202 {object} models.ImportJob "Задание импорта поставлено в очередь"
+// @Failure 400 {object} models.ErrorResponse "Ошибка в запросе"
+// @Failure 413 {object} models.ErrorResponse "Файл слишком большой"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /imports [post]
+func CreateImport(w http.ResponseWriter, r *http.Request) {
+	slog.DebugContext(r.Context(), "Received request to create import job")
+	query := r.URL.Query()
+
+	source, fileName, err := readImportUpload(w, r)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			slog.InfoContext(r.Context(), "Import file is too large")
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			json.NewEncoder(w).Encode(models.ErrorResponse{
+				Code:    http.StatusRequestEntityTooLarge,
+				Message: fmt.Sprintf("Import file must not exceed %d bytes", config.Get().Limits.ImportMaxBytes),
+			})
+			return
+		}
+		slog.InfoContext(r.Context(), "Failed to read import file", "error", err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Failed to read import file",
+		})
+		return
+	}
+
+	opts := services.ImportOptions{
+		FileName:    fileName,
+		Format:      query.Get("format"),
+		Mapping:     query.Get("mapping"),
+		Delimiter:   query.Get("delimiter"),
+		OnDuplicate: query.Get("onDuplicate"),
+	}
+	if opts.Format == "" && importer.DetectFormat(fileName) == "" {
+		opts.Format = formatFromContentType(r.Header.Get("Content-Type"))
+	}
+	opts.DryRun, _ = strconv.ParseBool(query.Get("dryRun"))
+	opts.Enrich, _ = strconv.ParseBool(query.Get("enrich"))
+
+	job, err := services.CreateImportJob(requestDB(r), source, opts)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidImport) {
+			slog.InfoContext(r.Context(), "Invalid import request", "error", err)
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(models.ErrorResponse{
+				Code:    http.StatusBadRequest,
+				Message: err.Error(),
+			})
+			return
+		}
+		slog.InfoContext(r.Context(), "Failed to create import job", "error", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to create import job",
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", fmt.Sprintf("/imports/%d", job.ID))
+
+	if !job.DryRun {
+		if err := services.StartImportJob(backgroundDB(r), job); err != nil {
+			writeImportStartError(w, r, err)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(job)
+		return
+	}
+
+	report := models.ImportReport{}
+	err = services.RunImportJob(requestDB(r), job, func(row models.ImportRowResult) {
+		report.Rows = append(report.Rows, row)
+	})
+	if err != nil {
+		slog.InfoContext(r.Context(), "Dry-run import failed", "error", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to run import: " + err.Error(),
+		})
+		return
+	}
+	report.Job = *job
+	json.NewEncoder(w).Encode(report)
+}
+
+// GetImport возвращает задание импорта с прогрессом обработки.
+// @Summary Получение задания импорта
+// @Description Возвращает статус и прогресс задания импорта.
+// @Produce json
+// @Param id path string true "ID задания импорта"
+// @Success 200 {object} models.ImportJob "Задание импорта"
+// @Failure 404 {object} models.ErrorResponse "Задание не найдено"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /imports/{id} [get]
+func GetImport(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	slog.DebugContext(r.Context(), "Received request for import job", "id", id)
+
+	job, ok := loadImportJob(w, r, id)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(job)
+}
+
+// ResumeImport возобновляет прерванное задание импорта с первой необработанной записи.
+// @Summary Возобновление задания импорта
+// @Description Продолжает обработку задания импорта, остановленного из-за ошибки или перезапуска сервиса.
+// @Description Задание, которое уже выполняется, возобновить нельзя. Задание, прогресс которого не сохранялся
+// @Description 5 минут (например, после падения сервиса), считается прерванным.
+// @Produce json
+// @Param id path string true "ID задания импорта"
+// @Success 202 {object} models.ImportJob "Задание импорта возобновлено"
+// @Failure 404 {object} models.ErrorResponse "Задание не найдено"
+// @Failure 409 {object} models.ErrorResponse "Задание уже завершено или выполняется"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /imports/{id}/resume [post]
+func ResumeImport(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	slog.DebugContext(r.Context(), "Received request to resume import job", "id", id)
+
+	job, ok := loadImportJob(w, r, id)
+	if !ok {
+		return
+	}
+
+	if err := services.StartImportJob(backgroundDB(r), job); err != nil {
+		writeImportStartError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(job)
+}
+
+// GetImportErrors возвращает отчет об ошибках задания импорта в формате CSV.
+// @Summary Отчет об ошибках импорта
+// @Description Возвращает CSV-файл с номером, описанием ошибки и исходным содержимым каждой необработанной записи.
+// @Produce text/csv
+// @Param id path string true "ID задания импорта"
+// @Success 200 {file} file "Отчет об ошибках"
+// @Failure 404 {object} models.ErrorResponse "Задание не найдено"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /imports/{id}/errors [get]
+func GetImportErrors(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	slog.DebugContext(r.Context(), "Received request for import errors of job", "id", id)
+
+	job, ok := loadImportJob(w, r, id)
+	if !ok {
+		return
+	}
+
+	importErrors, err := services.GetImportErrors(requestDB(r), job.ID)
+	if err != nil {
+		slog.InfoContext(r.Context(), "Failed to retrieve import errors", "error", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to retrieve import errors",
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="import-%d-errors.csv"`, job.ID))
+
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"row", "error", "raw"})
+	for _, importError := range importErrors {
+		writer.Write([]string{strconv.Itoa(importError.Row), importError.Message, importError.Raw})
+	}
+	writer.Flush()
+}
+
+// loadImportJob загружает задание импорта и отправляет ошибку клиенту, если его нет.
+func loadImportJob(w http.ResponseWriter, r *http.Request, id string) (*models.ImportJob, bool) {
+	job, err := services.GetImportJob(requestDB(r), id)
+	if err == nil {
+		return job, true
+	}
+
+	if errors.Is(err, services.ErrImportNotFound) {
+		slog.InfoContext(r.Context(), "Import job not found", "id", id)
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(models.ErrorResponse{
+			Code:    http.StatusNotFound,
+			Message: "Import job not found",
+		})
+		return nil, false
+	}
+
+	slog.InfoContext(r.Context(), "Failed to retrieve import job", "error", err)
+	w.WriteHeader(http.StatusInternalServerError)
+	json.NewEncoder(w).Encode(models.ErrorResponse{
+		Code:    http.StatusInternalServerError,
+		Message: "Failed to retrieve import job",
+	})
+	return nil, false
+}
+
+// writeImportStartError отвечает ошибкой запуска задания импорта.
+func writeImportStartError(w http.ResponseWriter, r *http.Request, err error) {
+	status, message := http.StatusInternalServerError, "Failed to start import job"
+	switch {
+	case errors.Is(err, services.ErrImportCompleted):
+		status, message = http.StatusConflict, "Import job is already completed"
+	case errors.Is(err, services.ErrImportRunning):
+		status, message = http.StatusConflict, "Import job is already running"
+	case errors.Is(err, services.ErrImportNotFound):
+		status, message = http.StatusNotFound, "Import job not found"
+	}
+	slog.InfoContext(r.Context(), message, "error", err)
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(models.ErrorResponse{
+		Code:    status,
+		Message: message,
+	})
+}
+
+// readImportUpload читает файл импорта из формы multipart/form-data или из тела запроса.
+func readImportUpload(w http.ResponseWriter, r *http.Request) ([]byte, string, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, config.Get().Limits.ImportMaxBytes)
+
+	if !strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		source, err := io.ReadAll(r.Body)
+		return source, r.URL.Query().Get("fileName"), err
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		return nil, "", err
+	}
+	defer file.Close()
+
+	source, err := io.ReadAll(file)
+	return source, header.Filename, err
+}
+
+// formatFromContentType определяет формат импорта по заголовку Content-Type.
+func formatFromContentType(contentType string) string {
+	switch {
+	case strings.Contains(contentType, "ndjson"), strings.Contains(contentType, "jsonl"):
+		return importer.FormatNDJSON
+	case strings.Contains(contentType, "json"):
+		return importer.FormatJSON
+	case strings.Contains(contentType, "csv"):
+		return importer.FormatCSV
+	}
+	return ""
+}
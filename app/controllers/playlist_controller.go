@@ -0,0 +1,151 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"music-library/app/models"
+	"music-library/app/playlist"
+	"music-library/app/services"
+)
+
+// ExportPlaylist выгружает выбранные песни в виде плейлиста M3U8 или XSPF.
+// @Summary Экспорт плейлиста
+// @Description Формирует плейлист из песен с указанными ids (в переданном порядке) или из песен, отобранных теми же фильтрами, что и GetSongs.
+// @Description В качестве адреса записи используется ссылка на песню.
+// @Produce application/vnd.apple.mpegurl
+// @Produce application/xspf+xml
+// @Param format query string false "Формат плейлиста: m3u8 (по умолчанию) или xspf"
+// @Param title query string false "Название плейлиста"
+// @Param ids query string false "ID песен через запятую"
+// @Param group query string false "Группа"
+// @Param song query string false "Название песни"
+// @Param limit query int false "Максимальное количество песен"
+// @Param offset query int false "Количество пропускаемых песен"
+// @Success 200 {file} file "Плейлист"
+// @Failure 400 {object} models.ErrorResponse "Ошибка в запросе"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /songs/playlist [get]
+func ExportPlaylist(w http.ResponseWriter, r *http.Request) {
+	slog.DebugContext(r.Context(), "Received request to export playlist")
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = playlist.FormatM3U8
+	}
+	if !playlist.ValidFormat(format) {
+		slog.InfoContext(r.Context(), "Invalid playlist format", "format", format)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Format must be m3u8 or xspf",
+		})
+		return
+	}
+
+	var ids []uint
+	for _, value := range parseList(r.URL.Query().Get("ids")) {
+		id, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			slog.InfoContext(r.Context(), "Invalid song ID in ids", "value", value)
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(models.ErrorResponse{
+				Code:    http.StatusBadRequest,
+				Message: "Invalid ids",
+			})
+			return
+		}
+		ids = append(ids, uint(id))
+	}
+
+	filter, ok := parseSongFilter(w, r, 0)
+	if !ok {
+		return
+	}
+
+	entries, err := services.PlaylistEntries(requestDB(r), ids, filter)
+	if err != nil {
+		slog.InfoContext(r.Context(), "Failed to retrieve playlist songs", "error", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to retrieve songs",
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", playlist.ContentType(format))
+	w.Header().Set("Content-Disposition", `attachment; filename="playlist.`+format+`"`)
+	if err := playlist.Write(w, format, r.URL.Query().Get("title"), entries); err != nil {
+		slog.InfoContext(r.Context(), "Failed to write playlist", "error", err)
+	}
+}
+
+// ImportPlaylist разбирает плейлист M3U8 или XSPF и сопоставляет его записи с песнями каталога.
+// @Summary Импорт плейлиста
+// @Description Записи сопоставляются с песнями по ссылке или по нормализованным исполнителю и названию.
+// @Description Для несопоставленных записей предлагаются похожие песни того же исполнителя или с тем же названием.
+// @Accept application/vnd.apple.mpegurl
+// @Accept application/xspf+xml
+// @Produce json
+// @Param format query string false "Формат плейлиста: m3u8 или xspf (по умолчанию определяется по имени файла или содержимому)"
+// @Param fileName query string false "Имя файла плейлиста"
+// @Success 200 {object} models.PlaylistImportResult "Результат сопоставления"
+// @Failure 400 {object} models.ErrorResponse "Ошибка в запросе"
+// @Failure 413 {object} models.ErrorResponse "Плейлист слишком большой"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /playlists/import [post]
+func ImportPlaylist(w http.ResponseWriter, r *http.Request) {
+	slog.DebugContext(r.Context(), "Received request to import playlist")
+
+	source, fileName, err := readImportUpload(w, r)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		status := http.StatusBadRequest
+		if errors.As(err, &maxBytesErr) {
+			status = http.StatusRequestEntityTooLarge
+		}
+		slog.InfoContext(r.Context(), "Failed to read playlist", "error", err)
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(models.ErrorResponse{
+			Code:    status,
+			Message: "Failed to read playlist",
+		})
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = playlist.DetectFormat(fileName, source)
+	}
+
+	entries, err := playlist.Parse(bytes.NewReader(source), format)
+	if err != nil {
+		slog.InfoContext(r.Context(), "Failed to parse playlist", "error", err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	result, err := services.MatchPlaylist(requestDB(r), format, entries)
+	if err != nil {
+		slog.InfoContext(r.Context(), "Failed to match playlist entries", "error", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to match playlist entries",
+		})
+		return
+	}
+
+	slog.DebugContext(r.Context(), "Matched playlist entries", "matched", result.Matched, "total", result.Total)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
@@ -0,0 +1,79 @@
+package controllers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"music-library/app/config"
+	"music-library/app/models"
+	"music-library/app/services"
+)
+
+// notModified обрабатывает If-None-Match для запросов на чтение.
+// Возвращает true, если клиенту уже отправлен ответ 304 Not Modified.
+func notModified(w http.ResponseWriter, r *http.Request, etag string) bool {
+	w.Header().Set("ETag", etag)
+
+	header := r.Header.Get("If-None-Match")
+	if header == "" || !services.MatchWeakETag(header, etag) {
+		return false
+	}
+
+	slog.DebugContext(r.Context(), "ETag matched If-None-Match, returning 304", "etag", etag)
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
+
+// checkIfMatch проверяет заголовок If-Match перед изменением песни.
+// Возвращает false, если клиенту уже отправлен ответ 428 или 412.
+func checkIfMatch(w http.ResponseWriter, r *http.Request, song models.Song) bool {
+	header := r.Header.Get("If-Match")
+	if header == "" {
+		if !config.Get().Features.RequireIfMatch {
+			return true
+		}
+		slog.InfoContext(r.Context(), "If-Match header is required but missing")
+		w.WriteHeader(http.StatusPreconditionRequired)
+		json.NewEncoder(w).Encode(models.ErrorResponse{
+			Code:    http.StatusPreconditionRequired,
+			Message: "If-Match header is required",
+		})
+		return false
+	}
+
+	if !services.MatchETag(header, song.ETag) {
+		slog.InfoContext(r.Context(), "If-Match does not match current ETag", "etag", song.ETag)
+		writePreconditionFailed(w)
+		return false
+	}
+	return true
+}
+
+// writePreconditionFailed отправляет ответ 412, когда песня была изменена другим клиентом.
+func writePreconditionFailed(w http.ResponseWriter) {
+	w.WriteHeader(http.StatusPreconditionFailed)
+	json.NewEncoder(w).Encode(models.ErrorResponse{
+		Code:    http.StatusPreconditionFailed,
+		Message: "Song was modified by another request",
+	})
+}
+
+// listETag строит ETag для списка песен из ETag-ов отдельных записей и набора полей fields.
+func listETag(songs []models.Song, fields []string) string {
+	hash := sha256.New()
+	hash.Write([]byte(strings.Join(fields, ",") + "\n"))
+	for _, song := range songs {
+		hash.Write([]byte(song.ETag))
+	}
+	return `W/"` + hex.EncodeToString(hash.Sum(nil))[:32] + `"`
+}
+
+// representationETag строит слабый ETag по телу ответа.
+func representationETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `W/"` + hex.EncodeToString(sum[:])[:32] + `"`
+}
@@ -0,0 +1,214 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"music-library/app/services"
+	"music-library/app/textdiff"
+)
+
+// GetSongRevisions возвращает ревизии песни.
+// @Summary Ревизии песни
+// @Description Возвращает сохраненное содержимое песни для каждой ее версии, от новых ревизий к старым.
+// @Description Номер ревизии совпадает с версией песни. Ревизии удаленной песни остаются доступными.
+// @Produce json
+// @Param id path string true "ID песни"
+// @Param limit query int false "Максимальное количество ревизий"
+// @Param offset query int false "Количество пропускаемых ревизий"
+// @Success 200 {array} models.SongRevision "Ревизии песни"
+// @Failure 400 {object} models.ErrorResponse "Ошибка в запросе"
+// @Failure 404 {object} models.ErrorResponse "Песня не найдена"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /songs/{id}/revisions [get]
+func GetSongRevisions(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	slog.DebugContext(r.Context(), "Received request for song revisions", "id", id)
+
+	songID, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		writeAuditError(w, r, http.StatusBadRequest, "Invalid song ID", err)
+		return
+	}
+	filter, ok := parseAuditFilter(w, r)
+	if !ok {
+		return
+	}
+
+	revisions, err := services.ListRevisions(requestDB(r), uint(songID), filter.Limit, filter.Offset)
+	if errors.Is(err, services.ErrSongNotFound) {
+		writeAuditError(w, r, http.StatusNotFound, "Song not found", err)
+		return
+	}
+	if err != nil {
+		writeAuditError(w, r, http.StatusInternalServerError, "Failed to retrieve song revisions", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(revisions)
+}
+
+// GetSongRevision возвращает одну ревизию песни.
+// @Summary Ревизия песни
+// @Description Возвращает содержимое песни в указанной ревизии.
+// @Produce json
+// @Param id path string true "ID песни"
+// @Param rev path int true "Номер ревизии"
+// @Success 200 {object} models.SongRevision "Ревизия песни"
+// @Failure 400 {object} models.ErrorResponse "Ошибка в запросе"
+// @Failure 404 {object} models.ErrorResponse "Ревизия не найдена"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /songs/{id}/revisions/{rev} [get]
+func GetSongRevision(w http.ResponseWriter, r *http.Request) {
+	songID, rev, ok := parseRevisionPath(w, r)
+	if !ok {
+		return
+	}
+
+	revision, err := services.GetRevision(requestDB(r), songID, rev)
+	if errors.Is(err, services.ErrRevisionNotFound) {
+		writeAuditError(w, r, http.StatusNotFound, "Revision not found", err)
+		return
+	}
+	if err != nil {
+		writeAuditError(w, r, http.StatusInternalServerError, "Failed to retrieve song revision", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(revision)
+}
+
+// DiffSongRevisions возвращает diff текста песни между двумя ревизиями.
+// @Summary Diff текста между ревизиями
+// @Description Строит построчный diff текста песни между ревизиями from и to с тремя строками контекста.
+// @Description format=unified возвращает unified diff (text/x-diff), format=json - список фрагментов.
+// @Produce json
+// @Produce text/x-diff
+// @Param id path string true "ID песни"
+// @Param from query int true "Исходная ревизия"
+// @Param to query int true "Конечная ревизия"
+// @Param format query string false "Формат: json (по умолчанию) или unified"
+// @Success 200 {object} models.RevisionDiff "Diff текста"
+// @Failure 400 {object} models.ErrorResponse "Ошибка в запросе"
+// @Failure 404 {object} models.ErrorResponse "Ревизия не найдена"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /songs/{id}/revisions/diff [get]
+func DiffSongRevisions(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	songID, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		writeAuditError(w, r, http.StatusBadRequest, "Invalid song ID", err)
+		return
+	}
+	from, err := strconv.ParseUint(query.Get("from"), 10, 64)
+	if err != nil {
+		writeAuditError(w, r, http.StatusBadRequest, "Invalid from revision", err)
+		return
+	}
+	to, err := strconv.ParseUint(query.Get("to"), 10, 64)
+	if err != nil {
+		writeAuditError(w, r, http.StatusBadRequest, "Invalid to revision", err)
+		return
+	}
+	format := query.Get("format")
+	if format != "" && format != "json" && format != "unified" {
+		writeAuditError(w, r, http.StatusBadRequest, "Format must be json or unified", nil)
+		return
+	}
+
+	diff, hunks, err := services.DiffRevisions(requestDB(r), uint(songID), uint(from), uint(to))
+	if errors.Is(err, services.ErrRevisionNotFound) {
+		writeAuditError(w, r, http.StatusNotFound, "Revision not found", err)
+		return
+	}
+	if err != nil {
+		writeAuditError(w, r, http.StatusInternalServerError, "Failed to diff song revisions", err)
+		return
+	}
+
+	if format == "unified" {
+		w.Header().Set("Content-Type", "text/x-diff; charset=utf-8")
+		fmt.Fprint(w, textdiff.Unified(fmt.Sprintf("revision %d", from), fmt.Sprintf("revision %d", to), hunks))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(diff)
+}
+
+// RevertSong возвращает песню к содержимому указанной ревизии.
+// @Summary Откат песни к ревизии
+// @Description Восстанавливает группу, название, дату релиза, текст, ссылку и альбом из указанной ревизии.
+// @Description Откат создает новую версию и новую ревизию песни, прежние ревизии не меняются.
+// @Produce json
+// @Param id path string true "ID песни"
+// @Param rev path int true "Номер ревизии"
+// @Param If-Match header string false "ETag текущей версии песни"
+// @Success 200 {object} models.Song "Песня после отката"
+// @Failure 400 {object} models.ErrorResponse "Ошибка в запросе"
+// @Failure 404 {object} models.ErrorResponse "Песня или ревизия не найдена"
+// @Failure 412 {object} models.ErrorResponse "ETag не совпадает с текущей версией"
+// @Failure 428 {object} models.ErrorResponse "Отсутствует заголовок If-Match"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /songs/{id}/revisions/{rev}/revert [post]
+func RevertSong(w http.ResponseWriter, r *http.Request) {
+	songID, rev, ok := parseRevisionPath(w, r)
+	if !ok {
+		return
+	}
+
+	existing, err := services.GetSong(requestDB(r), songID)
+	if errors.Is(err, services.ErrSongNotFound) {
+		writeAuditError(w, r, http.StatusNotFound, "Song not found", err)
+		return
+	}
+	if err != nil {
+		writeAuditError(w, r, http.StatusInternalServerError, "Failed to retrieve song", err)
+		return
+	}
+	if !checkIfMatch(w, r, existing) {
+		return
+	}
+
+	song, err := services.RevertSong(requestDB(r), existing, rev)
+	if errors.Is(err, services.ErrRevisionNotFound) {
+		writeAuditError(w, r, http.StatusNotFound, "Revision not found", err)
+		return
+	}
+	if errors.Is(err, services.ErrVersionConflict) {
+		slog.InfoContext(r.Context(), "Song was modified concurrently, revert rejected", "id", songID)
+		writePreconditionFailed(w)
+		return
+	}
+	if err != nil {
+		writeAuditError(w, r, http.StatusInternalServerError, "Failed to revert song", err)
+		return
+	}
+
+	slog.DebugContext(r.Context(), "Reverted song to revision", "id", songID, "revision", rev, "version", song.Version)
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", song.ETag)
+	json.NewEncoder(w).Encode(song)
+}
+
+// parseRevisionPath разбирает ID песни и номер ревизии из пути запроса.
+func parseRevisionPath(w http.ResponseWriter, r *http.Request) (uint, uint, bool) {
+	vars := mux.Vars(r)
+	songID, err := strconv.ParseUint(vars["id"], 10, 64)
+	if err != nil {
+		writeAuditError(w, r, http.StatusBadRequest, "Invalid song ID", err)
+		return 0, 0, false
+	}
+	rev, err := strconv.ParseUint(vars["rev"], 10, 64)
+	if err != nil {
+		writeAuditError(w, r, http.StatusBadRequest, "Invalid revision", err)
+		return 0, 0, false
+	}
+	return uint(songID), uint(rev), true
+}
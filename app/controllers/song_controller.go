@@ -2,15 +2,16 @@ package controllers
 
 import (
 	"encoding/json"
-	"log"
+	"errors"
+	"log/slog"
 	"net/http"
-	"net/url"
 	"strconv"
-	"strings"
 
 	"github.com/gorilla/mux"
-	"music-library/app/database"
+	"music-library/app/config"
 	"music-library/app/models"
+	"music-library/app/services"
+	"music-library/app/tracing"
 )
 
 // GetSongs возвращает список песен в зависимости от переданных параметров.
@@ -22,75 +23,210 @@ import (
 // @Param song query string false "Song name"
 // @Param limit query int false "Limit the number of songs returned"
 // @Param offset query int false "Offset the returned songs by this amount"
+// @Param fields query string false "Comma-separated list of fields to return, e.g. id,group,song"
 // @Success 200 {array} models.Song
 // @Failure 400 {object} models.ErrorResponse
 // @Failure 500 {object} models.ErrorResponse
 // @Router /songs [get]
 func GetSongs(w http.ResponseWriter, r *http.Request) {
-	log.Println("DEBUG: Received request to get songs")
+	slog.DebugContext(r.Context(), "Received request to get songs")
 	var songs []models.Song
 
-	group := r.URL.Query().Get("group")
-	name := r.URL.Query().Get("song")
+	limits := config.Get().Limits
+	filter, ok := parseSongFilter(w, r, limits.DefaultPageSize)
+	if !ok {
+		return
+	}
+	if limits.MaxPageSize > 0 && (filter.Limit <= 0 || filter.Limit > limits.MaxPageSize) {
+		filter.Limit = limits.MaxPageSize
+	}
+
+	fields, columns, err := parseFields(r.URL.Query().Get("fields"))
+	if err != nil {
+		slog.InfoContext(r.Context(), "Invalid fields value", "error", err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid fields: " + err.Error(),
+		})
+		return
+	}
+
+	query := requestDB(r).Model(&songs)
+	if columns != nil {
+		query = query.Select(columns)
+	}
+	query = services.ApplySongFilter(query, filter)
+
+	if err := query.Find(&songs).Error; err != nil {
+		slog.InfoContext(r.Context(), "Failed to retrieve songs", "error", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(models.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to retrieve songs",
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if notModified(w, r, listETag(songs, fields)) {
+		return
+	}
+
+	if fields == nil {
+		json.NewEncoder(w).Encode(songs)
+		return
+	}
+
+	projected := make([]interface{}, 0, len(songs))
+	for _, song := range songs {
+		item, err := projectSong(requestDB(r), song, fields, nil)
+		if err != nil {
+			slog.InfoContext(r.Context(), "Failed to project song fields", "error", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(models.ErrorResponse{
+				Code:    http.StatusInternalServerError,
+				Message: "Failed to retrieve songs",
+			})
+			return
+		}
+		projected = append(projected, item)
+	}
+	json.NewEncoder(w).Encode(projected)
+}
+
+// parseSongFilter разбирает фильтры и пагинацию списка песен из параметров запроса.
+// Возвращает false, если клиенту уже отправлен ответ с ошибкой.
+func parseSongFilter(w http.ResponseWriter, r *http.Request, defaultLimit int) (services.SongFilter, bool) {
+	filter := services.SongFilter{
+		Group: r.URL.Query().Get("group"),
+		Name:  r.URL.Query().Get("song"),
+		Limit: defaultLimit,
+	}
 	limitStr := r.URL.Query().Get("limit")
 	offsetStr := r.URL.Query().Get("offset")
-
-	limit := 10
-	offset := 0
 	var err error
 
 	if limitStr != "" {
-		limit, err = strconv.Atoi(limitStr)
+		filter.Limit, err = strconv.Atoi(limitStr)
 		if err != nil {
-			log.Println("INFO: Invalid limit value")
+			slog.InfoContext(r.Context(), "Invalid limit value")
 			w.WriteHeader(http.StatusBadRequest)
 			json.NewEncoder(w).Encode(models.ErrorResponse{
 				Code:    http.StatusBadRequest,
 				Message: "Invalid limit",
 			})
-			return
+			return filter, false
 		}
 	}
 
 	if offsetStr != "" {
-		offset, err = strconv.Atoi(offsetStr)
+		filter.Offset, err = strconv.Atoi(offsetStr)
 		if err != nil {
-			log.Println("INFO: Invalid offset value")
+			slog.InfoContext(r.Context(), "Invalid offset value")
 			w.WriteHeader(http.StatusBadRequest)
 			json.NewEncoder(w).Encode(models.ErrorResponse{
 				Code:    http.StatusBadRequest,
 				Message: "Invalid offset",
 			})
-			return
+			return filter, false
 		}
 	}
 
-	query := database.DB.Model(&songs)
-	if group != "" {
-		query = query.Where("artist = ?", group)
+	return filter, true
+}
+
+// GetSong возвращает песню по идентификатору.
+// @Summary Получение песни по ID
+// @Description Возвращает песню по ее идентификатору вместе с заголовком ETag. Поддерживает If-None-Match для условных запросов,
+// @Description выбор полей через fields= и встраивание связанных ресурсов (artist, album, enrichment) через include=.
+// @Description include=translations не поддерживается: переводы текстов в библиотеке не хранятся.
+// @Description С fields или include заголовок ETag слабый и описывает именно это представление, для If-Match нужен ETag полной песни (поле etag).
+// @Accept json
+// @Produce json
+// @Param id path string true "ID песни"
+// @Param fields query string false "Список полей через запятую, например id,group,song"
+// @Param include query string false "Связанные ресурсы через запятую: artist, album, enrichment"
+// @Param If-None-Match header string false "ETag ранее полученной версии песни"
+// @Success 200 {object} models.Song "Песня"
+// @Success 304 "Песня не изменилась"
+// @Failure 400 {object} models.ErrorResponse "Неверный запрос, ошибка в параметрах"
+// @Failure 404 {object} models.ErrorResponse "Песня не найдена"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /songs/{id} [get]
+func GetSong(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	slog.DebugContext(r.Context(), "Received request to get song", "id", id)
+
+	fields, columns, err := parseFields(r.URL.Query().Get("fields"))
+	if err != nil {
+		slog.InfoContext(r.Context(), "Invalid fields value", "error", err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid fields: " + err.Error(),
+		})
+		return
 	}
-	if name != "" {
-		query = query.Where("name = ?", name)
+
+	includes, err := parseIncludes(r.URL.Query().Get("include"))
+	if err != nil {
+		slog.InfoContext(r.Context(), "Invalid include value", "error", err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(models.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid include: " + err.Error(),
+		})
+		return
 	}
-	if limit > 0 {
-		query = query.Limit(limit)
+
+	var song models.Song
+
+	query := requestDB(r)
+	if columns != nil {
+		query = query.Select(columns)
 	}
-	if offset > 0 {
-		query = query.Offset(offset)
+	if err := query.First(&song, id).Error; err != nil {
+		slog.InfoContext(r.Context(), "Song not found", "id", id)
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(models.ErrorResponse{
+			Code:    http.StatusNotFound,
+			Message: "Song not found",
+		})
+		return
 	}
 
-	if err := query.Find(&songs).Error; err != nil {
-		log.Println("INFO: Failed to retrieve songs")
+	w.Header().Set("Content-Type", "application/json")
+	if len(fields) == 0 && len(includes) == 0 {
+		if notModified(w, r, song.ETag) {
+			return
+		}
+		json.NewEncoder(w).Encode(song)
+		return
+	}
+
+	var body []byte
+	response, err := projectSong(requestDB(r), song, fields, includes)
+	if err == nil {
+		body, err = json.Marshal(response)
+	}
+	if err != nil {
+		slog.InfoContext(r.Context(), "Failed to build song representation", "error", err)
 		w.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(w).Encode(models.ErrorResponse{
 			Code:    http.StatusInternalServerError,
-			Message: "Failed to retrieve songs",
+			Message: "Failed to retrieve song",
 		})
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(songs)
+	// Встроенные ресурсы меняются без изменения версии песни, а разные fields и include - разные представления,
+	// поэтому ETag вычисляется по самому ответу.
+	if notModified(w, r, representationETag(body)) {
+		return
+	}
+	w.Write(append(body, '\n'))
 }
 
 // GetSongTextWithPagination возвращает текст песни с пагинацией по куплетам.
@@ -107,12 +243,12 @@ func GetSongs(w http.ResponseWriter, r *http.Request) {
 func GetSongTextWithPagination(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
-	log.Println("DEBUG: Received request for song ID:", id)
+	slog.DebugContext(r.Context(), "Received request for song", "id", id)
 
 	var song models.Song
 
-	if err := database.DB.First(&song, id).Error; err != nil {
-		log.Println("INFO: Song not found with ID:", id)
+	if err := requestDB(r).First(&song, id).Error; err != nil {
+		slog.InfoContext(r.Context(), "Song not found", "id", id)
 		w.WriteHeader(http.StatusNotFound)
 		json.NewEncoder(w).Encode(models.ErrorResponse{
 			Code:    http.StatusNotFound,
@@ -121,8 +257,11 @@ func GetSongTextWithPagination(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	text := strings.ReplaceAll(song.Text, "\\n", "\n")
-	verses := strings.Split(text, "\n\n")
+	if notModified(w, r, song.ETag) {
+		return
+	}
+
+	verses := services.SplitVerses(song.Text)
 
 	pageStr := r.URL.Query().Get("page")
 	if pageStr == "" {
@@ -131,7 +270,7 @@ func GetSongTextWithPagination(w http.ResponseWriter, r *http.Request) {
 
 	page, err := strconv.Atoi(pageStr)
 	if err != nil || page < 1 {
-		log.Println("INFO: Invalid page value:", pageStr)
+		slog.InfoContext(r.Context(), "Invalid page value", "page", pageStr)
 		w.WriteHeader(http.StatusBadRequest)
 		json.NewEncoder(w).Encode(models.ErrorResponse{
 			Code:    http.StatusBadRequest,
@@ -140,12 +279,12 @@ func GetSongTextWithPagination(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	perPage := 1000
+	perPage := config.Get().Limits.TextPageSize
 	start := (page - 1) * perPage
 	end := start + perPage
 
 	if start >= len(verses) {
-		log.Println("INFO: Page exceeds total number of verses")
+		slog.InfoContext(r.Context(), "Page exceeds total number of verses")
 		w.WriteHeader(http.StatusBadRequest)
 		json.NewEncoder(w).Encode(models.ErrorResponse{
 			Code:    http.StatusBadRequest,
@@ -169,20 +308,24 @@ func GetSongTextWithPagination(w http.ResponseWriter, r *http.Request) {
 // @Accept json
 // @Produce json
 // @Param id path string true "ID песни"
+// @Param If-Match header string false "ETag текущей версии песни"
 // @Success 204 "Песня успешно удалена"
 // @Failure 400 {object} models.ErrorResponse "Ошибка в запросе"
+// @Failure 403 {object} models.ErrorResponse "Редактор удаляет чужую песню"
 // @Failure 404 {object} models.ErrorResponse "Песня не найдена"
+// @Failure 412 {object} models.ErrorResponse "ETag не совпадает с текущей версией"
+// @Failure 428 {object} models.ErrorResponse "Отсутствует заголовок If-Match"
 // @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
 // @Router /songs/{id} [delete]
 func DeleteSong(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
-	log.Println("DEBUG: Received request to delete song with ID:", id)
+	slog.DebugContext(r.Context(), "Received request to delete song", "id", id)
 
 	var song models.Song
 
-	if err := database.DB.First(&song, id).Error; err != nil {
-		log.Println("INFO: Song not found with ID:", id)
+	if err := requestDB(r).First(&song, id).Error; err != nil {
+		slog.InfoContext(r.Context(), "Song not found", "id", id)
 		w.WriteHeader(http.StatusNotFound)
 		json.NewEncoder(w).Encode(models.ErrorResponse{
 			Code:    http.StatusNotFound,
@@ -191,8 +334,26 @@ func DeleteSong(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := database.DB.Delete(&song).Error; err != nil {
-		log.Println("INFO: Failed to delete song with ID:", id)
+	if !checkIfMatch(w, r, song) {
+		return
+	}
+
+	if err := services.DeleteSong(requestDB(r), song); err != nil {
+		if errors.Is(err, services.ErrVersionConflict) {
+			slog.InfoContext(r.Context(), "Song was modified concurrently, delete rejected", "id", id)
+			writePreconditionFailed(w)
+			return
+		}
+		if errors.Is(err, services.ErrForbidden) {
+			slog.InfoContext(r.Context(), "Song belongs to another user, delete rejected", "id", id)
+			w.WriteHeader(http.StatusForbidden)
+			json.NewEncoder(w).Encode(models.ErrorResponse{
+				Code:    http.StatusForbidden,
+				Message: "Editors may only delete their own songs",
+			})
+			return
+		}
+		slog.InfoContext(r.Context(), "Failed to delete song", "id", id, "error", err)
 		w.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(w).Encode(models.ErrorResponse{
 			Code:    http.StatusInternalServerError,
@@ -201,7 +362,7 @@ func DeleteSong(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	log.Println("DEBUG: Successfully deleted song with ID:", id)
+	slog.DebugContext(r.Context(), "Successfully deleted song", "id", id)
 	w.WriteHeader(http.StatusNoContent)
 }
 
@@ -211,19 +372,23 @@ func DeleteSong(w http.ResponseWriter, r *http.Request) {
 // @Accept json
 // @Produce json
 // @Param id path string true "ID песни"
+// @Param If-Match header string false "ETag текущей версии песни"
 // @Param song body models.Song true "Данные песни для обновления"
 // @Success 204 "Песня успешно обновлена"
 // @Failure 400 {object} models.ErrorResponse "Ошибка в запросе"
 // @Failure 404 {object} models.ErrorResponse "Песня не найдена"
+// @Failure 412 {object} models.ErrorResponse "ETag не совпадает с текущей версией"
+// @Failure 428 {object} models.ErrorResponse "Отсутствует заголовок If-Match"
 // @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
 // @Router /songs/{id} [put]
+// @Router /songs/{id} [patch]
 func UpdateSong(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 	var song models.Song
 
 	if err := json.NewDecoder(r.Body).Decode(&song); err != nil {
-		log.Println("INFO: Failed to decode request body for update")
+		slog.InfoContext(r.Context(), "Failed to decode request body for update")
 		w.WriteHeader(http.StatusBadRequest)
 		json.NewEncoder(w).Encode(models.ErrorResponse{
 			Code:    http.StatusBadRequest,
@@ -233,8 +398,8 @@ func UpdateSong(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var existingSong models.Song
-	if err := database.DB.First(&existingSong, id).Error; err != nil {
-		log.Println("INFO: Song not found with ID:", id)
+	if err := requestDB(r).First(&existingSong, id).Error; err != nil {
+		slog.InfoContext(r.Context(), "Song not found", "id", id)
 		w.WriteHeader(http.StatusNotFound)
 		json.NewEncoder(w).Encode(models.ErrorResponse{
 			Code:    http.StatusNotFound,
@@ -243,8 +408,18 @@ func UpdateSong(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := database.DB.Model(&existingSong).Updates(song).Error; err != nil {
-		log.Println("INFO: Failed to update song with ID:", id)
+	if !checkIfMatch(w, r, existingSong) {
+		return
+	}
+
+	updatedSong, err := services.UpdateSong(requestDB(r), existingSong, song)
+	if err != nil {
+		if errors.Is(err, services.ErrVersionConflict) {
+			slog.InfoContext(r.Context(), "Song was modified concurrently, update rejected", "id", id)
+			writePreconditionFailed(w)
+			return
+		}
+		slog.InfoContext(r.Context(), "Failed to update song", "id", id, "error", err)
 		w.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(w).Encode(models.ErrorResponse{
 			Code:    http.StatusInternalServerError,
@@ -253,13 +428,15 @@ func UpdateSong(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	log.Println("DEBUG: Successfully updated song with ID:", id)
+	slog.DebugContext(r.Context(), "Successfully updated song", "id", id)
+	w.Header().Set("ETag", updatedSong.ETag)
 	w.WriteHeader(http.StatusNoContent)
 }
 
 // AddSong добавляет новую песню в базу данных.
 // @Summary Добавление новой песни
 // @Description Добавляет новую песню в базу данных, сначала запрашивая информацию из внешнего API.
+// @Description Наличие песни с той же группой и названием не проверяется, POST /songs:batch отмечает такие песни как дубликаты.
 // @Accept json
 // @Produce json
 // @Param song body models.Song true "Данные о песне"
@@ -271,7 +448,7 @@ func AddSong(w http.ResponseWriter, r *http.Request) {
 	var song models.Song
 
 	if err := json.NewDecoder(r.Body).Decode(&song); err != nil {
-		log.Println("INFO: Failed to decode request body for adding song")
+		slog.InfoContext(r.Context(), "Failed to decode request body for adding song")
 		w.WriteHeader(http.StatusBadRequest)
 		json.NewEncoder(w).Encode(models.ErrorResponse{
 			Code:    http.StatusBadRequest,
@@ -280,8 +457,8 @@ func AddSong(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if song.Group == "" || song.Name == "" {
-		log.Println("INFO: Group or song name is empty")
+	if err := services.ValidateSong(song); err != nil {
+		slog.InfoContext(r.Context(), "Group or song name is empty")
 		w.WriteHeader(http.StatusBadRequest)
 		json.NewEncoder(w).Encode(models.ErrorResponse{
 			Code:    http.StatusBadRequest,
@@ -290,23 +467,9 @@ func AddSong(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// мой тестовый Mock API запускается на порту 8081
-	apiURL := "http://localhost:8081/info?group=" + url.QueryEscape(song.Group) + "&song=" + url.QueryEscape(song.Name)
-	resp, err := http.Get(apiURL)
-
-	if err != nil {
-		log.Println("INFO: Error calling external API:", err)
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(models.ErrorResponse{
-			Code:    http.StatusInternalServerError,
-			Message: "Failed to call external API",
-		})
-		return
-	}
-	defer resp.Body.Close()
+	tracing.SetSongAttributes(r.Context(), song)
 
-	if resp.StatusCode != http.StatusOK {
-		log.Println("INFO: Non-200 response from external API:", resp.StatusCode)
+	if err := services.EnrichSong(r.Context(), &song); err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(w).Encode(models.ErrorResponse{
 			Code:    http.StatusInternalServerError,
@@ -315,24 +478,8 @@ func AddSong(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var externalSongDetail models.SongDetail
-	if err := json.NewDecoder(resp.Body).Decode(&externalSongDetail); err != nil {
-		log.Println("INFO: Failed to decode external API response:", err)
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(models.ErrorResponse{
-			Code:    http.StatusInternalServerError,
-			Message: "Failed to decode external API response",
-		})
-		return
-	}
-
-	song.ReleaseDate = externalSongDetail.ReleaseDate
-	song.Text = externalSongDetail.Text
-
-	song.Link = externalSongDetail.Link
-
-	if err := database.DB.Create(&song).Error; err != nil {
-		log.Println("INFO: Failed to save song to the database:", err)
+	if err := services.InsertSong(requestDB(r), &song); err != nil {
+		slog.InfoContext(r.Context(), "Failed to save song to the database", "error", err)
 		w.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(w).Encode(models.ErrorResponse{
 			Code:    http.StatusInternalServerError,
@@ -341,7 +488,8 @@ func AddSong(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	log.Println("DEBUG: Successfully added song")
+	tracing.SetSongAttributes(r.Context(), song)
+	slog.DebugContext(r.Context(), "Successfully added song")
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(song)
 }
@@ -0,0 +1,278 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"music-library/app/models"
+	"music-library/app/services"
+)
+
+// CreateWebhook создает подписку на события песен.
+// @Summary Создание подписки на события
+// @Description Создает подписку на события song.created, song.updated, song.deleted и song.enriched. События
+// @Description сохраняются в очередь в одной транзакции с изменением песни и отправляются POST-запросом с телом
+// @Description models.WebhookEvent. Неудачные попытки (не 2xx) повторяются с удваивающейся паузой.
+// @Description Заголовок X-Webhook-Signature содержит "sha256=" и HMAC-SHA256 секрета подписки от строки
+// @Description "<X-Webhook-Timestamp>.<тело запроса>". Секрет возвращается только в этом ответе.
+// @Accept json
+// @Produce json
+// @Param webhook body models.WebhookRequest true "Параметры подписки"
+// @Success 201 {object} models.CreatedWebhook "Созданная подписка"
+// @Failure 400 {object} models.ErrorResponse "Ошибка в запросе"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /webhooks [post]
+func CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	var request models.WebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeWebhookError(w, r, http.StatusBadRequest, "Invalid request payload", err)
+		return
+	}
+
+	created, err := services.CreateWebhook(requestDB(r), request)
+	if err != nil {
+		writeWebhookServiceError(w, r, "Failed to create webhook", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(created)
+}
+
+// GetWebhooks возвращает список подписок.
+// @Summary Список подписок на события
+// @Description Возвращает все подписки, включая неактивные. Секреты подписи не возвращаются.
+// @Produce json
+// @Success 200 {array} models.Webhook "Подписки"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /webhooks [get]
+func GetWebhooks(w http.ResponseWriter, r *http.Request) {
+	webhooks, err := services.ListWebhooks(requestDB(r))
+	if err != nil {
+		writeWebhookError(w, r, http.StatusInternalServerError, "Failed to retrieve webhooks", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(webhooks)
+}
+
+// GetWebhook возвращает подписку по ID.
+// @Summary Подписка на события
+// @Produce json
+// @Param id path int true "ID подписки"
+// @Success 200 {object} models.Webhook "Подписка"
+// @Failure 400 {object} models.ErrorResponse "Ошибка в запросе"
+// @Failure 404 {object} models.ErrorResponse "Подписка не найдена"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /webhooks/{id} [get]
+func GetWebhook(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseWebhookID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	webhook, err := services.GetWebhook(requestDB(r), id)
+	if err != nil {
+		writeWebhookServiceError(w, r, "Failed to retrieve webhook", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(webhook)
+}
+
+// UpdateWebhook изменяет подписку.
+// @Summary Изменение подписки на события
+// @Description Меняет адрес, события, активность, описание или секрет подписки. Пустые поля не меняются.
+// @Description События, произошедшие, пока подписка отключена, ей не доставляются, а уже созданные доставки ждут ее включения.
+// @Accept json
+// @Produce json
+// @Param id path int true "ID подписки"
+// @Param webhook body models.WebhookRequest true "Изменения подписки"
+// @Success 200 {object} models.Webhook "Подписка после изменения"
+// @Failure 400 {object} models.ErrorResponse "Ошибка в запросе"
+// @Failure 404 {object} models.ErrorResponse "Подписка не найдена"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /webhooks/{id} [patch]
+func UpdateWebhook(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseWebhookID(w, r, "id")
+	if !ok {
+		return
+	}
+	var request models.WebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeWebhookError(w, r, http.StatusBadRequest, "Invalid request payload", err)
+		return
+	}
+
+	webhook, err := services.UpdateWebhook(requestDB(r), id, request)
+	if err != nil {
+		writeWebhookServiceError(w, r, "Failed to update webhook", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(webhook)
+}
+
+// DeleteWebhook удаляет подписку.
+// @Summary Удаление подписки на события
+// @Description Удаляет подписку вместе с ее доставками и журналом попыток.
+// @Produce json
+// @Param id path int true "ID подписки"
+// @Success 204 "Подписка удалена"
+// @Failure 400 {object} models.ErrorResponse "Ошибка в запросе"
+// @Failure 404 {object} models.ErrorResponse "Подписка не найдена"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /webhooks/{id} [delete]
+func DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseWebhookID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	if err := services.DeleteWebhook(requestDB(r), id); err != nil {
+		writeWebhookServiceError(w, r, "Failed to delete webhook", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetWebhookDeliveries возвращает доставки подписки.
+// @Summary Доставки подписки
+// @Description Возвращает доставки событий подписке от новых к старым с их состоянием и итогом последней попытки.
+// @Produce json
+// @Param id path int true "ID подписки"
+// @Param status query string false "Статус: pending, delivered или failed"
+// @Param limit query int false "Максимальное количество доставок"
+// @Param offset query int false "Количество пропускаемых доставок"
+// @Success 200 {array} models.WebhookDelivery "Доставки"
+// @Failure 400 {object} models.ErrorResponse "Ошибка в запросе"
+// @Failure 404 {object} models.ErrorResponse "Подписка не найдена"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /webhooks/{id}/deliveries [get]
+func GetWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseWebhookID(w, r, "id")
+	if !ok {
+		return
+	}
+	page, ok := parseAuditFilter(w, r)
+	if !ok {
+		return
+	}
+
+	deliveries, err := services.ListDeliveries(requestDB(r), id, r.URL.Query().Get("status"), page.Limit, page.Offset)
+	if err != nil {
+		writeWebhookServiceError(w, r, "Failed to retrieve webhook deliveries", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(deliveries)
+}
+
+// GetWebhookDelivery возвращает доставку с журналом попыток.
+// @Summary Доставка события
+// @Description Возвращает доставку события с телом запроса и журналом всех попыток: время, код ответа, ошибка и длительность.
+// @Produce json
+// @Param id path int true "ID подписки"
+// @Param deliveryId path int true "ID доставки"
+// @Success 200 {object} models.WebhookDelivery "Доставка"
+// @Failure 400 {object} models.ErrorResponse "Ошибка в запросе"
+// @Failure 404 {object} models.ErrorResponse "Доставка не найдена"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /webhooks/{id}/deliveries/{deliveryId} [get]
+func GetWebhookDelivery(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseWebhookID(w, r, "id")
+	if !ok {
+		return
+	}
+	deliveryID, ok := parseWebhookID(w, r, "deliveryId")
+	if !ok {
+		return
+	}
+
+	delivery, err := services.GetDelivery(requestDB(r), id, deliveryID)
+	if err != nil {
+		writeWebhookServiceError(w, r, "Failed to retrieve webhook delivery", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(delivery)
+}
+
+// RedeliverWebhook повторно отправляет событие подписчику.
+// @Summary Повторная доставка события
+// @Description Ставит доставку в очередь заново с полным числом попыток, в том числе уже доставленную
+// @Description или исчерпавшую попытки. Журнал прежних попыток сохраняется.
+// @Produce json
+// @Param id path int true "ID подписки"
+// @Param deliveryId path int true "ID доставки"
+// @Success 202 {object} models.WebhookDelivery "Доставка поставлена в очередь"
+// @Failure 400 {object} models.ErrorResponse "Ошибка в запросе"
+// @Failure 404 {object} models.ErrorResponse "Доставка не найдена"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /webhooks/{id}/deliveries/{deliveryId}/redeliver [post]
+func RedeliverWebhook(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseWebhookID(w, r, "id")
+	if !ok {
+		return
+	}
+	deliveryID, ok := parseWebhookID(w, r, "deliveryId")
+	if !ok {
+		return
+	}
+
+	delivery, err := services.Redeliver(requestDB(r), id, deliveryID)
+	if err != nil {
+		writeWebhookServiceError(w, r, "Failed to redeliver webhook", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(delivery)
+}
+
+// parseWebhookID разбирает числовой ID из переменной пути name.
+func parseWebhookID(w http.ResponseWriter, r *http.Request, name string) (uint, bool) {
+	id, err := strconv.ParseUint(mux.Vars(r)[name], 10, 64)
+	if err != nil {
+		writeWebhookError(w, r, http.StatusBadRequest, "Invalid "+name, err)
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// writeWebhookServiceError отвечает ошибкой сервиса подписок с подходящим кодом ответа.
+func writeWebhookServiceError(w http.ResponseWriter, r *http.Request, message string, err error) {
+	switch {
+	case errors.Is(err, services.ErrWebhookNotFound):
+		writeWebhookError(w, r, http.StatusNotFound, "Webhook not found", err)
+	case errors.Is(err, services.ErrDeliveryNotFound):
+		writeWebhookError(w, r, http.StatusNotFound, "Webhook delivery not found", err)
+	case errors.Is(err, services.ErrInvalidWebhook):
+		writeWebhookError(w, r, http.StatusBadRequest, "URL must be an absolute http or https URL and events must be song.created, song.updated, song.deleted or song.enriched", err)
+	case errors.Is(err, services.ErrInvalidDeliveryStatus):
+		writeWebhookError(w, r, http.StatusBadRequest, "Status must be pending, delivered or failed", err)
+	default:
+		writeWebhookError(w, r, http.StatusInternalServerError, message, err)
+	}
+}
+
+// writeWebhookError отвечает ошибкой запроса к подпискам.
+func writeWebhookError(w http.ResponseWriter, r *http.Request, status int, message string, err error) {
+	slog.InfoContext(r.Context(), message, "error", err)
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(models.ErrorResponse{
+		Code:    status,
+		Message: message,
+	})
+}
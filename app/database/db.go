@@ -1,30 +1,97 @@
 package database
 
 import (
+	"log/slog"
+	"os"
+
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
-	"log"
 	"music-library/app/config"
-	"music-library/app/models"
+	"music-library/app/logging"
+	"music-library/app/metrics"
+	"music-library/app/tracing"
 )
 
 var DB *gorm.DB
 
 func ConnectDatabase() {
-	log.Println("DEBUG: Attempting to connect to the database...")
+	slog.Debug("Attempting to connect to the database")
+	settings := config.Get().Database
+	if settings.URL == "" {
+		fatal("Database URL is not set, use DATABASE_URL, database.url in the config file or -database-url")
+	}
+
 	var err error
-	dsn := config.GetDatabaseURL()
-	DB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	DB, err = gorm.Open(postgres.Open(settings.URL), &gorm.Config{
+		Logger: logging.NewGormLogger(settings.SlowQueryThreshold),
+	})
+	if err != nil {
+		fatal("Failed to connect to database", "error", err)
+	}
+
+	sqlDB, err := DB.DB()
+	if err != nil {
+		fatal("Failed to configure database pool", "error", err)
+	}
+	sqlDB.SetMaxOpenConns(settings.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(settings.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(settings.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(settings.ConnMaxIdleTime)
+	if err := metrics.InstrumentDatabase(DB); err != nil {
+		slog.Warn("Failed to instrument database metrics", "error", err)
+	}
+	if err := metrics.RegisterCatalog(DB); err != nil {
+		slog.Warn("Failed to register catalog metrics", "error", err)
+	}
+	if err := tracing.InstrumentDatabase(DB); err != nil {
+		slog.Warn("Failed to instrument database tracing", "error", err)
+	}
+	slog.Info("Successfully connected to the database")
+}
+
+// EnsureSchema проверяет, что схема базы данных не отстает от миграций приложения.
+// При MIGRATE_ON_START недостающие миграции применяются, иначе при отставании схемы
+// работа завершается, если не отключен REQUIRE_CURRENT_SCHEMA.
+func EnsureSchema() {
+	if config.Get().Database.MigrateOnStart {
+		slog.Debug("Running database migrations")
+		if err := MigrateUp(DB); err != nil {
+			fatal("Failed to migrate database", "error", err)
+		}
+		slog.Info("Database migration completed successfully")
+	}
+
+	current, err := CurrentVersion(DB)
 	if err != nil {
-		log.Fatal("ERROR: Failed to connect to database:", err)
+		fatal("Failed to read database schema version", "error", err)
 	}
-	log.Println("INFO: Successfully connected to the database.")
+	expected := SchemaVersion()
+	switch {
+	case current < expected && config.Get().Database.RequireCurrentSchema:
+		fatal("Database schema is behind, run `music-library migrate up`", "version", current, "expected", expected)
+	case current < expected:
+		slog.Warn("Database schema is behind", "version", current, "expected", expected)
+	case current > expected:
+		slog.Warn("Database schema is newer than known to this build", "version", current, "expected", expected)
+	default:
+		slog.Info("Database schema is up to date", "version", current)
+	}
+}
 
-	// Автомиграция
-	log.Println("DEBUG: Running database migrations...")
-	err = DB.AutoMigrate(&models.Song{})
+// Close закрывает пул соединений с базой данных.
+func Close() error {
+	if DB == nil {
+		return nil
+	}
+	sqlDB, err := DB.DB()
 	if err != nil {
-		log.Fatal("ERROR: Failed to migrate database:", err)
+		return err
 	}
-	log.Println("INFO: Database migration completed successfully.")
+	return sqlDB.Close()
+}
+
+// fatal пишет ошибку в журнал и завершает процесс, как log.Fatal.
+func fatal(message string, args ...interface{}) {
+	slog.Error(message, args...)
+	os.Exit(1)
 }
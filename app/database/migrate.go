@@ -0,0 +1,257 @@
+package database
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// migrationLockKey - ключ advisory-блокировки PostgreSQL, под которой выполняются миграции,
+// чтобы несколько экземпляров сервиса не применяли их одновременно.
+const migrationLockKey = 7_240_149_823
+
+// ErrUnknownMigration возвращается при переходе на версию, для которой нет миграции.
+var ErrUnknownMigration = errors.New("unknown migration version")
+
+// Migration - пронумерованная миграция схемы с SQL для применения и отката.
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationStatus описывает состояние миграции в базе данных.
+type MigrationStatus struct {
+	Version   int
+	Name      string
+	AppliedAt *time.Time // nil, если миграция не применена
+}
+
+// schemaMigration - запись о примененной миграции в таблице schema_migrations.
+type schemaMigration struct {
+	Version   int       `gorm:"primaryKey;autoIncrement:false"`
+	Name      string    `gorm:"not null"`
+	AppliedAt time.Time `gorm:"not null"`
+}
+
+func (schemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+var migrations = mustLoadMigrations()
+
+// mustLoadMigrations разбирает встроенные файлы вида 0001_name.up.sql и 0001_name.down.sql.
+func mustLoadMigrations() []Migration {
+	byVersion := make(map[int]*Migration)
+	err := fs.WalkDir(migrationFiles, "migrations", func(file string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		base := strings.TrimSuffix(path.Base(file), ".sql")
+		base, direction := strings.TrimSuffix(base, path.Ext(base)), strings.TrimPrefix(path.Ext(base), ".")
+		number, name, ok := strings.Cut(base, "_")
+		version, convErr := strconv.Atoi(number)
+		if !ok || convErr != nil || version <= 0 || (direction != "up" && direction != "down") {
+			return fmt.Errorf("invalid migration file name %q", file)
+		}
+
+		content, err := migrationFiles.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		migration, exists := byVersion[version]
+		if !exists {
+			migration = &Migration{Version: version, Name: name}
+			byVersion[version] = migration
+		} else if migration.Name != name {
+			return fmt.Errorf("migration %d has different names: %s and %s", version, migration.Name, name)
+		}
+		if direction == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+		return nil
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	list := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			panic(fmt.Sprintf("migration %d must have both up and down files", migration.Version))
+		}
+		list = append(list, *migration)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Version < list[j].Version })
+	return list
+}
+
+// Migrations возвращает все встроенные миграции по возрастанию версии.
+func Migrations() []Migration {
+	return migrations
+}
+
+// SchemaVersion возвращает версию схемы, которую ожидает приложение, - номер последней миграции.
+func SchemaVersion() int {
+	if len(migrations) == 0 {
+		return 0
+	}
+	return migrations[len(migrations)-1].Version
+}
+
+// CurrentVersion возвращает номер последней примененной миграции.
+// Если таблицы schema_migrations еще нет, возвращается 0.
+func CurrentVersion(db *gorm.DB) (int, error) {
+	if !db.Migrator().HasTable(&schemaMigration{}) {
+		return 0, nil
+	}
+	var version int
+	err := db.Model(&schemaMigration{}).Select("COALESCE(MAX(version), 0)").Scan(&version).Error
+	return version, err
+}
+
+// MigrationStatuses возвращает состояние всех встроенных миграций.
+func MigrationStatuses(db *gorm.DB) ([]MigrationStatus, error) {
+	var applied []schemaMigration
+	if db.Migrator().HasTable(&schemaMigration{}) {
+		if err := db.Find(&applied).Error; err != nil {
+			return nil, err
+		}
+	}
+	appliedAt := make(map[int]time.Time, len(applied))
+	for _, record := range applied {
+		appliedAt[record.Version] = record.AppliedAt
+	}
+
+	statuses := make([]MigrationStatus, len(migrations))
+	for i, migration := range migrations {
+		statuses[i] = MigrationStatus{Version: migration.Version, Name: migration.Name}
+		if at, ok := appliedAt[migration.Version]; ok {
+			statuses[i].AppliedAt = &at
+		}
+	}
+	return statuses, nil
+}
+
+// MigrateUp применяет все непримененные миграции.
+func MigrateUp(db *gorm.DB) error {
+	return MigrateTo(db, SchemaVersion())
+}
+
+// MigrateDown откатывает последние steps примененных миграций.
+func MigrateDown(db *gorm.DB, steps int) error {
+	return withMigrationLock(db, func(conn *gorm.DB) error {
+		current, err := CurrentVersion(conn)
+		if err != nil {
+			return err
+		}
+		target := 0
+		for i := len(migrations) - 1; i >= 0; i-- {
+			if migrations[i].Version > current {
+				continue
+			}
+			if steps == 0 {
+				target = migrations[i].Version
+				break
+			}
+			steps--
+		}
+		return migrate(conn, current, target)
+	})
+}
+
+// MigrateTo применяет или откатывает миграции так, чтобы схема оказалась в версии target.
+func MigrateTo(db *gorm.DB, target int) error {
+	if target != 0 && findMigration(target) == nil {
+		return fmt.Errorf("%w: %d", ErrUnknownMigration, target)
+	}
+	return withMigrationLock(db, func(conn *gorm.DB) error {
+		current, err := CurrentVersion(conn)
+		if err != nil {
+			return err
+		}
+		return migrate(conn, current, target)
+	})
+}
+
+// migrate выполняет миграции между версиями current и target, каждую в своей транзакции.
+func migrate(db *gorm.DB, current, target int) error {
+	if target >= current {
+		for _, migration := range migrations {
+			if migration.Version <= current || migration.Version > target {
+				continue
+			}
+			slog.Info("Applying migration", "version", migration.Version, "name", migration.Name)
+			err := db.Transaction(func(tx *gorm.DB) error {
+				if err := tx.Exec(migration.Up).Error; err != nil {
+					return err
+				}
+				return tx.Create(&schemaMigration{Version: migration.Version, Name: migration.Name, AppliedAt: time.Now()}).Error
+			})
+			if err != nil {
+				return fmt.Errorf("migration %04d_%s failed: %w", migration.Version, migration.Name, err)
+			}
+		}
+		return nil
+	}
+
+	for i := len(migrations) - 1; i >= 0; i-- {
+		migration := migrations[i]
+		if migration.Version > current || migration.Version <= target {
+			continue
+		}
+		slog.Info("Reverting migration", "version", migration.Version, "name", migration.Name)
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec(migration.Down).Error; err != nil {
+				return err
+			}
+			return tx.Delete(&schemaMigration{Version: migration.Version}).Error
+		})
+		if err != nil {
+			return fmt.Errorf("reverting migration %04d_%s failed: %w", migration.Version, migration.Name, err)
+		}
+	}
+	return nil
+}
+
+// withMigrationLock выполняет fn на одном соединении, удерживая advisory-блокировку PostgreSQL.
+func withMigrationLock(db *gorm.DB, fn func(conn *gorm.DB) error) error {
+	return db.Connection(func(conn *gorm.DB) error {
+		conn = conn.Session(&gorm.Session{NewDB: true})
+		if conn.Dialector.Name() == "postgres" {
+			slog.Debug("Waiting for migration lock")
+			if err := conn.Exec("SELECT pg_advisory_lock(?)", migrationLockKey).Error; err != nil {
+				return err
+			}
+			defer conn.Exec("SELECT pg_advisory_unlock(?)", migrationLockKey)
+		}
+		if err := conn.AutoMigrate(&schemaMigration{}); err != nil {
+			return err
+		}
+		return fn(conn)
+	})
+}
+
+func findMigration(version int) *Migration {
+	for i := range migrations {
+		if migrations[i].Version == version {
+			return &migrations[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_songs_deleted_at;
+DROP TABLE IF EXISTS songs;
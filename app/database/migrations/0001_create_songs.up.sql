@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS songs (
+    id           bigserial PRIMARY KEY,
+    created_at   timestamptz,
+    updated_at   timestamptz,
+    deleted_at   timestamptz,
+    artist       text NOT NULL,
+    name         text NOT NULL,
+    release_date text,
+    text         text,
+    link         text
+);
+CREATE INDEX IF NOT EXISTS idx_songs_deleted_at ON songs (deleted_at);
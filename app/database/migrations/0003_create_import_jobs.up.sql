@@ -0,0 +1,34 @@
+CREATE TABLE IF NOT EXISTS import_jobs (
+    id             bigserial PRIMARY KEY,
+    created_at     timestamptz,
+    updated_at     timestamptz,
+    deleted_at     timestamptz,
+    status         text NOT NULL DEFAULT 'pending',
+    file_name      text,
+    format         text NOT NULL,
+    mapping        text,
+    delimiter      text,
+    dry_run        boolean,
+    enrich         boolean,
+    on_duplicate   text NOT NULL DEFAULT 'skip',
+    source         bytea,
+    total_rows     bigint,
+    processed_rows bigint,
+    created_rows   bigint,
+    updated_rows   bigint,
+    skipped_rows   bigint,
+    failed_rows    bigint,
+    last_error     text,
+    started_at     timestamptz,
+    finished_at    timestamptz
+);
+
+CREATE TABLE IF NOT EXISTS import_errors (
+    id            bigserial PRIMARY KEY,
+    job_id        bigint NOT NULL,
+    record_number bigint,
+    message       text,
+    raw           text
+);
+
+CREATE INDEX IF NOT EXISTS idx_import_errors_job_id ON import_errors (job_id);
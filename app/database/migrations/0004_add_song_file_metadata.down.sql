@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS idx_songs_content_hash;
+DROP INDEX IF EXISTS idx_songs_file_path;
+
+ALTER TABLE songs
+    DROP COLUMN IF EXISTS bitrate,
+    DROP COLUMN IF EXISTS duration,
+    DROP COLUMN IF EXISTS content_hash,
+    DROP COLUMN IF EXISTS file_mod_time,
+    DROP COLUMN IF EXISTS file_size,
+    DROP COLUMN IF EXISTS file_path,
+    DROP COLUMN IF EXISTS album;
@@ -0,0 +1,11 @@
+ALTER TABLE songs
+    ADD COLUMN IF NOT EXISTS album         text,
+    ADD COLUMN IF NOT EXISTS file_path     text,
+    ADD COLUMN IF NOT EXISTS file_size     bigint,
+    ADD COLUMN IF NOT EXISTS file_mod_time timestamptz,
+    ADD COLUMN IF NOT EXISTS content_hash  text,
+    ADD COLUMN IF NOT EXISTS duration      bigint,
+    ADD COLUMN IF NOT EXISTS bitrate       bigint;
+
+CREATE INDEX IF NOT EXISTS idx_songs_file_path ON songs (file_path);
+CREATE INDEX IF NOT EXISTS idx_songs_content_hash ON songs (content_hash);
@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS users (
+    id            bigserial PRIMARY KEY,
+    created_at    timestamptz,
+    updated_at    timestamptz,
+    username      text NOT NULL,
+    password_hash text NOT NULL,
+    role          text NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_username ON users (username);
+
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    id         bigserial PRIMARY KEY,
+    created_at timestamptz,
+    user_id    bigint NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    token_hash text NOT NULL,
+    expires_at timestamptz NOT NULL,
+    revoked_at timestamptz
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_refresh_tokens_token_hash ON refresh_tokens (token_hash);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_user_id ON refresh_tokens (user_id);
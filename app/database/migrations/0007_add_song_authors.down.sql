@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_songs_created_by;
+
+ALTER TABLE songs
+    DROP COLUMN IF EXISTS updated_by,
+    DROP COLUMN IF EXISTS created_by;
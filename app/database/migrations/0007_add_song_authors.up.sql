@@ -0,0 +1,5 @@
+ALTER TABLE songs
+    ADD COLUMN IF NOT EXISTS created_by bigint,
+    ADD COLUMN IF NOT EXISTS updated_by bigint;
+
+CREATE INDEX IF NOT EXISTS idx_songs_created_by ON songs (created_by);
@@ -0,0 +1 @@
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS rate_limits text;
@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS audit_log (
+    id         bigserial PRIMARY KEY,
+    created_at timestamptz NOT NULL,
+    actor      text NOT NULL,
+    actor_name text,
+    request_id text,
+    operation  text NOT NULL,
+    song_id    bigint NOT NULL,
+    changes    jsonb NOT NULL DEFAULT '{}'
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_song_id ON audit_log (song_id, id);
+CREATE INDEX IF NOT EXISTS idx_audit_log_actor ON audit_log (actor, id);
+CREATE INDEX IF NOT EXISTS idx_audit_log_created_at ON audit_log (created_at);
+
+-- Журнал только дополняется: изменение и удаление записей запрещены.
+CREATE OR REPLACE FUNCTION audit_log_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS audit_log_append_only ON audit_log;
+CREATE TRIGGER audit_log_append_only
+    BEFORE UPDATE OR DELETE ON audit_log
+    FOR EACH ROW EXECUTE FUNCTION audit_log_append_only();
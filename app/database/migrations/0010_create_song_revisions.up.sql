@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS song_revisions (
+    id           bigserial PRIMARY KEY,
+    song_id      bigint NOT NULL,
+    revision     bigint NOT NULL,
+    created_at   timestamptz NOT NULL,
+    actor        text NOT NULL,
+    actor_name   text,
+    artist       text,
+    name         text,
+    release_date text,
+    text         text,
+    link         text,
+    album        text
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_song_revisions_song_revision ON song_revisions (song_id, revision);
+
+-- Текущее содержимое существующих песен становится их первой сохраненной ревизией.
+INSERT INTO song_revisions (song_id, revision, created_at, actor, artist, name, release_date, text, link, album)
+SELECT id, version, COALESCE(updated_at, now()), 'system', artist, name, release_date, text, link, album
+FROM songs
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,42 @@
+CREATE TABLE IF NOT EXISTS webhooks (
+    id          bigserial PRIMARY KEY,
+    created_at  timestamptz,
+    updated_at  timestamptz,
+    url         text NOT NULL,
+    secret      text NOT NULL,
+    events      text NOT NULL,
+    active      boolean NOT NULL DEFAULT true,
+    description text
+);
+
+-- Исходящая очередь: доставки создаются в транзакции изменения песни и живут до успешной отправки или отказа.
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id              bigserial PRIMARY KEY,
+    created_at      timestamptz NOT NULL,
+    webhook_id      bigint NOT NULL REFERENCES webhooks (id) ON DELETE CASCADE,
+    event_id        text NOT NULL,
+    event           text NOT NULL,
+    song_id         bigint,
+    payload         jsonb NOT NULL,
+    status          text NOT NULL,
+    attempts        integer NOT NULL DEFAULT 0,
+    next_attempt_at timestamptz,
+    last_attempt_at timestamptz,
+    response_status integer,
+    last_error      text,
+    delivered_at    timestamptz
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_webhook_id ON webhook_deliveries (webhook_id, id);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
+
+CREATE TABLE IF NOT EXISTS webhook_attempts (
+    id              bigserial PRIMARY KEY,
+    created_at      timestamptz NOT NULL,
+    delivery_id     bigint NOT NULL REFERENCES webhook_deliveries (id) ON DELETE CASCADE,
+    response_status integer,
+    error           text,
+    duration_ms     bigint NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_attempts_delivery_id ON webhook_attempts (delivery_id, id);
@@ -0,0 +1,10 @@
+-- Журнал событий песен: источник потока /events и доставок подписчикам.
+CREATE TABLE IF NOT EXISTS song_events (
+    id         bigserial PRIMARY KEY,
+    created_at timestamptz NOT NULL,
+    event      text NOT NULL,
+    song_id    bigint NOT NULL,
+    artist     text NOT NULL,
+    song       jsonb NOT NULL,
+    changes    jsonb
+);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_songs_link;
+DROP INDEX IF EXISTS idx_songs_name_lower;
+DROP INDEX IF EXISTS idx_songs_artist_lower;
@@ -0,0 +1,4 @@
+-- Индексы для сопоставления записей плейлиста с каталогом без учета регистра и по ссылке.
+CREATE INDEX IF NOT EXISTS idx_songs_artist_lower ON songs (LOWER(artist));
+CREATE INDEX IF NOT EXISTS idx_songs_name_lower ON songs (LOWER(name));
+CREATE INDEX IF NOT EXISTS idx_songs_link ON songs (link);
@@ -0,0 +1,161 @@
+package exporter
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"music-library/app/models"
+)
+
+// Поддерживаемые форматы экспорта.
+const (
+	FormatCSV    = "csv"
+	FormatJSON   = "json"
+	FormatNDJSON = "ndjson"
+	FormatXLSX   = "xlsx"
+)
+
+// columns перечисляет колонки табличных форматов. Названия совпадают с полями,
+// которые распознает импорт, поэтому выгрузку можно загрузить обратно.
+var columns = []string{"id", "group", "song", "releaseDate", "text", "link", "version", "createdAt", "updatedAt"}
+
+// Writer записывает песни в выбранном формате по одной, не накапливая их в памяти.
+type Writer interface {
+	// WriteSong записывает очередную песню.
+	WriteSong(song models.Song) error
+	// Close дописывает окончание документа. Закрывать исходный io.Writer не требуется.
+	Close() error
+}
+
+// ValidFormat проверяет, поддерживается ли формат экспорта.
+func ValidFormat(format string) bool {
+	switch format {
+	case FormatCSV, FormatJSON, FormatNDJSON, FormatXLSX:
+		return true
+	}
+	return false
+}
+
+// ContentType возвращает MIME-тип файла экспорта.
+func ContentType(format string) string {
+	switch format {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatJSON:
+		return "application/json"
+	case FormatNDJSON:
+		return "application/x-ndjson"
+	case FormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "application/octet-stream"
+}
+
+// FileName возвращает имя файла экспорта с отметкой времени.
+func FileName(format string, now time.Time) string {
+	return fmt.Sprintf("songs-%s.%s", now.UTC().Format("20060102-150405"), format)
+}
+
+// NewWriter создает Writer для указанного формата.
+func NewWriter(w io.Writer, format string) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w)
+	case FormatJSON:
+		return &jsonWriter{out: bufio.NewWriter(w)}, nil
+	case FormatNDJSON:
+		out := bufio.NewWriter(w)
+		return &ndjsonWriter{out: out, encoder: json.NewEncoder(out)}, nil
+	case FormatXLSX:
+		return newXLSXWriter(w)
+	}
+	return nil, fmt.Errorf("unsupported export format %q", format)
+}
+
+// songRow возвращает значения колонок песни для табличных форматов.
+func songRow(song models.Song) []string {
+	return []string{
+		strconv.FormatUint(uint64(song.ID), 10),
+		song.Group,
+		song.Name,
+		song.ReleaseDate,
+		song.Text,
+		song.Link,
+		strconv.FormatUint(uint64(song.Version), 10),
+		song.CreatedAt.UTC().Format(time.RFC3339),
+		song.UpdatedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+type csvWriter struct {
+	out *csv.Writer
+}
+
+func newCSVWriter(w io.Writer) (*csvWriter, error) {
+	writer := &csvWriter{out: csv.NewWriter(w)}
+	if err := writer.out.Write(columns); err != nil {
+		return nil, err
+	}
+	return writer, nil
+}
+
+func (c *csvWriter) WriteSong(song models.Song) error {
+	return c.out.Write(songRow(song))
+}
+
+func (c *csvWriter) Close() error {
+	c.out.Flush()
+	return c.out.Error()
+}
+
+type jsonWriter struct {
+	out   *bufio.Writer
+	count int
+}
+
+func (j *jsonWriter) WriteSong(song models.Song) error {
+	prefix := ",\n"
+	if j.count == 0 {
+		prefix = "[\n"
+	}
+	j.count++
+
+	data, err := json.Marshal(song)
+	if err != nil {
+		return err
+	}
+	if _, err := j.out.WriteString(prefix); err != nil {
+		return err
+	}
+	_, err = j.out.Write(data)
+	return err
+}
+
+func (j *jsonWriter) Close() error {
+	closing := "\n]\n"
+	if j.count == 0 {
+		closing = "[]\n"
+	}
+	if _, err := j.out.WriteString(closing); err != nil {
+		return err
+	}
+	return j.out.Flush()
+}
+
+type ndjsonWriter struct {
+	out     *bufio.Writer
+	encoder *json.Encoder
+}
+
+func (n *ndjsonWriter) WriteSong(song models.Song) error {
+	return n.encoder.Encode(song)
+}
+
+func (n *ndjsonWriter) Close() error {
+	return n.out.Flush()
+}
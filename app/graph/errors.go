@@ -0,0 +1,102 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"music-library/app/auth"
+	"music-library/app/config"
+	"music-library/app/models"
+	"music-library/app/ratelimit"
+	"music-library/app/services"
+)
+
+// Коды ошибок в поле extensions.code ответа. Они соответствуют кодам ответа REST API в тех же случаях.
+const (
+	codeBadUserInput         = "BAD_USER_INPUT"        // 400
+	codeUnauthenticated      = "UNAUTHENTICATED"       // 401
+	codeForbidden            = "FORBIDDEN"             // 403
+	codeNotFound             = "NOT_FOUND"             // 404
+	codeConflict             = "CONFLICT"              // 409
+	codePreconditionFailed   = "PRECONDITION_FAILED"   // 412
+	codePreconditionRequired = "PRECONDITION_REQUIRED" // 428
+	codeRateLimited          = "RATE_LIMITED"          // 429
+	codeInternal             = "INTERNAL_SERVER_ERROR" // 500
+)
+
+// newError возвращает ошибку поля с кодом code.
+func newError(ctx context.Context, code, message string) *gqlerror.Error {
+	return &gqlerror.Error{
+		Path:       graphql.GetPath(ctx),
+		Message:    message,
+		Extensions: map[string]interface{}{"code": code},
+	}
+}
+
+// serviceError преобразует ошибку сервисного слоя в ошибку GraphQL. Неизвестные ошибки записываются
+// в журнал, а клиенту возвращается только message.
+func serviceError(ctx context.Context, message string, err error) error {
+	switch {
+	case errors.Is(err, services.ErrSongNotFound):
+		return newError(ctx, codeNotFound, "Song not found")
+	case errors.Is(err, services.ErrInvalidSong):
+		return newError(ctx, codeBadUserInput, "Group and song name must not be empty")
+	case errors.Is(err, services.ErrDuplicateSong):
+		return newError(ctx, codeConflict, "Song already exists")
+	case errors.Is(err, services.ErrVersionConflict):
+		return newError(ctx, codePreconditionFailed, "Song was modified by another request")
+	case errors.Is(err, services.ErrForbidden):
+		return newError(ctx, codeForbidden, "Editors may only delete their own songs")
+	case errors.Is(err, services.ErrEnrichmentFailed):
+		return newError(ctx, codeInternal, "Failed to retrieve data from external API")
+	default:
+		slog.InfoContext(ctx, message, "error", err)
+		return newError(ctx, codeInternal, message)
+	}
+}
+
+// requireScope проверяет разрешение участника на мутацию. Маршрут /graphql требует только songs:read,
+// так как запросы и мутации приходят на один адрес, поэтому разрешения на изменения проверяют резолверы.
+func requireScope(ctx context.Context, scope string) error {
+	if !config.Get().Auth.Enabled {
+		return nil
+	}
+	principal := auth.PrincipalFrom(ctx)
+	if principal == nil || principal.Type == auth.PrincipalAnonymous {
+		return newError(ctx, codeUnauthenticated, "Authentication required")
+	}
+	if !principal.HasScope(scope) {
+		slog.InfoContext(ctx, "Insufficient scope", "principal", principal.Name, "scope", scope)
+		return newError(ctx, codeForbidden, "Scope "+scope+" is required")
+	}
+	return nil
+}
+
+// checkIfMatch проверяет ETag из аргумента ifMatch перед изменением песни, как заголовок If-Match в REST API.
+func checkIfMatch(ctx context.Context, ifMatch *string, song models.Song) error {
+	if ifMatch == nil || *ifMatch == "" {
+		if !config.Get().Features.RequireIfMatch {
+			return nil
+		}
+		return newError(ctx, codePreconditionRequired, "ifMatch is required")
+	}
+	if !services.MatchETag(*ifMatch, song.ETag) {
+		slog.InfoContext(ctx, "ifMatch does not match current ETag", "etag", song.ETag)
+		return newError(ctx, codePreconditionFailed, "Song was modified by another request")
+	}
+	return nil
+}
+
+// chargeEnrich расходует токен лимита enrich на мутацию, которая обращается к внешнему API.
+func chargeEnrich(ctx context.Context) error {
+	var limitErr *ratelimit.LimitError
+	if err := ratelimit.Charge(ctx, ratelimit.ClassEnrich); errors.As(err, &limitErr) {
+		gqlErr := newError(ctx, codeRateLimited, limitErr.Error())
+		gqlErr.Extensions["retryAfter"] = ratelimit.CeilSeconds(limitErr.RetryAfter)
+		return gqlErr
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package graph
+
+import (
+	"context"
+	"strings"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/errcode"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// errDepthLimit - код ошибки запроса, превышающего graphql.maxDepth.
+const errDepthLimit = "DEPTH_LIMIT_EXCEEDED"
+
+// depthLimit отклоняет запросы, вложенность полей которых больше max. Поля схемы (__schema, __type)
+// не учитываются: запрос схемы от инструментов разработчика глубже любого запроса данных.
+type depthLimit struct {
+	max int
+}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.OperationContextMutator
+} = depthLimit{}
+
+func (depthLimit) ExtensionName() string {
+	return "DepthLimit"
+}
+
+func (depthLimit) Validate(graphql.ExecutableSchema) error {
+	return nil
+}
+
+// MutateOperationContext проверяет глубину запроса после разбора, до выполнения резолверов.
+func (d depthLimit) MutateOperationContext(_ context.Context, oc *graphql.OperationContext) *gqlerror.Error {
+	if depth := selectionDepth(oc.Operation.SelectionSet); depth > d.max {
+		err := gqlerror.Errorf("operation has depth %d, which exceeds the limit of %d", depth, d.max)
+		errcode.Set(err, errDepthLimit)
+		return err
+	}
+	return nil
+}
+
+// selectionDepth возвращает наибольшую вложенность полей набора с учетом фрагментов.
+func selectionDepth(selections ast.SelectionSet) int {
+	depth := 0
+	for _, selection := range selections {
+		switch s := selection.(type) {
+		case *ast.Field:
+			if strings.HasPrefix(s.Name, "__") {
+				continue
+			}
+			depth = max(depth, 1+selectionDepth(s.SelectionSet))
+		case *ast.InlineFragment:
+			depth = max(depth, selectionDepth(s.SelectionSet))
+		case *ast.FragmentSpread:
+			if s.Definition != nil {
+				depth = max(depth, selectionDepth(s.Definition.SelectionSet))
+			}
+		}
+	}
+	return depth
+}
@@ -0,0 +1,168 @@
+package graph
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"music-library/app/models"
+)
+
+// loaderWait - сколько загрузчик ждет остальные ключи после первого. Резолверы полей элементов списка
+// выполняются параллельно, поэтому за это время их ключи собираются в один запрос к базе данных.
+const loaderWait = time.Millisecond
+
+// loaderMaxBatch - наибольшее число ключей в одном запросе загрузчика.
+const loaderMaxBatch = 500
+
+// loader собирает ключи, запрошенные резолверами одного запроса GraphQL, в пакеты и загружает
+// каждый пакет одним запросом. Результаты запоминаются до конца запроса GraphQL.
+type loader[K comparable, V any] struct {
+	fetch func(ctx context.Context, keys []K) (map[K]V, error)
+
+	mu    sync.Mutex
+	cache map[K]*loaderResult[V]
+	batch *loaderBatch[K, V]
+}
+
+// loaderResult - результат загрузки одного ключа. done закрывается, когда результат готов.
+type loaderResult[V any] struct {
+	done  chan struct{}
+	value V
+	found bool
+	err   error
+}
+
+// loaderBatch - ключи, загружаемые одним запросом.
+type loaderBatch[K comparable, V any] struct {
+	once    sync.Once
+	keys    []K
+	results []*loaderResult[V]
+}
+
+func newLoader[K comparable, V any](fetch func(ctx context.Context, keys []K) (map[K]V, error)) *loader[K, V] {
+	return &loader[K, V]{fetch: fetch, cache: make(map[K]*loaderResult[V])}
+}
+
+// load возвращает значение по ключу. found равен false, если значения нет.
+func (l *loader[K, V]) load(ctx context.Context, key K) (value V, found bool, err error) {
+	l.mu.Lock()
+	result, ok := l.cache[key]
+	if !ok {
+		result = &loaderResult[V]{done: make(chan struct{})}
+		l.cache[key] = result
+		if l.batch == nil {
+			batch := &loaderBatch[K, V]{}
+			l.batch = batch
+			time.AfterFunc(loaderWait, func() { l.dispatch(ctx, batch) })
+		}
+		batch := l.batch
+		batch.keys = append(batch.keys, key)
+		batch.results = append(batch.results, result)
+		if len(batch.keys) >= loaderMaxBatch {
+			l.batch = nil
+			go l.dispatch(ctx, batch)
+		}
+	}
+	l.mu.Unlock()
+
+	select {
+	case <-result.done:
+		return result.value, result.found, result.err
+	case <-ctx.Done():
+		return value, false, ctx.Err()
+	}
+}
+
+// dispatch загружает пакет и раздает результаты ожидающим резолверам. Пакет загружается один раз,
+// даже если он заполнился раньше, чем истекло ожидание.
+func (l *loader[K, V]) dispatch(ctx context.Context, batch *loaderBatch[K, V]) {
+	batch.once.Do(func() {
+		l.mu.Lock()
+		if l.batch == batch {
+			l.batch = nil
+		}
+		l.mu.Unlock()
+
+		values, err := l.fetch(ctx, batch.keys)
+		for i, key := range batch.keys {
+			result := batch.results[i]
+			result.value, result.found = values[key]
+			result.err = err
+			close(result.done)
+		}
+	})
+}
+
+// loaders - загрузчики связанных данных одного запроса GraphQL.
+type loaders struct {
+	artists *loader[string, int] // Количество песен исполнителя
+	users   *loader[uint, models.User]
+}
+
+type loadersKey struct{}
+
+// withLoaders создает для каждого запроса свои загрузчики, чтобы кеш не переживал запрос
+// и не отдавал данные, загруженные с правами другого участника.
+func withLoaders(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		l := &loaders{
+			artists: newLoader(fetchArtistSongCounts),
+			users:   newLoader(fetchUsers),
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), loadersKey{}, l)))
+	})
+}
+
+// loadersFrom возвращает загрузчики запроса.
+func loadersFrom(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
+
+// fetchArtistSongCounts считает песни исполнителей одним запросом.
+func fetchArtistSongCounts(ctx context.Context, names []string) (map[string]int, error) {
+	var rows []struct {
+		Artist string
+		Count  int
+	}
+	err := db(ctx).Model(&models.Song{}).Select("artist, COUNT(*) AS count").
+		Where("artist IN ?", names).Group("artist").Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int, len(rows))
+	for _, row := range rows {
+		counts[row.Artist] = row.Count
+	}
+	return counts, nil
+}
+
+// fetchUsers загружает пользователей по ID одним запросом.
+func fetchUsers(ctx context.Context, ids []uint) (map[uint]models.User, error) {
+	var users []models.User
+	if err := db(ctx).Where("id IN ?", ids).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]models.User, len(users))
+	for _, user := range users {
+		byID[user.ID] = user
+	}
+	return byID, nil
+}
+
+// loadUser загружает автора изменения песни через загрузчик запроса. Возвращает nil, если автор
+// не задан или пользователь удален.
+func loadUser(ctx context.Context, id *uint) (*models.User, error) {
+	if id == nil {
+		return nil, nil
+	}
+	user, found, err := loadersFrom(ctx).users.load(ctx, *id)
+	if err != nil {
+		return nil, serviceError(ctx, "Failed to retrieve user", err)
+	}
+	if !found {
+		return nil, nil
+	}
+	return &user, nil
+}
@@ -0,0 +1,18 @@
+package graph
+
+import (
+	"music-library/app/models"
+	"music-library/app/services"
+)
+
+//go:generate go run github.com/99designs/gqlgen --config gqlgen.yml generate
+
+// SongPage - страница списка песен. Общее количество песен считается отдельным запросом,
+// только если клиент запросил поле total.
+type SongPage struct {
+	Items  []models.Song `json:"items"`
+	Limit  int           `json:"limit"`
+	Offset int           `json:"offset"`
+
+	filter services.SongFilter
+}
@@ -0,0 +1,59 @@
+// Code generated by github.com/99designs/gqlgen, DO NOT EDIT.
+
+package graph
+
+// Исполнитель песни.
+type Artist struct {
+	Name      string `json:"name"`
+	SongCount int    `json:"songCount"`
+}
+
+// Статус обогащения песни данными внешнего API.
+type Enrichment struct {
+	// complete, partial или missing
+	Status string `json:"status"`
+	// Поля, не заполненные внешним API
+	Missing []string `json:"missing"`
+}
+
+type Mutation struct {
+}
+
+// Новая песня. Дата релиза, текст и ссылка запрашиваются во внешнем API.
+type NewSong struct {
+	Group string  `json:"group"`
+	Song  string  `json:"song"`
+	Album *string `json:"album,omitempty"`
+}
+
+type Query struct {
+}
+
+// Изменения песни. Незаданные и пустые поля не меняются.
+type SongChanges struct {
+	Group       *string `json:"group,omitempty"`
+	Song        *string `json:"song,omitempty"`
+	ReleaseDate *string `json:"releaseDate,omitempty"`
+	Text        *string `json:"text,omitempty"`
+	Link        *string `json:"link,omitempty"`
+	Album       *string `json:"album,omitempty"`
+}
+
+// Фильтр списка песен.
+type SongFilter struct {
+	// Точное название группы
+	Group *string `json:"group,omitempty"`
+	// Точное название песни
+	Song *string `json:"song,omitempty"`
+	// Подстрока группы, названия или альбома без учета регистра
+	Search *string `json:"search,omitempty"`
+}
+
+// Страница куплетов песни.
+type VersePage struct {
+	Items []string `json:"items"`
+	Page  int      `json:"page"`
+	Limit int      `json:"limit"`
+	// Всего куплетов
+	Total int `json:"total"`
+}
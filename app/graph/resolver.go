@@ -0,0 +1,77 @@
+package graph
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/99designs/gqlgen/graphql/handler/lru"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/vektah/gqlparser/v2/ast"
+	"gorm.io/gorm"
+	"music-library/app/config"
+	"music-library/app/database"
+)
+
+// queryCacheSize - сколько разобранных запросов хранится, чтобы повторяющиеся запросы клиентов
+// не разбирались и не проверялись заново.
+const queryCacheSize = 1000
+
+// Resolver - корень резолверов схемы. Резолверы работают с базой данных через сервисный слой,
+// как и обработчики REST API.
+type Resolver struct{}
+
+// NewHandler возвращает HTTP-обработчик GraphQL. Запросы принимаются методом POST, а запросы
+// без мутаций - также методом GET. Глубина и сложность запросов ограничиваются настройками graphql.
+func NewHandler() http.Handler {
+	settings := config.Get().GraphQL
+
+	cfg := Config{Resolvers: &Resolver{}}
+	cfg.Complexity.Query.Songs = func(childComplexity int, _ *SongFilter, limit *int, _ int) int {
+		return 1 + max(songPageSize(limit), 1)*childComplexity
+	}
+
+	srv := handler.New(NewExecutableSchema(cfg))
+	srv.AddTransport(transport.GET{})
+	srv.AddTransport(transport.POST{})
+	srv.SetQueryCache(lru.New[*ast.QueryDocument](queryCacheSize))
+	if settings.Introspection {
+		srv.Use(extension.Introspection{})
+	}
+	srv.Use(depthLimit{max: settings.MaxDepth})
+	srv.Use(extension.FixedComplexityLimit(settings.MaxComplexity))
+	srv.SetRecoverFunc(func(ctx context.Context, err interface{}) error {
+		slog.ErrorContext(ctx, "GraphQL resolver panicked", "panic", err, "path", graphql.GetPath(ctx).String())
+		return newError(ctx, codeInternal, "Internal server error")
+	})
+	return withLoaders(srv)
+}
+
+// db возвращает подключение к базе данных с контекстом запроса: по нему сервисы определяют автора изменений.
+func db(ctx context.Context) *gorm.DB {
+	return database.DB.WithContext(ctx)
+}
+
+// songPageSize возвращает размер страницы списка песен с учетом limits.defaultPageSize и limits.maxPageSize.
+func songPageSize(limit *int) int {
+	limits := config.Get().Limits
+	size := limits.DefaultPageSize
+	if limit != nil {
+		size = *limit
+	}
+	if limits.MaxPageSize > 0 && size > limits.MaxPageSize {
+		size = limits.MaxPageSize
+	}
+	return size
+}
+
+// stringValue возвращает строку по указателю или пустую строку для незаданного аргумента.
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
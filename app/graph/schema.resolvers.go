@@ -0,0 +1,202 @@
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.66
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"music-library/app/auth"
+	"music-library/app/config"
+	"music-library/app/models"
+	"music-library/app/services"
+	"music-library/app/tracing"
+)
+
+// AddSong is the resolver for the addSong field.
+func (r *mutationResolver) AddSong(ctx context.Context, input NewSong) (*models.Song, error) {
+	if err := requireScope(ctx, auth.ScopeSongsWrite); err != nil {
+		return nil, err
+	}
+	song := models.Song{Group: input.Group, Name: input.Song, Album: stringValue(input.Album)}
+	if err := services.ValidateSong(song); err != nil {
+		return nil, serviceError(ctx, "Invalid song", err)
+	}
+	if err := chargeEnrich(ctx); err != nil {
+		return nil, err
+	}
+
+	tracing.SetSongAttributes(ctx, song)
+	if err := services.EnrichSong(ctx, &song); err != nil {
+		return nil, serviceError(ctx, "Failed to retrieve data from external API", err)
+	}
+	if err := services.InsertSong(db(ctx), &song); err != nil {
+		return nil, serviceError(ctx, "Failed to save song to the database", err)
+	}
+
+	tracing.SetSongAttributes(ctx, song)
+	slog.DebugContext(ctx, "Successfully added song", "id", song.ID)
+	return &song, nil
+}
+
+// UpdateSong is the resolver for the updateSong field.
+func (r *mutationResolver) UpdateSong(ctx context.Context, id uint, input SongChanges, ifMatch *string) (*models.Song, error) {
+	if err := requireScope(ctx, auth.ScopeSongsWrite); err != nil {
+		return nil, err
+	}
+	existing, err := services.GetSong(db(ctx), id)
+	if err != nil {
+		return nil, serviceError(ctx, "Failed to retrieve song", err)
+	}
+	if err := checkIfMatch(ctx, ifMatch, existing); err != nil {
+		return nil, err
+	}
+
+	changes := models.Song{
+		Group:       stringValue(input.Group),
+		Name:        stringValue(input.Song),
+		ReleaseDate: stringValue(input.ReleaseDate),
+		Text:        stringValue(input.Text),
+		Link:        stringValue(input.Link),
+		Album:       stringValue(input.Album),
+	}
+	updated, err := services.UpdateSong(db(ctx), existing, changes)
+	if err != nil {
+		return nil, serviceError(ctx, "Failed to update song", err)
+	}
+
+	slog.DebugContext(ctx, "Successfully updated song", "id", id)
+	return &updated, nil
+}
+
+// DeleteSong is the resolver for the deleteSong field.
+func (r *mutationResolver) DeleteSong(ctx context.Context, id uint, ifMatch *string) (bool, error) {
+	if err := requireScope(ctx, auth.ScopeSongsDelete); err != nil {
+		return false, err
+	}
+	existing, err := services.GetSong(db(ctx), id)
+	if err != nil {
+		return false, serviceError(ctx, "Failed to retrieve song", err)
+	}
+	if err := checkIfMatch(ctx, ifMatch, existing); err != nil {
+		return false, err
+	}
+
+	if err := services.DeleteSong(db(ctx), existing); err != nil {
+		return false, serviceError(ctx, "Failed to delete song", err)
+	}
+
+	slog.DebugContext(ctx, "Successfully deleted song", "id", id)
+	return true, nil
+}
+
+// Songs is the resolver for the songs field.
+func (r *queryResolver) Songs(ctx context.Context, filter *SongFilter, limit *int, offset int) (*SongPage, error) {
+	size := songPageSize(limit)
+	if size < 1 {
+		return nil, newError(ctx, codeBadUserInput, "Limit must be positive")
+	}
+	if offset < 0 {
+		return nil, newError(ctx, codeBadUserInput, "Offset must not be negative")
+	}
+
+	page := &SongPage{Limit: size, Offset: offset, filter: services.SongFilter{Limit: size, Offset: offset}}
+	if filter != nil {
+		page.filter.Group = stringValue(filter.Group)
+		page.filter.Name = stringValue(filter.Song)
+		page.filter.Search = stringValue(filter.Search)
+	}
+	query := services.ApplySongFilter(db(ctx).Model(&models.Song{}), page.filter).Order("id")
+	if err := query.Find(&page.Items).Error; err != nil {
+		return nil, serviceError(ctx, "Failed to retrieve songs", err)
+	}
+	return page, nil
+}
+
+// Song is the resolver for the song field.
+func (r *queryResolver) Song(ctx context.Context, id uint) (*models.Song, error) {
+	song, err := services.GetSong(db(ctx), id)
+	if errors.Is(err, services.ErrSongNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, serviceError(ctx, "Failed to retrieve song", err)
+	}
+	return &song, nil
+}
+
+// CreatedBy is the resolver for the createdBy field.
+func (r *songResolver) CreatedBy(ctx context.Context, obj *models.Song) (*models.User, error) {
+	return loadUser(ctx, obj.CreatedBy)
+}
+
+// UpdatedBy is the resolver for the updatedBy field.
+func (r *songResolver) UpdatedBy(ctx context.Context, obj *models.Song) (*models.User, error) {
+	return loadUser(ctx, obj.UpdatedBy)
+}
+
+// Artist is the resolver for the artist field.
+func (r *songResolver) Artist(ctx context.Context, obj *models.Song) (*Artist, error) {
+	count, _, err := loadersFrom(ctx).artists.load(ctx, obj.Group)
+	if err != nil {
+		return nil, serviceError(ctx, "Failed to retrieve artist", err)
+	}
+	return &Artist{Name: obj.Group, SongCount: count}, nil
+}
+
+// Enrichment is the resolver for the enrichment field.
+func (r *songResolver) Enrichment(ctx context.Context, obj *models.Song) (*Enrichment, error) {
+	status, missing := services.Enrichment(models.SongDetail{ReleaseDate: obj.ReleaseDate, Text: obj.Text, Link: obj.Link})
+	if missing == nil {
+		missing = []string{}
+	}
+	return &Enrichment{Status: status, Missing: missing}, nil
+}
+
+// Verses is the resolver for the verses field.
+func (r *songResolver) Verses(ctx context.Context, obj *models.Song, page int, limit *int) (*VersePage, error) {
+	perPage := config.Get().Limits.TextPageSize
+	if limit != nil {
+		if *limit < 1 || *limit > perPage {
+			return nil, newError(ctx, codeBadUserInput, fmt.Sprintf("Limit must be between 1 and %d", perPage))
+		}
+		perPage = *limit
+	}
+	if page < 1 {
+		return nil, newError(ctx, codeBadUserInput, "Page must be greater than 0")
+	}
+
+	verses := services.SplitVerses(obj.Text)
+	start := min((page-1)*perPage, len(verses))
+	end := min(start+perPage, len(verses))
+	return &VersePage{Items: verses[start:end], Page: page, Limit: perPage, Total: len(verses)}, nil
+}
+
+// Total is the resolver for the total field.
+func (r *songPageResolver) Total(ctx context.Context, obj *SongPage) (int, error) {
+	total, err := services.CountSongs(db(ctx), obj.filter)
+	if err != nil {
+		return 0, serviceError(ctx, "Failed to count songs", err)
+	}
+	return int(total), nil
+}
+
+// Mutation returns MutationResolver implementation.
+func (r *Resolver) Mutation() MutationResolver { return &mutationResolver{r} }
+
+// Query returns QueryResolver implementation.
+func (r *Resolver) Query() QueryResolver { return &queryResolver{r} }
+
+// Song returns SongResolver implementation.
+func (r *Resolver) Song() SongResolver { return &songResolver{r} }
+
+// SongPage returns SongPageResolver implementation.
+func (r *Resolver) SongPage() SongPageResolver { return &songPageResolver{r} }
+
+type mutationResolver struct{ *Resolver }
+type queryResolver struct{ *Resolver }
+type songResolver struct{ *Resolver }
+type songPageResolver struct{ *Resolver }
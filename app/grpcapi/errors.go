@@ -0,0 +1,53 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"music-library/app/config"
+	"music-library/app/models"
+	"music-library/app/services"
+)
+
+// serviceError преобразует ошибку сервисного слоя в статус gRPC, соответствующий коду ответа REST API.
+// Неизвестные ошибки записываются в журнал, а клиенту возвращается только message.
+func serviceError(ctx context.Context, message string, err error) error {
+	switch {
+	case errors.Is(err, services.ErrSongNotFound):
+		return status.Error(codes.NotFound, "Song not found")
+	case errors.Is(err, services.ErrInvalidSong):
+		return status.Error(codes.InvalidArgument, "Group and song name must not be empty")
+	case errors.Is(err, services.ErrDuplicateSong):
+		return status.Error(codes.AlreadyExists, "Song already exists")
+	case errors.Is(err, services.ErrVersionConflict):
+		return status.Error(codes.Aborted, "Song was modified by another request")
+	case errors.Is(err, services.ErrForbidden):
+		return status.Error(codes.PermissionDenied, "Editors may only delete their own songs")
+	case errors.Is(err, services.ErrEnrichmentFailed):
+		return status.Error(codes.Unavailable, "Failed to retrieve data from external API")
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return status.FromContextError(err).Err()
+	default:
+		slog.InfoContext(ctx, message, "error", err)
+		return status.Error(codes.Internal, message)
+	}
+}
+
+// checkIfMatch проверяет ETag из поля if_match перед изменением песни, как заголовок If-Match в REST API:
+// без него при features.requireIfMatch возвращается FailedPrecondition, при несовпадении - Aborted.
+func checkIfMatch(ctx context.Context, ifMatch string, song models.Song) error {
+	if ifMatch == "" {
+		if !config.Get().Features.RequireIfMatch {
+			return nil
+		}
+		return status.Error(codes.FailedPrecondition, "if_match is required")
+	}
+	if !services.MatchETag(ifMatch, song.ETag) {
+		slog.InfoContext(ctx, "if_match does not match current ETag", "etag", song.ETag)
+		return status.Error(codes.Aborted, "Song was modified by another request")
+	}
+	return nil
+}
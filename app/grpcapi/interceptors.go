@@ -0,0 +1,308 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/grpc/status"
+	"music-library/app/auth"
+	"music-library/app/config"
+	"music-library/app/grpcapi/librarypb"
+	"music-library/app/logging"
+	"music-library/app/metrics"
+	"music-library/app/ratelimit"
+)
+
+// Ключи метаданных вызова. В gRPC ключи метаданных передаются в нижнем регистре.
+var (
+	metadataRequestID = strings.ToLower(logging.HeaderRequestID)
+	metadataAPIKey    = strings.ToLower(auth.HeaderAPIKey)
+)
+
+// methodScopes - разрешения, которых требуют методы; методы с пустым разрешением, например reflection,
+// доступны без учетных данных. Методы, которых нет в списке, при включенной авторизации запрещены,
+// чтобы новый сервис не оказался открытым по ошибке.
+var methodScopes = map[string]string{
+	librarypb.SongService_ListSongs_FullMethodName:  auth.ScopeSongsRead,
+	librarypb.SongService_GetSong_FullMethodName:    auth.ScopeSongsRead,
+	librarypb.SongService_CreateSong_FullMethodName: auth.ScopeSongsWrite,
+	librarypb.SongService_UpdateSong_FullMethodName: auth.ScopeSongsWrite,
+	librarypb.SongService_DeleteSong_FullMethodName: auth.ScopeSongsDelete,
+
+	grpc_reflection_v1.ServerReflection_ServerReflectionInfo_FullMethodName:      "",
+	grpc_reflection_v1alpha.ServerReflection_ServerReflectionInfo_FullMethodName: "",
+}
+
+// methodClasses - классы лимитов частоты вызовов, как у соответствующих маршрутов HTTP API.
+// Вызовы остальных методов, например reflection, не ограничиваются.
+var methodClasses = map[string]string{
+	librarypb.SongService_ListSongs_FullMethodName:  ratelimit.ClassRead,
+	librarypb.SongService_GetSong_FullMethodName:    ratelimit.ClassRead,
+	librarypb.SongService_CreateSong_FullMethodName: ratelimit.ClassEnrich,
+	librarypb.SongService_UpdateSong_FullMethodName: ratelimit.ClassWrite,
+	librarypb.SongService_DeleteSong_FullMethodName: ratelimit.ClassWrite,
+}
+
+// Ключи метаданных ответа с состоянием лимита, как заголовки HTTP API.
+var (
+	metadataRateLimit     = strings.ToLower(ratelimit.HeaderLimit)
+	metadataRateRemaining = strings.ToLower(ratelimit.HeaderRemaining)
+	metadataRateReset     = strings.ToLower(ratelimit.HeaderReset)
+	metadataRetryAfter    = "retry-after"
+)
+
+// logUnary назначает вызову ID запроса и пишет в журнал строку о вызове, как logging.Middleware для HTTP.
+func logUnary(ctx context.Context, request interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (response interface{}, err error) {
+	ctx = withRequestID(ctx)
+	grpc.SetHeader(ctx, metadata.Pairs(metadataRequestID, logging.RequestID(ctx)))
+	started := time.Now()
+	defer func() {
+		err = recoverPanic(ctx, recover(), err)
+		logCall(ctx, info.FullMethod, started, err)
+	}()
+	return handler(ctx, request)
+}
+
+// logStream - то же, что logUnary, для потоковых вызовов.
+func logStream(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	ctx := withRequestID(stream.Context())
+	stream.SetHeader(metadata.Pairs(metadataRequestID, logging.RequestID(ctx)))
+	started := time.Now()
+	defer func() {
+		err = recoverPanic(ctx, recover(), err)
+		logCall(ctx, info.FullMethod, started, err)
+	}()
+	return handler(srv, &serverStream{ServerStream: stream, ctx: ctx})
+}
+
+// withRequestID кладет в контекст ID запроса из метаданных x-request-id или новый.
+func withRequestID(ctx context.Context) context.Context {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if ids := md.Get(metadataRequestID); len(ids) > 0 && logging.ValidRequestID(ids[0]) {
+		return logging.WithRequestID(ctx, ids[0])
+	}
+	return logging.WithRequestID(ctx, logging.NewRequestID())
+}
+
+// recoverPanic превращает панику обработчика в ошибку Internal, чтобы она не остановила процесс.
+func recoverPanic(ctx context.Context, recovered interface{}, err error) error {
+	if recovered == nil {
+		return err
+	}
+	slog.ErrorContext(ctx, "gRPC handler panicked", "panic", recovered)
+	return status.Error(codes.Internal, "Internal server error")
+}
+
+// logCall пишет в журнал итог вызова.
+func logCall(ctx context.Context, method string, started time.Time, err error) {
+	code := status.Code(err)
+	level := slog.LevelInfo
+	switch code {
+	case codes.Internal, codes.Unknown, codes.DataLoss, codes.Unavailable:
+		level = slog.LevelError
+	}
+	remoteAddr := ""
+	if p, ok := peer.FromContext(ctx); ok {
+		remoteAddr = p.Addr.String()
+	}
+	slog.Log(ctx, level, "gRPC request",
+		"method", method,
+		"code", code.String(),
+		"duration_ms", float64(time.Since(started).Microseconds())/1000,
+		"remote_addr", remoteAddr,
+	)
+}
+
+// authUnary проверяет учетные данные вызова и кладет участника в контекст.
+func authUnary(authenticate auth.Authenticator) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, request interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := authorize(ctx, info.FullMethod, authenticate)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, request)
+	}
+}
+
+// authStream - то же, что authUnary, для потоковых вызовов.
+func authStream(authenticate auth.Authenticator) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authorize(stream.Context(), info.FullMethod, authenticate)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &serverStream{ServerStream: stream, ctx: ctx})
+	}
+}
+
+// authorize проверяет ключ доступа или токен из метаданных authorization (Bearer) или x-api-key
+// и наличие у участника разрешения, которого требует метод, по тем же правилам, что и auth.Middleware.
+func authorize(ctx context.Context, method string, authenticate auth.Authenticator) (context.Context, error) {
+	settings := config.Get().Auth
+	if !settings.Enabled {
+		return ctx, nil
+	}
+	scope, ok := methodScopes[method]
+	if !ok {
+		slog.InfoContext(ctx, "Method is not allowed", "method", method)
+		return ctx, status.Error(codes.PermissionDenied, "Method "+method+" is not allowed")
+	}
+	if scope == "" {
+		return ctx, nil
+	}
+
+	credential, ok := credentialFrom(ctx)
+	if !ok {
+		return ctx, unauthenticated(ctx, "Authorization metadata must use the Bearer scheme")
+	}
+
+	var principal *auth.Principal
+	switch {
+	case credential != "":
+		var err error
+		principal, err = authenticate(ctx, credential)
+		if errors.Is(err, auth.ErrUnauthenticated) {
+			return ctx, unauthenticated(ctx, "Invalid or expired credentials")
+		}
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to authenticate request", "error", err)
+			return ctx, status.Error(codes.Internal, "Failed to authenticate request")
+		}
+	case settings.AnonymousRead && scope == auth.ScopeSongsRead:
+		principal = &auth.Principal{Type: auth.PrincipalAnonymous, Scopes: []string{auth.ScopeSongsRead}}
+	default:
+		return ctx, unauthenticated(ctx, "Authentication required")
+	}
+
+	if !principal.HasScope(scope) {
+		slog.InfoContext(ctx, "Insufficient scope", "principal", principal.Name, "scope", scope)
+		return ctx, status.Error(codes.PermissionDenied, "Scope "+scope+" is required")
+	}
+	return auth.WithPrincipal(ctx, principal), nil
+}
+
+// credentialFrom извлекает ключ доступа или токен из метаданных вызова.
+// Возвращает false, если authorization задан не по схеме Bearer.
+func credentialFrom(ctx context.Context) (string, bool) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if keys := md.Get(metadataAPIKey); len(keys) > 0 && keys[0] != "" {
+		return keys[0], true
+	}
+	values := md.Get("authorization")
+	if len(values) == 0 || values[0] == "" {
+		return "", true
+	}
+	scheme, token, ok := strings.Cut(values[0], " ")
+	token = strings.TrimSpace(token)
+	if !ok || !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return "", false
+	}
+	return token, true
+}
+
+func unauthenticated(ctx context.Context, message string) error {
+	slog.InfoContext(ctx, "Unauthenticated request", "reason", message)
+	return status.Error(codes.Unauthenticated, message)
+}
+
+// rateLimitUnary ограничивает частоту вызовов клиента теми же корзинами и лимитами, что и ratelimit.Middleware,
+// поэтому HTTP и gRPC API расходуют общий лимит клиента. Подключается после authUnary.
+func rateLimitUnary(store ratelimit.Store) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, request interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := takeToken(ctx, store, info.FullMethod); err != nil {
+			return nil, err
+		}
+		return handler(ctx, request)
+	}
+}
+
+// rateLimitStream - то же, что rateLimitUnary, для потоковых вызовов. Токен берется один раз на вызов.
+func rateLimitStream(store ratelimit.Store) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := takeToken(stream.Context(), store, info.FullMethod); err != nil {
+			return err
+		}
+		return handler(srv, stream)
+	}
+}
+
+// takeToken берет токен из корзины клиента для класса метода и возвращает ResourceExhausted, если лимит исчерпан.
+// Состояние лимита передается в метаданных ответа.
+func takeToken(ctx context.Context, store ratelimit.Store, method string) error {
+	settings := config.Get().RateLimit
+	class, ok := methodClasses[method]
+	if !settings.Enabled || !ok {
+		return nil
+	}
+
+	principal := auth.PrincipalFrom(ctx)
+	limit := ratelimit.Limit{Requests: ratelimit.LimitFor(settings, principal, class), Window: settings.Window}
+	key := class + ":" + ratelimit.ClientKey(principal, peerIP(ctx, settings.TrustProxy))
+	result, err := store.Take(ctx, key, limit)
+	if err != nil {
+		// Недоступность общего хранилища не должна останавливать API.
+		slog.WarnContext(ctx, "Rate limit store failed, request allowed", "error", err)
+		return nil
+	}
+
+	md := metadata.Pairs(
+		metadataRateLimit, strconv.Itoa(limit.Requests),
+		metadataRateRemaining, strconv.Itoa(result.Remaining),
+		metadataRateReset, strconv.Itoa(ratelimit.CeilSeconds(result.Reset)),
+	)
+	if result.Allowed {
+		grpc.SetHeader(ctx, md)
+		return nil
+	}
+
+	retryAfter := ratelimit.CeilSeconds(result.RetryAfter)
+	slog.InfoContext(ctx, "Rate limit exceeded", "class", class, "client", key, "retry_after", retryAfter)
+	metrics.ObserveRateLimited(class)
+	md.Set(metadataRetryAfter, strconv.Itoa(retryAfter))
+	grpc.SetHeader(ctx, md)
+	return status.Errorf(codes.ResourceExhausted, "Rate limit for %s requests exceeded, retry in %d seconds", class, retryAfter)
+}
+
+// peerIP возвращает адрес клиента вызова. Метаданные x-forwarded-for учитываются, только если сервис
+// стоит за доверенным прокси, как и в HTTP API.
+func peerIP(ctx context.Context, trustProxy bool) string {
+	if trustProxy {
+		md, _ := metadata.FromIncomingContext(ctx)
+		if forwarded := md.Get("x-forwarded-for"); len(forwarded) > 0 {
+			first, _, _ := strings.Cut(forwarded[0], ",")
+			if ip := strings.TrimSpace(first); ip != "" {
+				return ip
+			}
+		}
+	}
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
+
+// serverStream подменяет контекст потокового вызова.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
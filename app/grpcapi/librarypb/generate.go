@@ -0,0 +1,4 @@
+// Package librarypb содержит сообщения и сервис gRPC API, сгенерированные из library.proto.
+package librarypb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative library.proto
@@ -0,0 +1,297 @@
+// gRPC API библиотеки песен. Методы повторяют REST API и работают через тот же сервисный слой.
+// После изменения перегенерируйте код: go generate ./app/grpcapi/librarypb
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: library.proto
+
+package librarypb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	SongService_ListSongs_FullMethodName  = "/musiclibrary.v1.SongService/ListSongs"
+	SongService_GetSong_FullMethodName    = "/musiclibrary.v1.SongService/GetSong"
+	SongService_CreateSong_FullMethodName = "/musiclibrary.v1.SongService/CreateSong"
+	SongService_UpdateSong_FullMethodName = "/musiclibrary.v1.SongService/UpdateSong"
+	SongService_DeleteSong_FullMethodName = "/musiclibrary.v1.SongService/DeleteSong"
+)
+
+// SongServiceClient is the client API for SongService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// SongService - каталог песен.
+type SongServiceClient interface {
+	// ListSongs отдает песни, подходящие под фильтр, потоком по возрастанию ID.
+	ListSongs(ctx context.Context, in *ListSongsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Song], error)
+	// GetSong возвращает песню по ID.
+	GetSong(ctx context.Context, in *GetSongRequest, opts ...grpc.CallOption) (*Song, error)
+	// CreateSong добавляет песню, запрашивая дату релиза, текст и ссылку во внешнем API.
+	// Как и POST /songs, не проверяет, есть ли уже песня с той же группой и названием.
+	CreateSong(ctx context.Context, in *CreateSongRequest, opts ...grpc.CallOption) (*Song, error)
+	// UpdateSong изменяет песню. Пустые поля не меняются.
+	UpdateSong(ctx context.Context, in *UpdateSongRequest, opts ...grpc.CallOption) (*Song, error)
+	// DeleteSong удаляет песню. Редакторы могут удалять только свои песни.
+	DeleteSong(ctx context.Context, in *DeleteSongRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+}
+
+type songServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSongServiceClient(cc grpc.ClientConnInterface) SongServiceClient {
+	return &songServiceClient{cc}
+}
+
+func (c *songServiceClient) ListSongs(ctx context.Context, in *ListSongsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Song], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &SongService_ServiceDesc.Streams[0], SongService_ListSongs_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ListSongsRequest, Song]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type SongService_ListSongsClient = grpc.ServerStreamingClient[Song]
+
+func (c *songServiceClient) GetSong(ctx context.Context, in *GetSongRequest, opts ...grpc.CallOption) (*Song, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Song)
+	err := c.cc.Invoke(ctx, SongService_GetSong_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *songServiceClient) CreateSong(ctx context.Context, in *CreateSongRequest, opts ...grpc.CallOption) (*Song, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Song)
+	err := c.cc.Invoke(ctx, SongService_CreateSong_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *songServiceClient) UpdateSong(ctx context.Context, in *UpdateSongRequest, opts ...grpc.CallOption) (*Song, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Song)
+	err := c.cc.Invoke(ctx, SongService_UpdateSong_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *songServiceClient) DeleteSong(ctx context.Context, in *DeleteSongRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, SongService_DeleteSong_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// SongServiceServer is the server API for SongService service.
+// All implementations must embed UnimplementedSongServiceServer
+// for forward compatibility.
+//
+// SongService - каталог песен.
+type SongServiceServer interface {
+	// ListSongs отдает песни, подходящие под фильтр, потоком по возрастанию ID.
+	ListSongs(*ListSongsRequest, grpc.ServerStreamingServer[Song]) error
+	// GetSong возвращает песню по ID.
+	GetSong(context.Context, *GetSongRequest) (*Song, error)
+	// CreateSong добавляет песню, запрашивая дату релиза, текст и ссылку во внешнем API.
+	// Как и POST /songs, не проверяет, есть ли уже песня с той же группой и названием.
+	CreateSong(context.Context, *CreateSongRequest) (*Song, error)
+	// UpdateSong изменяет песню. Пустые поля не меняются.
+	UpdateSong(context.Context, *UpdateSongRequest) (*Song, error)
+	// DeleteSong удаляет песню. Редакторы могут удалять только свои песни.
+	DeleteSong(context.Context, *DeleteSongRequest) (*emptypb.Empty, error)
+	mustEmbedUnimplementedSongServiceServer()
+}
+
+// UnimplementedSongServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedSongServiceServer struct{}
+
+func (UnimplementedSongServiceServer) ListSongs(*ListSongsRequest, grpc.ServerStreamingServer[Song]) error {
+	return status.Errorf(codes.Unimplemented, "method ListSongs not implemented")
+}
+func (UnimplementedSongServiceServer) GetSong(context.Context, *GetSongRequest) (*Song, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSong not implemented")
+}
+func (UnimplementedSongServiceServer) CreateSong(context.Context, *CreateSongRequest) (*Song, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateSong not implemented")
+}
+func (UnimplementedSongServiceServer) UpdateSong(context.Context, *UpdateSongRequest) (*Song, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateSong not implemented")
+}
+func (UnimplementedSongServiceServer) DeleteSong(context.Context, *DeleteSongRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteSong not implemented")
+}
+func (UnimplementedSongServiceServer) mustEmbedUnimplementedSongServiceServer() {}
+func (UnimplementedSongServiceServer) testEmbeddedByValue()                     {}
+
+// UnsafeSongServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to SongServiceServer will
+// result in compilation errors.
+type UnsafeSongServiceServer interface {
+	mustEmbedUnimplementedSongServiceServer()
+}
+
+func RegisterSongServiceServer(s grpc.ServiceRegistrar, srv SongServiceServer) {
+	// If the following call pancis, it indicates UnimplementedSongServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&SongService_ServiceDesc, srv)
+}
+
+func _SongService_ListSongs_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ListSongsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SongServiceServer).ListSongs(m, &grpc.GenericServerStream[ListSongsRequest, Song]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type SongService_ListSongsServer = grpc.ServerStreamingServer[Song]
+
+func _SongService_GetSong_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSongRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SongServiceServer).GetSong(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SongService_GetSong_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SongServiceServer).GetSong(ctx, req.(*GetSongRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SongService_CreateSong_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateSongRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SongServiceServer).CreateSong(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SongService_CreateSong_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SongServiceServer).CreateSong(ctx, req.(*CreateSongRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SongService_UpdateSong_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateSongRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SongServiceServer).UpdateSong(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SongService_UpdateSong_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SongServiceServer).UpdateSong(ctx, req.(*UpdateSongRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SongService_DeleteSong_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteSongRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SongServiceServer).DeleteSong(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SongService_DeleteSong_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SongServiceServer).DeleteSong(ctx, req.(*DeleteSongRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// SongService_ServiceDesc is the grpc.ServiceDesc for SongService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var SongService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "musiclibrary.v1.SongService",
+	HandlerType: (*SongServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetSong",
+			Handler:    _SongService_GetSong_Handler,
+		},
+		{
+			MethodName: "CreateSong",
+			Handler:    _SongService_CreateSong_Handler,
+		},
+		{
+			MethodName: "UpdateSong",
+			Handler:    _SongService_UpdateSong_Handler,
+		},
+		{
+			MethodName: "DeleteSong",
+			Handler:    _SongService_DeleteSong_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ListSongs",
+			Handler:       _SongService_ListSongs_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "library.proto",
+}
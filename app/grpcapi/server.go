@@ -0,0 +1,73 @@
+// Package grpcapi реализует gRPC API библиотеки поверх того же сервисного слоя, что и REST API.
+package grpcapi
+
+import (
+	"context"
+	"net"
+	"net/http"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+	"music-library/app/auth"
+	"music-library/app/config"
+	"music-library/app/grpcapi/librarypb"
+	"music-library/app/ratelimit"
+)
+
+// Server - gRPC API. Запускается и останавливается теми же методами, что и http.Server,
+// чтобы работать в одном процессе с HTTP API.
+type Server struct {
+	Addr string // Адрес, на котором слушает сервер
+
+	server *grpc.Server
+}
+
+// NewServer создает gRPC API с проверкой учетных данных через authenticate и лимитами частоты вызовов
+// в limits. Чтобы лимит клиента был общим для HTTP и gRPC API, limits должно быть тем же, что и у HTTP API.
+func NewServer(settings config.GRPCConfig, authenticate auth.Authenticator, limits ratelimit.Store) *Server {
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(logUnary, authUnary(authenticate), rateLimitUnary(limits)),
+		grpc.ChainStreamInterceptor(logStream, authStream(authenticate), rateLimitStream(limits)),
+	)
+	librarypb.RegisterSongServiceServer(server, &songServer{})
+	if settings.Reflection {
+		reflection.Register(server)
+	}
+	return &Server{Addr: settings.Addr, server: server}
+}
+
+// ListenAndServe принимает соединения на Addr. Как и у http.Server, после остановки
+// возвращается http.ErrServerClosed.
+func (s *Server) ListenAndServe() error {
+	listener, err := net.Listen("tcp", s.Addr)
+	if err != nil {
+		return err
+	}
+	if err := s.server.Serve(listener); err != nil {
+		return err
+	}
+	return http.ErrServerClosed
+}
+
+// Shutdown перестает принимать вызовы и ждет завершения текущих, в том числе потоков ListSongs.
+// Если ctx истекает раньше, оставшиеся вызовы прерываются.
+func (s *Server) Shutdown(ctx context.Context) error {
+	stopped := make(chan struct{})
+	go func() {
+		s.server.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		s.server.Stop()
+		return ctx.Err()
+	}
+}
+
+// Close прерывает все соединения и вызовы.
+func (s *Server) Close() error {
+	s.server.Stop()
+	return nil
+}
@@ -0,0 +1,183 @@
+package grpcapi
+
+import (
+	"context"
+	"log/slog"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"gorm.io/gorm"
+	"music-library/app/database"
+	"music-library/app/grpcapi/librarypb"
+	"music-library/app/models"
+	"music-library/app/services"
+	"music-library/app/tracing"
+)
+
+// listSongsBatchSize - сколько песен ListSongs читает из базы данных за один запрос.
+const listSongsBatchSize = 500
+
+// songServer реализует SongService через сервисный слой.
+type songServer struct {
+	librarypb.UnimplementedSongServiceServer
+}
+
+// db возвращает подключение к базе данных с контекстом вызова: по нему сервисы определяют автора изменений.
+func db(ctx context.Context) *gorm.DB {
+	return database.DB.WithContext(ctx)
+}
+
+// ListSongs отдает песни по частям, чтобы поток любой длины не держал в памяти всю выборку.
+// Следующая часть читается после песен с большим ID, поэтому песни, добавленные во время потока,
+// не сдвигают уже отданные.
+func (s *songServer) ListSongs(request *librarypb.ListSongsRequest, stream grpc.ServerStreamingServer[librarypb.Song]) error {
+	ctx := stream.Context()
+	if request.Limit < 0 || request.Offset < 0 {
+		return status.Error(codes.InvalidArgument, "Limit and offset must not be negative")
+	}
+
+	filter := services.SongFilter{
+		Group:  request.Group,
+		Name:   request.Song,
+		Search: request.Search,
+		Offset: int(request.Offset),
+	}
+	remaining := int(request.Limit)
+	var last uint
+	for {
+		filter.Limit = listSongsBatchSize
+		if request.Limit > 0 {
+			filter.Limit = min(filter.Limit, remaining)
+		}
+
+		var songs []models.Song
+		query := services.ApplySongFilter(db(ctx).Model(&models.Song{}), filter).Where("id > ?", last).Order("id")
+		if err := query.Find(&songs).Error; err != nil {
+			return serviceError(ctx, "Failed to retrieve songs", err)
+		}
+		for i := range songs {
+			if err := stream.Send(toProto(songs[i])); err != nil {
+				return err
+			}
+			last = songs[i].ID
+		}
+
+		remaining -= len(songs)
+		if len(songs) < filter.Limit || (request.Limit > 0 && remaining == 0) {
+			return nil
+		}
+		filter.Offset = 0
+	}
+}
+
+// GetSong возвращает песню по ID.
+func (s *songServer) GetSong(ctx context.Context, request *librarypb.GetSongRequest) (*librarypb.Song, error) {
+	song, err := services.GetSong(db(ctx), request.Id)
+	if err != nil {
+		return nil, serviceError(ctx, "Failed to retrieve song", err)
+	}
+	return toProto(song), nil
+}
+
+// CreateSong добавляет песню с данными из внешнего API через тот же services.InsertSong, что и POST /songs,
+// поэтому дубликаты по группе и названию, как и в REST API, не отклоняются.
+func (s *songServer) CreateSong(ctx context.Context, request *librarypb.CreateSongRequest) (*librarypb.Song, error) {
+	song := models.Song{Group: request.Group, Name: request.Song, Album: request.Album}
+	if err := services.ValidateSong(song); err != nil {
+		return nil, serviceError(ctx, "Invalid song", err)
+	}
+
+	tracing.SetSongAttributes(ctx, song)
+	if err := services.EnrichSong(ctx, &song); err != nil {
+		return nil, serviceError(ctx, "Failed to retrieve data from external API", err)
+	}
+	if err := services.InsertSong(db(ctx), &song); err != nil {
+		return nil, serviceError(ctx, "Failed to save song to the database", err)
+	}
+
+	tracing.SetSongAttributes(ctx, song)
+	slog.DebugContext(ctx, "Successfully added song", "id", song.ID)
+	return toProto(song), nil
+}
+
+// UpdateSong изменяет песню, как PATCH /songs/{id}.
+func (s *songServer) UpdateSong(ctx context.Context, request *librarypb.UpdateSongRequest) (*librarypb.Song, error) {
+	existing, err := services.GetSong(db(ctx), request.Id)
+	if err != nil {
+		return nil, serviceError(ctx, "Failed to retrieve song", err)
+	}
+	if err := checkIfMatch(ctx, request.IfMatch, existing); err != nil {
+		return nil, err
+	}
+
+	changes := models.Song{
+		Group:       request.Group,
+		Name:        request.Song,
+		ReleaseDate: request.GetDetail().GetReleaseDate(),
+		Text:        request.GetDetail().GetText(),
+		Link:        request.GetDetail().GetLink(),
+		Album:       request.Album,
+	}
+	updated, err := services.UpdateSong(db(ctx), existing, changes)
+	if err != nil {
+		return nil, serviceError(ctx, "Failed to update song", err)
+	}
+
+	slog.DebugContext(ctx, "Successfully updated song", "id", request.Id)
+	return toProto(updated), nil
+}
+
+// DeleteSong удаляет песню, как DELETE /songs/{id}.
+func (s *songServer) DeleteSong(ctx context.Context, request *librarypb.DeleteSongRequest) (*emptypb.Empty, error) {
+	existing, err := services.GetSong(db(ctx), request.Id)
+	if err != nil {
+		return nil, serviceError(ctx, "Failed to retrieve song", err)
+	}
+	if err := checkIfMatch(ctx, request.IfMatch, existing); err != nil {
+		return nil, err
+	}
+
+	if err := services.DeleteSong(db(ctx), existing); err != nil {
+		return nil, serviceError(ctx, "Failed to delete song", err)
+	}
+
+	slog.DebugContext(ctx, "Successfully deleted song", "id", request.Id)
+	return &emptypb.Empty{}, nil
+}
+
+// toProto преобразует песню в сообщение gRPC.
+func toProto(song models.Song) *librarypb.Song {
+	detail := models.SongDetail{ReleaseDate: song.ReleaseDate, Text: song.Text, Link: song.Link}
+	enrichment, missing := services.Enrichment(detail)
+	message := &librarypb.Song{
+		Id:        uint64(song.ID),
+		CreatedAt: timestamppb.New(song.CreatedAt),
+		UpdatedAt: timestamppb.New(song.UpdatedAt),
+		Group:     song.Group,
+		Song:      song.Name,
+		Detail: &librarypb.SongDetail{
+			ReleaseDate: detail.ReleaseDate,
+			Text:        detail.Text,
+			Link:        detail.Link,
+		},
+		Album:      song.Album,
+		FilePath:   song.FilePath,
+		Duration:   int32(song.Duration),
+		Bitrate:    int32(song.Bitrate),
+		Version:    uint64(song.Version),
+		Etag:       song.ETag,
+		Enrichment: &librarypb.Enrichment{Status: enrichment, Missing: missing},
+	}
+	if song.CreatedBy != nil {
+		id := uint64(*song.CreatedBy)
+		message.CreatedBy = &id
+	}
+	if song.UpdatedBy != nil {
+		id := uint64(*song.UpdatedBy)
+		message.UpdatedBy = &id
+	}
+	return message
+}
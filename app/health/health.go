@@ -0,0 +1,108 @@
+package health
+
+import (
+	"context"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"music-library/app/models"
+)
+
+// Version - версия сборки. Задается при сборке: -ldflags "-X music-library/app/health.Version=1.2.3".
+var Version = "dev"
+
+// startedAt - время запуска процесса для расчета uptime.
+var startedAt = time.Now()
+
+// Статусы проверок.
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+)
+
+// Check - проверка доступности зависимости сервиса.
+type Check struct {
+	Name     string                          // Имя проверки
+	Critical bool                            // Выводит ли ее отказ сервис из балансировки
+	Timeout  time.Duration                   // Максимальная длительность проверки
+	Run      func(ctx context.Context) error // Проверка, nil - зависимость доступна
+}
+
+// RunChecks выполняет проверки параллельно, каждую со своим таймаутом, и возвращает результаты
+// в порядке проверок, а также признак готовности - все критичные проверки прошли.
+func RunChecks(ctx context.Context, checks []Check) ([]models.HealthCheckResult, bool) {
+	results := make([]models.HealthCheckResult, len(checks))
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func(i int, check Check) {
+			defer wg.Done()
+			results[i] = run(ctx, check)
+		}(i, check)
+	}
+	wg.Wait()
+
+	ready := true
+	for _, result := range results {
+		if result.Critical && result.Status != StatusUp {
+			ready = false
+		}
+	}
+	return results, ready
+}
+
+func run(ctx context.Context, check Check) models.HealthCheckResult {
+	ctx, cancel := context.WithTimeout(ctx, check.Timeout)
+	defer cancel()
+
+	started := time.Now()
+	errs := make(chan error, 1)
+	go func() {
+		errs <- check.Run(ctx)
+	}()
+
+	// Проверка, не уважающая контекст, не задерживает ответ дольше своего таймаута.
+	var err error
+	select {
+	case err = <-errs:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	result := models.HealthCheckResult{
+		Name:      check.Name,
+		Status:    StatusUp,
+		Critical:  check.Critical,
+		LatencyMs: float64(time.Since(started).Microseconds()) / 1000,
+	}
+	if err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// Uptime возвращает время работы процесса.
+func Uptime() time.Duration {
+	return time.Since(startedAt)
+}
+
+// StartedAt возвращает время запуска процесса.
+func StartedAt() time.Time {
+	return startedAt
+}
+
+// Revision возвращает ревизию VCS, из которой собран бинарный файл, если она известна.
+func Revision() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	for _, setting := range info.Settings {
+		if setting.Key == "vcs.revision" {
+			return setting.Value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,294 @@
+package importer
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"music-library/app/models"
+)
+
+// Поддерживаемые форматы файлов импорта.
+const (
+	FormatCSV    = "csv"
+	FormatJSON   = "json"
+	FormatNDJSON = "ndjson"
+)
+
+// songFields перечисляет поля песни, которые можно загрузить из файла.
+var songFields = []string{"group", "song", "releaseDate", "text", "link"}
+
+// defaultColumns перечисляет названия колонок, которые распознаются без явного сопоставления.
+var defaultColumns = map[string][]string{
+	"group":       {"group", "artist", "band", "исполнитель", "группа"},
+	"song":        {"song", "title", "name", "песня", "название"},
+	"releaseDate": {"releasedate", "release_date", "release date", "date", "year", "дата релиза"},
+	"text":        {"text", "lyrics", "текст"},
+	"link":        {"link", "url", "ссылка"},
+}
+
+// Options задает формат файла и сопоставление его колонок с полями песни.
+type Options struct {
+	Format    string            // csv, json или ndjson
+	Mapping   map[string]string // Поле песни -> колонка или ключ в файле
+	Delimiter rune              // Разделитель колонок CSV, по умолчанию запятая
+}
+
+// Record описывает одну запись файла импорта.
+type Record struct {
+	Row  int         // Номер записи с единицы, строка заголовка CSV не учитывается
+	Song models.Song // Песня, собранная из записи
+	Raw  string      // Исходное содержимое записи
+	Err  error       // Ошибка разбора записи
+}
+
+// DetectFormat определяет формат файла по расширению.
+func DetectFormat(fileName string) string {
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".csv", ".tsv":
+		return FormatCSV
+	case ".json":
+		return FormatJSON
+	case ".ndjson", ".jsonl":
+		return FormatNDJSON
+	}
+	return ""
+}
+
+// ValidFormat проверяет, поддерживается ли формат импорта.
+func ValidFormat(format string) bool {
+	return format == FormatCSV || format == FormatJSON || format == FormatNDJSON
+}
+
+// ParseMapping разбирает сопоставление вида "group=Artist,song=Title".
+func ParseMapping(value string) (map[string]string, error) {
+	mapping := make(map[string]string)
+	for _, pair := range strings.Split(value, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		field, column, ok := strings.Cut(pair, "=")
+		field, column = strings.TrimSpace(field), strings.TrimSpace(column)
+		if !ok || column == "" {
+			return nil, fmt.Errorf("invalid mapping %q, expected field=column", pair)
+		}
+		if !isSongField(field) {
+			return nil, fmt.Errorf("unknown song field %q, expected one of %s", field, strings.Join(songFields, ", "))
+		}
+		mapping[field] = column
+	}
+	return mapping, nil
+}
+
+// FormatMapping сериализует сопоставление в вид "group=Artist,song=Title".
+func FormatMapping(mapping map[string]string) string {
+	var pairs []string
+	for _, field := range songFields {
+		if column, ok := mapping[field]; ok {
+			pairs = append(pairs, field+"="+column)
+		}
+	}
+	return strings.Join(pairs, ",")
+}
+
+// ParseDelimiter проверяет разделитель колонок CSV.
+func ParseDelimiter(value string) (rune, error) {
+	switch value {
+	case "":
+		return ',', nil
+	case `\t`, "tab":
+		return '\t', nil
+	}
+	delimiter, size := utf8.DecodeRuneInString(value)
+	if size != len(value) {
+		return 0, fmt.Errorf("delimiter must be a single character")
+	}
+	return delimiter, nil
+}
+
+// Read читает записи файла импорта и передает их в fn по одной, не загружая файл в память целиком.
+// Ошибки отдельных записей передаются в Record.Err, ошибка fn прерывает чтение.
+func Read(r io.Reader, opts Options, fn func(Record) error) error {
+	switch opts.Format {
+	case FormatCSV:
+		return readCSV(r, opts, fn)
+	case FormatJSON:
+		return readJSON(r, opts, fn)
+	case FormatNDJSON:
+		return readNDJSON(r, opts, fn)
+	}
+	return fmt.Errorf("unsupported import format %q", opts.Format)
+}
+
+func readCSV(r io.Reader, opts Options, fn func(Record) error) error {
+	reader := csv.NewReader(r)
+	reader.Comma = opts.Delimiter
+	if reader.Comma == 0 {
+		reader.Comma = ','
+	}
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		return fmt.Errorf("failed to read CSV header: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[normalizeColumn(strings.TrimPrefix(name, "\ufeff"))] = i
+	}
+
+	indexes := make(map[string]int)
+	for _, field := range songFields {
+		if column, ok := opts.Mapping[field]; ok {
+			index, found := columns[normalizeColumn(column)]
+			if !found {
+				return fmt.Errorf("mapped column %q for field %s not found in CSV header", column, field)
+			}
+			indexes[field] = index
+			continue
+		}
+		for _, candidate := range defaultColumns[field] {
+			if index, found := columns[candidate]; found {
+				indexes[field] = index
+				break
+			}
+		}
+	}
+
+	for row := 1; ; row++ {
+		values, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		record := Record{Row: row, Raw: strings.Join(values, string(reader.Comma))}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return err
+			}
+			record.Err = err
+		} else {
+			fieldValues := make(map[string]string, len(indexes))
+			for field, index := range indexes {
+				if index < len(values) {
+					fieldValues[field] = values[index]
+				}
+			}
+			record.Song = songFromFields(fieldValues)
+		}
+
+		if err := fn(record); err != nil {
+			return err
+		}
+	}
+}
+
+func readJSON(r io.Reader, opts Options, fn func(Record) error) error {
+	decoder := json.NewDecoder(r)
+	token, err := decoder.Token()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		return fmt.Errorf("failed to read JSON array: %w", err)
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '[' {
+		return fmt.Errorf("JSON import must be an array of objects")
+	}
+
+	for row := 1; decoder.More(); row++ {
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err != nil {
+			return fmt.Errorf("record %d: %w", row, err)
+		}
+		if err := fn(recordFromJSON(row, raw, opts)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readNDJSON(r io.Reader, opts Options, fn func(Record) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	row := 0
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		row++
+		if err := fn(recordFromJSON(row, []byte(line), opts)); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// recordFromJSON собирает запись из JSON-объекта с учетом сопоставления ключей.
+func recordFromJSON(row int, raw []byte, opts Options) Record {
+	record := Record{Row: row, Raw: string(raw)}
+
+	var object map[string]interface{}
+	if err := json.Unmarshal(raw, &object); err != nil {
+		record.Err = fmt.Errorf("invalid JSON object: %w", err)
+		return record
+	}
+
+	keys := make(map[string]string, len(object))
+	for key := range object {
+		keys[normalizeColumn(key)] = key
+	}
+
+	fieldValues := make(map[string]string)
+	for _, field := range songFields {
+		candidates := defaultColumns[field]
+		if column, ok := opts.Mapping[field]; ok {
+			candidates = []string{normalizeColumn(column)}
+		}
+		for _, candidate := range candidates {
+			if key, found := keys[candidate]; found && object[key] != nil {
+				fieldValues[field] = fmt.Sprint(object[key])
+				break
+			}
+		}
+	}
+	record.Song = songFromFields(fieldValues)
+	return record
+}
+
+func songFromFields(values map[string]string) models.Song {
+	return models.Song{
+		Group:       strings.TrimSpace(values["group"]),
+		Name:        strings.TrimSpace(values["song"]),
+		ReleaseDate: strings.TrimSpace(values["releaseDate"]),
+		Text:        values["text"],
+		Link:        strings.TrimSpace(values["link"]),
+	}
+}
+
+func normalizeColumn(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+func isSongField(field string) bool {
+	for _, known := range songFields {
+		if known == field {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,74 @@
+package logging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// gormLogger направляет журнал GORM в slog. Запросы пишутся с уровнем debug,
+// медленные - warn, ошибочные - error. ID запроса берется из контекста, переданного в WithContext.
+type gormLogger struct {
+	slowThreshold time.Duration
+}
+
+// NewGormLogger создает журнал GORM. Запросы дольше slowThreshold отмечаются как медленные,
+// 0 отключает это.
+func NewGormLogger(slowThreshold time.Duration) logger.Interface {
+	return gormLogger{slowThreshold: slowThreshold}
+}
+
+// LogMode не меняет уровень: фильтрацию выполняет slog.
+func (l gormLogger) LogMode(logger.LogLevel) logger.Interface {
+	return l
+}
+
+func (l gormLogger) Info(ctx context.Context, message string, args ...interface{}) {
+	slog.InfoContext(ctx, fmt.Sprintf(message, args...), "component", "gorm")
+}
+
+func (l gormLogger) Warn(ctx context.Context, message string, args ...interface{}) {
+	slog.WarnContext(ctx, fmt.Sprintf(message, args...), "component", "gorm")
+}
+
+func (l gormLogger) Error(ctx context.Context, message string, args ...interface{}) {
+	slog.ErrorContext(ctx, fmt.Sprintf(message, args...), "component", "gorm")
+}
+
+func (l gormLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	elapsed := time.Since(begin)
+	failed := err != nil && !errors.Is(err, gorm.ErrRecordNotFound)
+	slow := l.slowThreshold > 0 && elapsed > l.slowThreshold
+
+	level := slog.LevelDebug
+	message := "Database query"
+	switch {
+	case failed:
+		level, message = slog.LevelError, "Database query failed"
+	case slow:
+		level, message = slog.LevelWarn, "Slow database query"
+	}
+	if !slog.Default().Enabled(ctx, level) {
+		return
+	}
+
+	sql, rows := fc()
+	attrs := []interface{}{
+		"component", "gorm",
+		"sql", sql,
+		"rows", rows,
+		"duration_ms", float64(elapsed.Microseconds()) / 1000,
+	}
+	if failed {
+		attrs = append(attrs, "error", err)
+	}
+	if slow {
+		attrs = append(attrs, "threshold_ms", l.slowThreshold.Milliseconds())
+	}
+	slog.Log(ctx, level, message, attrs...)
+}
@@ -0,0 +1,70 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Форматы вывода журнала.
+const (
+	FormatJSON = "json" // Одна JSON-запись на строку
+	FormatText = "text" // Пары key=value для чтения в терминале
+)
+
+// Setup настраивает журнал по умолчанию: уровень (debug, info, warn, error) и формат (json, text).
+// Сообщения стандартного пакета log также попадают в этот журнал с уровнем info.
+func Setup(level, format string) error {
+	handler, err := NewHandler(os.Stderr, level, format)
+	if err != nil {
+		return err
+	}
+	slog.SetDefault(slog.New(handler))
+	return nil
+}
+
+// NewHandler создает обработчик журнала, добавляющий к записям ID запроса из контекста.
+func NewHandler(w io.Writer, level, format string) (slog.Handler, error) {
+	var parsed slog.Level
+	if err := parsed.UnmarshalText([]byte(level)); err != nil {
+		return nil, fmt.Errorf("invalid log level %q", level)
+	}
+	options := &slog.HandlerOptions{Level: parsed}
+
+	switch strings.ToLower(format) {
+	case FormatJSON:
+		return contextHandler{slog.NewJSONHandler(w, options)}, nil
+	case FormatText:
+		return contextHandler{slog.NewTextHandler(w, options)}, nil
+	default:
+		return nil, fmt.Errorf("invalid log format %q", format)
+	}
+}
+
+// contextHandler дописывает в запись ID запроса и трассы, если они есть в контексте.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if id := RequestID(ctx); id != "" {
+		record.AddAttrs(slog.String("request_id", id))
+	}
+	if span := trace.SpanContextFromContext(ctx); span.IsValid() {
+		record.AddAttrs(slog.String("trace_id", span.TraceID().String()), slog.String("span_id", span.SpanID().String()))
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,128 @@
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// HeaderRequestID - заголовок с ID запроса. Принимается от клиента, возвращается в ответе
+// и передается во внешний API.
+const HeaderRequestID = "X-Request-ID"
+
+// maxRequestIDLength ограничивает длину ID, принятого от клиента.
+const maxRequestIDLength = 128
+
+type requestIDKey struct{}
+
+// WithRequestID возвращает контекст с ID запроса.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID возвращает ID запроса из контекста или пустую строку.
+func RequestID(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// NewRequestID генерирует случайный ID запроса.
+func NewRequestID() string {
+	var id [16]byte
+	rand.Read(id[:])
+	return hex.EncodeToString(id[:])
+}
+
+// ValidRequestID допускает только короткие ID из печатных символов без пробелов,
+// чтобы клиент не мог испортить журнал.
+func ValidRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, c := range id {
+		if c <= ' ' || c > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// Middleware принимает ID запроса из X-Request-ID или генерирует новый, кладет его в контекст
+// и заголовок ответа и по завершении пишет в журнал строку о запросе.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(HeaderRequestID)
+		if !ValidRequestID(id) {
+			id = NewRequestID()
+		}
+		ctx := WithRequestID(r.Context(), id)
+		w.Header().Set(HeaderRequestID, id)
+
+		started := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r.WithContext(ctx))
+
+		route := r.URL.Path
+		if current := mux.CurrentRoute(r); current != nil {
+			if template, err := current.GetPathTemplate(); err == nil {
+				route = template
+			}
+		}
+		level := slog.LevelInfo
+		if recorder.status >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+		slog.Log(ctx, level, "HTTP request",
+			"method", r.Method,
+			"path", r.URL.Path,
+			"route", route,
+			"status", recorder.status,
+			"bytes", recorder.bytes,
+			"duration_ms", float64(time.Since(started).Microseconds())/1000,
+			"remote_addr", r.RemoteAddr,
+		)
+	})
+}
+
+// statusRecorder запоминает код и размер ответа обработчика.
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	bytes       int64
+	wroteHeader bool
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(p []byte) (int, error) {
+	r.wroteHeader = true
+	n, err := r.ResponseWriter.Write(p)
+	r.bytes += int64(n)
+	return n, err
+}
+
+// Flush передает буферизованные данные клиенту, если это поддерживает исходный ResponseWriter.
+func (r *statusRecorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap открывает исходный ResponseWriter для http.ResponseController.
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,62 @@
+package metrics
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"gorm.io/gorm"
+	"music-library/app/models"
+)
+
+// catalogTimeout ограничивает запросы к базе данных при сборе метрик каталога.
+const catalogTimeout = 5 * time.Second
+
+var (
+	songsTotalDesc = prometheus.NewDesc(namespace+"_songs_total",
+		"Songs in the catalog.", nil, nil)
+	importJobsDesc = prometheus.NewDesc(namespace+"_import_jobs",
+		"Import jobs by status.", []string{"status"}, nil)
+)
+
+// catalogCollector считает записи каталога в момент сбора метрик.
+// Если база данных недоступна, метрики каталога пропускаются, не ломая выдачу остальных.
+type catalogCollector struct {
+	db *gorm.DB
+}
+
+// RegisterCatalog регистрирует метрики каталога, которые считаются запросами к db при каждом сборе.
+func RegisterCatalog(db *gorm.DB) error {
+	return Registry.Register(catalogCollector{db: db})
+}
+
+func (c catalogCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- songsTotalDesc
+	ch <- importJobsDesc
+}
+
+func (c catalogCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), catalogTimeout)
+	defer cancel()
+	db := c.db.WithContext(ctx)
+
+	var songs int64
+	if err := db.Model(&models.Song{}).Count(&songs).Error; err != nil {
+		slog.WarnContext(ctx, "Failed to count songs for metrics", "error", err)
+	} else {
+		ch <- prometheus.MustNewConstMetric(songsTotalDesc, prometheus.GaugeValue, float64(songs))
+	}
+
+	var jobs []struct {
+		Status string
+		Count  int64
+	}
+	if err := db.Model(&models.ImportJob{}).Select("status, COUNT(*) AS count").Group("status").Scan(&jobs).Error; err != nil {
+		slog.WarnContext(ctx, "Failed to count import jobs for metrics", "error", err)
+		return
+	}
+	for _, job := range jobs {
+		ch <- prometheus.MustNewConstMetric(importJobsDesc, prometheus.GaugeValue, float64(job.Count), job.Status)
+	}
+}
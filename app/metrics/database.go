@@ -0,0 +1,99 @@
+package metrics
+
+import (
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"gorm.io/gorm"
+)
+
+// startedAtKey - ключ, под которым в Statement запоминается время начала запроса.
+const startedAtKey = "metrics:started_at"
+
+var (
+	dbQueries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "db",
+		Name:      "queries_total",
+		Help:      "Database queries by operation and table.",
+	}, []string{"operation", "table"})
+	dbQueryErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "db",
+		Name:      "query_errors_total",
+		Help:      "Failed database queries by operation and table. Record not found is not an error.",
+	}, []string{"operation", "table"})
+	dbQueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "db",
+		Name:      "query_duration_seconds",
+		Help:      "Database query latency by operation and table.",
+		Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5},
+	}, []string{"operation", "table"})
+)
+
+// InstrumentDatabase подключает к GORM учет длительности и ошибок запросов
+// и регистрирует метрики пула соединений.
+func InstrumentDatabase(db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	if err := Registry.Register(collectors.NewDBStatsCollector(sqlDB, db.Dialector.Name())); err != nil {
+		var registered prometheus.AlreadyRegisteredError
+		if !errors.As(err, &registered) {
+			return err
+		}
+	}
+
+	callbacks := db.Callback()
+	hooks := []struct {
+		operation     string
+		before, after callbackRegistrar
+	}{
+		{"create", callbacks.Create().Before("gorm:create"), callbacks.Create().After("gorm:create")},
+		{"query", callbacks.Query().Before("gorm:query"), callbacks.Query().After("gorm:query")},
+		{"update", callbacks.Update().Before("gorm:update"), callbacks.Update().After("gorm:update")},
+		{"delete", callbacks.Delete().Before("gorm:delete"), callbacks.Delete().After("gorm:delete")},
+		{"row", callbacks.Row().Before("gorm:row"), callbacks.Row().After("gorm:row")},
+		{"raw", callbacks.Raw().Before("gorm:raw"), callbacks.Raw().After("gorm:raw")},
+	}
+	for _, hook := range hooks {
+		if err := hook.before.Register("metrics:before_"+hook.operation, startQuery); err != nil {
+			return err
+		}
+		if err := hook.after.Register("metrics:after_"+hook.operation, finishQuery(hook.operation)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// callbackRegistrar - точка регистрации обработчика в цепочке колбэков GORM.
+type callbackRegistrar interface {
+	Register(name string, fn func(*gorm.DB)) error
+}
+
+func startQuery(db *gorm.DB) {
+	db.InstanceSet(startedAtKey, time.Now())
+}
+
+func finishQuery(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		value, ok := db.InstanceGet(startedAtKey)
+		if !ok {
+			return
+		}
+		table := db.Statement.Table
+		if table == "" {
+			table = "unknown"
+		}
+		dbQueries.WithLabelValues(operation, table).Inc()
+		dbQueryDuration.WithLabelValues(operation, table).Observe(time.Since(value.(time.Time)).Seconds())
+		if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+			dbQueryErrors.WithLabelValues(operation, table).Inc()
+		}
+	}
+}
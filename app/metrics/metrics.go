@@ -0,0 +1,202 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// namespace - общий префикс имен метрик сервиса.
+const namespace = "music_library"
+
+// Registry - реестр метрик сервиса, отдаваемый на /metrics.
+var Registry = prometheus.NewRegistry()
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "HTTP requests by route template, method and status code.",
+	}, []string{"route", "method", "code"})
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "HTTP request latency by route template and method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method"})
+	httpInFlight = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_in_flight",
+		Help:      "HTTP requests being served.",
+	})
+
+	rateLimited = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "rate_limited_total",
+		Help:      "Requests rejected by the rate limiter by request class.",
+	}, []string{"class"})
+
+	webhookDeliveries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "webhook",
+		Name:      "deliveries_total",
+		Help:      "Webhook delivery attempts by event and outcome.",
+	}, []string{"event", "outcome"})
+	webhookDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "webhook",
+		Name:      "delivery_duration_seconds",
+		Help:      "Webhook delivery request latency by outcome.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"outcome"})
+
+	eventSubscribers = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "events",
+		Name:      "subscribers",
+		Help:      "Clients connected to the song event stream.",
+	})
+	eventSubscribersDropped = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "events",
+		Name:      "subscribers_dropped_total",
+		Help:      "Event stream clients disconnected for not keeping up with events.",
+	})
+
+	providerRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "provider",
+		Name:      "requests_total",
+		Help:      "Metadata provider calls by outcome.",
+	}, []string{"outcome"})
+	providerDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "provider",
+		Name:      "request_duration_seconds",
+		Help:      "Metadata provider call latency by outcome.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"outcome"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests, httpDuration, httpInFlight, rateLimited,
+		dbQueries, dbQueryErrors, dbQueryDuration,
+		providerRequests, providerDuration,
+		webhookDeliveries, webhookDuration,
+		eventSubscribers, eventSubscribersDropped,
+	)
+}
+
+// Handler отдает метрики в формате Prometheus.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// Middleware считает запросы к маршрутам роутера. Путь в метках заменяется шаблоном маршрута
+// (/songs/{id}), чтобы число рядов не росло с числом записей.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := "unknown"
+		if current := mux.CurrentRoute(r); current != nil {
+			if template, err := current.GetPathTemplate(); err == nil {
+				route = template
+			}
+		}
+
+		httpInFlight.Inc()
+		defer httpInFlight.Dec()
+		started := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+
+		httpDuration.WithLabelValues(route, r.Method).Observe(time.Since(started).Seconds())
+		httpRequests.WithLabelValues(route, r.Method, strconv.Itoa(recorder.status)).Inc()
+	})
+}
+
+// Исходы обращения к внешнему API.
+const (
+	ProviderOK       = "ok"        // Данные получены
+	ProviderNotFound = "not_found" // Песня неизвестна внешнему API
+	ProviderError    = "error"     // Ошибочный ответ или неверный формат данных
+	ProviderTimeout  = "timeout"   // Превышен таймаут запроса
+	ProviderFailed   = "failed"    // Внешний API недоступен
+)
+
+// ObserveProvider учитывает обращение к внешнему API с исходом outcome.
+func ObserveProvider(outcome string, duration time.Duration) {
+	providerRequests.WithLabelValues(outcome).Inc()
+	providerDuration.WithLabelValues(outcome).Observe(duration.Seconds())
+}
+
+// ObserveRateLimited учитывает запрос класса class, отклоненный ограничителем частоты.
+func ObserveRateLimited(class string) {
+	rateLimited.WithLabelValues(class).Inc()
+}
+
+// Исходы попытки доставки события подписчику.
+const (
+	WebhookDelivered = "delivered" // Подписчик ответил кодом 2xx
+	WebhookRetry     = "retry"     // Попытка не удалась, доставка будет повторена
+	WebhookFailed    = "failed"    // Попытки исчерпаны, доставка прекращена
+)
+
+// ObserveWebhookDelivery учитывает попытку доставки события event с исходом outcome.
+func ObserveWebhookDelivery(event, outcome string, duration time.Duration) {
+	webhookDeliveries.WithLabelValues(event, outcome).Inc()
+	webhookDuration.WithLabelValues(outcome).Observe(duration.Seconds())
+}
+
+// SetEventSubscribers задает число клиентов, подключенных к потоку событий.
+func SetEventSubscribers(count int) {
+	eventSubscribers.Set(float64(count))
+}
+
+// ObserveEventSubscriberDropped учитывает клиента потока событий, отключенного из-за переполнения его очереди.
+func ObserveEventSubscriberDropped() {
+	eventSubscribersDropped.Inc()
+}
+
+// statusRecorder запоминает код ответа обработчика.
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(p []byte) (int, error) {
+	r.wroteHeader = true
+	return r.ResponseWriter.Write(p)
+}
+
+// Flush передает буферизованные данные клиенту, если это поддерживает исходный ResponseWriter.
+func (r *statusRecorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap открывает исходный ResponseWriter для http.ResponseController.
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
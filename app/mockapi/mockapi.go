@@ -0,0 +1,65 @@
+package mockapi
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"music-library/app/logging"
+	"music-library/app/models"
+)
+
+// Songs - песни, о которых знает тестовый внешний API. Ими же заполняет базу команда seed.
+var Songs = []models.Song{
+	{
+		Group:       "Muse",
+		Name:        "Supermassive Black Hole",
+		ReleaseDate: "2006-07-16",
+		Text:        "Ooh baby, don't you know I suffer?\nOoh baby, can you hear me moan?\nYou caught me under false pretenses\nHow long before you let me go?\n\nOoh\nYou set my soul alight\nOoh\nYou set my soul alight",
+		Link:        "https://www.youtube.com/watch?v=Xsp3_a-PMTw",
+	},
+	{
+		Group:       "Ласковый май",
+		Name:        "Белые розы",
+		ReleaseDate: "1988-02-16",
+		Text:        "Белые pозы, белые pозы, беззащитны шипы",
+		Link:        "https://youtu.be/CTpyz63q-6c?si=3GfsZwpV6EU8qJTk",
+	},
+}
+
+// Handler возвращает обработчик тестового внешнего API с единственным методом GET /info.
+func Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/info", info)
+	return logging.Middleware(mux)
+}
+
+func info(w http.ResponseWriter, r *http.Request) {
+	group := r.URL.Query().Get("group")
+	song := r.URL.Query().Get("song")
+
+	slog.DebugContext(r.Context(), "Received request", "group", group, "song", song)
+
+	if group == "" || song == "" {
+		slog.InfoContext(r.Context(), "Group or song is empty, returning 400 Bad Request")
+		http.Error(w, "Group or song is required", http.StatusBadRequest)
+		return
+	}
+
+	for _, known := range Songs {
+		if strings.EqualFold(known.Group, group) && strings.EqualFold(known.Name, song) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			json.NewEncoder(w).Encode(models.SongDetail{
+				ReleaseDate: known.ReleaseDate,
+				Text:        known.Text,
+				Link:        known.Link,
+			})
+			return
+		}
+	}
+
+	slog.InfoContext(r.Context(), "Song not found", "group", group, "song", song)
+	http.Error(w, "Song not found", http.StatusNotFound)
+}
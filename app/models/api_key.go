@@ -0,0 +1,101 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// APIKey описывает ключ доступа к API. Сам ключ не хранится, только его хеш.
+// @Description Ключ доступа к API
+type APIKey struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	Name       string     `json:"name" gorm:"not null"`                                        // Назначение ключа
+	Prefix     string     `json:"prefix" gorm:"not null"`                                      // Начало ключа, по которому его можно узнать
+	KeyHash    string     `json:"-" gorm:"not null;uniqueIndex"`                               // SHA-256 ключа
+	Scopes     Scopes     `json:"scopes" gorm:"type:text;not null" swaggertype:"array,string"` // Разрешения: songs:read, songs:write, songs:delete, admin
+	RateLimits RateLimits `json:"rateLimits,omitempty" gorm:"type:text"`                       // Лимиты запросов за период по классам read, write, enrich
+	ExpiresAt  *time.Time `json:"expiresAt,omitempty"`                                         // Срок действия
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`                                        // Время последнего использования
+	RevokedAt  *time.Time `json:"revokedAt,omitempty"`                                         // Время отзыва
+}
+
+// Active сообщает, можно ли пользоваться ключом в момент now.
+func (k *APIKey) Active(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
+
+// CreateAPIKeyRequest - запрос на создание ключа доступа.
+// @Description Параметры нового ключа доступа
+type CreateAPIKeyRequest struct {
+	Name       string         `json:"name"`                 // Назначение ключа
+	Scopes     []string       `json:"scopes"`               // Разрешения ключа
+	RateLimits map[string]int `json:"rateLimits,omitempty"` // Лимиты запросов вместо лимитов по умолчанию
+	ExpiresAt  *time.Time     `json:"expiresAt,omitempty"`  // Срок действия, без него ключ бессрочный
+}
+
+// CreatedAPIKey - созданный ключ доступа. Сам ключ возвращается только один раз.
+// @Description Созданный ключ доступа
+type CreatedAPIKey struct {
+	APIKey
+	Key string `json:"key"` // Ключ для заголовка Authorization: Bearer или X-API-Key
+}
+
+// Scopes - список разрешений, хранящийся в базе данных строкой через запятую.
+type Scopes []string
+
+// Value сохраняет разрешения строкой через запятую.
+func (s Scopes) Value() (driver.Value, error) {
+	return strings.Join(s, ","), nil
+}
+
+// Scan разбирает разрешения, сохраненные строкой через запятую.
+func (s *Scopes) Scan(value interface{}) error {
+	var raw string
+	switch v := value.(type) {
+	case string:
+		raw = v
+	case []byte:
+		raw = string(v)
+	case nil:
+	default:
+		return fmt.Errorf("unsupported scopes value %T", value)
+	}
+	*s = nil
+	for _, scope := range strings.Split(raw, ",") {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			*s = append(*s, scope)
+		}
+	}
+	return nil
+}
+
+// RateLimits - лимиты запросов по классам, хранящиеся в базе данных в виде JSON.
+type RateLimits map[string]int
+
+// Value сохраняет лимиты в виде JSON, пустые лимиты - как NULL.
+func (l RateLimits) Value() (driver.Value, error) {
+	if len(l) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal(map[string]int(l))
+	return string(data), err
+}
+
+// Scan разбирает лимиты, сохраненные в виде JSON.
+func (l *RateLimits) Scan(value interface{}) error {
+	*l = nil
+	switch v := value.(type) {
+	case string:
+		return json.Unmarshal([]byte(v), l)
+	case []byte:
+		return json.Unmarshal(v, l)
+	case nil:
+		return nil
+	default:
+		return fmt.Errorf("unsupported rate limits value %T", value)
+	}
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// AuditEntry - запись журнала изменений песен. Записи только добавляются.
+// @Description Запись журнала изменений
+type AuditEntry struct {
+	ID        uint         `json:"id" gorm:"primaryKey"`
+	CreatedAt time.Time    `json:"createdAt"`                                      // Время изменения
+	Actor     string       `json:"actor" gorm:"not null"`                          // Участник: user:<id>, api_key:<id> или system
+	ActorName string       `json:"actorName,omitempty"`                            // Имя пользователя или ключа доступа
+	RequestID string       `json:"requestId,omitempty"`                            // ID запроса, в котором сделано изменение
+	Operation string       `json:"operation" gorm:"not null"`                      // create, update, delete или restore
+	SongID    uint         `json:"songId" gorm:"not null"`                         // ID песни
+	Changes   FieldChanges `json:"changes" gorm:"type:jsonb" swaggertype:"object"` // Изменения полей песни
+}
+
+// TableName возвращает имя таблицы журнала изменений.
+func (AuditEntry) TableName() string {
+	return "audit_log"
+}
+
+// FieldChange - значение поля до и после изменения.
+// @Description Изменение поля
+type FieldChange struct {
+	Old interface{} `json:"old"` // Значение до изменения, null для новой песни
+	New interface{} `json:"new"` // Значение после изменения, null для удаленной песни
+}
+
+// FieldChanges - изменения полей песни по их JSON-именам, хранящиеся в базе данных в виде JSON.
+type FieldChanges map[string]FieldChange
+
+// Value сохраняет изменения в виде JSON.
+func (c FieldChanges) Value() (driver.Value, error) {
+	if c == nil {
+		c = FieldChanges{}
+	}
+	data, err := json.Marshal(map[string]FieldChange(c))
+	return string(data), err
+}
+
+// Scan разбирает изменения, сохраненные в виде JSON.
+func (c *FieldChanges) Scan(value interface{}) error {
+	*c = nil
+	switch v := value.(type) {
+	case string:
+		return json.Unmarshal([]byte(v), c)
+	case []byte:
+		return json.Unmarshal(v, c)
+	case nil:
+		return nil
+	default:
+		return fmt.Errorf("unsupported changes value %T", value)
+	}
+}
@@ -0,0 +1,17 @@
+package models
+
+// RestoreTableResult описывает восстановление одной таблицы из резервной копии.
+type RestoreTableResult struct {
+	Name    string `json:"name"`    // Имя таблицы
+	Rows    int    `json:"rows"`    // Записей в резервной копии
+	Created int    `json:"created"` // Добавлено записей
+	Skipped int    `json:"skipped"` // Пропущено записей, уже существующих в библиотеке
+}
+
+// RestoreResult описывает итог восстановления библиотеки из резервной копии.
+type RestoreResult struct {
+	Mode          string               `json:"mode"`          // empty или merge
+	SchemaVersion int                  `json:"schemaVersion"` // Версия схемы, с которой снята копия
+	Tables        []RestoreTableResult `json:"tables"`        // Результат по таблицам
+	RemappedIDs   int                  `json:"remappedIds"`   // Количество записей, получивших новый ID
+}
@@ -0,0 +1,35 @@
+package models
+
+// BatchItemResult описывает результат обработки одного элемента пакетного запроса.
+// @Description Результат обработки элемента пакета
+type BatchItemResult struct {
+	Index  int    `json:"index"`           // Индекс элемента во входных данных
+	Status string `json:"status"`          // created, duplicate, invalid, enrichment_failed, updated, deleted, not_found, conflict, ...
+	ID     uint   `json:"id,omitempty"`    // ID затронутой песни
+	Song   *Song  `json:"song,omitempty"`  // Созданная или обновленная песня
+	Error  string `json:"error,omitempty"` // Описание ошибки
+}
+
+// BatchResponse описывает ответ на пакетный запрос.
+// @Description Ответ на пакетный запрос
+type BatchResponse struct {
+	Mode      string            `json:"mode"`      // atomic или best-effort
+	Committed bool              `json:"committed"` // Были ли изменения сохранены в базе данных
+	Summary   map[string]int    `json:"summary"`   // Количество элементов по статусам
+	Results   []BatchItemResult `json:"results"`   // Результаты в порядке входных данных
+}
+
+// BatchUpdateItem описывает изменение одной песни в пакетном обновлении.
+// @Description Элемент пакетного обновления
+type BatchUpdateItem struct {
+	ID      uint   `json:"id"`      // ID песни
+	IfMatch string `json:"ifMatch"` // ETag версии, к которой применяется изменение
+	Changes Song   `json:"changes"` // Изменяемые поля песни
+}
+
+// BatchDeleteItem описывает удаление одной песни в пакетном удалении.
+// @Description Элемент пакетного удаления
+type BatchDeleteItem struct {
+	ID      uint   `json:"id"`      // ID песни
+	IfMatch string `json:"ifMatch"` // ETag удаляемой версии
+}
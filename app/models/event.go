@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// SongEvent - запись журнала событий песен. Из журнала строится поток GET /events,
+// а запись в формате JSON отправляется подписчикам как тело запроса.
+// @Description Событие песни
+type SongEvent struct {
+	ID        uint         `json:"id" gorm:"primaryKey"`                                     // ID события, растет в порядке изменений
+	CreatedAt time.Time    `json:"createdAt"`                                                // Время изменения
+	Type      string       `json:"type" gorm:"column:event;not null"`                        // song.created, song.updated, song.deleted или song.enriched
+	SongID    uint         `json:"songId" gorm:"not null"`                                   // ID песни
+	Artist    string       `json:"-" gorm:"not null"`                                        // Группа песни для фильтра потока
+	Song      RawJSON      `json:"song" gorm:"type:jsonb;not null" swaggertype:"object"`     // Песня после изменения, для song.deleted - перед удалением
+	Changes   FieldChanges `json:"changes,omitempty" gorm:"type:jsonb" swaggertype:"object"` // Изменения полей для song.updated
+}
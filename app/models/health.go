@@ -0,0 +1,54 @@
+package models
+
+import "time"
+
+// HealthCheckResult описывает результат проверки одной зависимости.
+// @Description Результат проверки зависимости
+type HealthCheckResult struct {
+	Name      string  `json:"name"`            // Имя проверки: database, migrations, provider
+	Status    string  `json:"status"`          // up или down
+	Critical  bool    `json:"critical"`        // Влияет ли отказ на готовность сервиса
+	LatencyMs float64 `json:"latencyMs"`       // Длительность проверки в миллисекундах
+	Error     string  `json:"error,omitempty"` // Причина отказа
+}
+
+// HealthResponse - ответ /healthz.
+// @Description Состояние процесса
+type HealthResponse struct {
+	Status string `json:"status"` // ok
+}
+
+// ReadinessResponse - ответ /readyz.
+// @Description Готовность сервиса принимать запросы
+type ReadinessResponse struct {
+	Status string              `json:"status"` // ready или not_ready
+	Checks []HealthCheckResult `json:"checks"` // Результаты проверок
+}
+
+// DBPoolStats описывает состояние пула соединений с базой данных.
+// @Description Статистика пула соединений
+type DBPoolStats struct {
+	MaxOpenConnections int    `json:"maxOpenConnections"` // Максимум открытых соединений
+	OpenConnections    int    `json:"openConnections"`    // Открыто соединений
+	InUse              int    `json:"inUse"`              // Занято соединений
+	Idle               int    `json:"idle"`               // Простаивает соединений
+	WaitCount          int64  `json:"waitCount"`          // Сколько раз пришлось ждать соединения
+	WaitDuration       string `json:"waitDuration"`       // Суммарное время ожидания соединения
+	MaxIdleClosed      int64  `json:"maxIdleClosed"`      // Закрыто из-за превышения числа простаивающих
+	MaxLifetimeClosed  int64  `json:"maxLifetimeClosed"`  // Закрыто из-за превышения времени жизни
+}
+
+// StatusResponse - ответ /status.
+// @Description Подробное состояние сервиса
+type StatusResponse struct {
+	Status        string              `json:"status"`             // ready или not_ready
+	Version       string              `json:"version"`            // Версия сборки
+	Revision      string              `json:"revision,omitempty"` // Ревизия VCS сборки
+	GoVersion     string              `json:"goVersion"`          // Версия Go
+	StartedAt     time.Time           `json:"startedAt"`          // Время запуска
+	Uptime        string              `json:"uptime"`             // Время работы
+	UptimeSeconds int64               `json:"uptimeSeconds"`      // Время работы в секундах
+	SchemaVersion int                 `json:"schemaVersion"`      // Версия схемы, ожидаемая сборкой
+	Database      DBPoolStats         `json:"database"`           // Пул соединений с базой данных
+	Checks        []HealthCheckResult `json:"checks"`             // Результаты проверок с задержками
+}
@@ -0,0 +1,55 @@
+package models
+
+import "time"
+
+// ImportJob описывает задание импорта каталога из файла.
+// @Description Задание импорта каталога
+type ImportJob struct {
+	MyBaseModel
+	Status        string     `json:"status" gorm:"not null;default:pending"`   // pending, running, completed, failed
+	FileName      string     `json:"fileName"`                                 // Имя исходного файла
+	Format        string     `json:"format" gorm:"not null"`                   // csv, json или ndjson
+	Mapping       string     `json:"mapping,omitempty"`                        // Сопоставление полей песни с колонками файла
+	Delimiter     string     `json:"delimiter,omitempty"`                      // Разделитель колонок CSV
+	DryRun        bool       `json:"dryRun"`                                   // Пробный запуск без изменения данных
+	Enrich        bool       `json:"enrich"`                                   // Запрашивать ли данные песен во внешнем API
+	OnDuplicate   string     `json:"onDuplicate" gorm:"not null;default:skip"` // skip или update
+	Source        []byte     `json:"-"`                                        // Содержимое файла для возобновления импорта
+	TotalRows     int        `json:"totalRows"`                                // Количество записей в файле
+	ProcessedRows int        `json:"processedRows"`                            // Количество обработанных записей
+	CreatedRows   int        `json:"createdRows"`                              // Создано песен
+	UpdatedRows   int        `json:"updatedRows"`                              // Обновлено песен
+	SkippedRows   int        `json:"skippedRows"`                              // Пропущено дубликатов
+	FailedRows    int        `json:"failedRows"`                               // Записей с ошибками
+	LastError     string     `json:"lastError,omitempty"`                      // Причина остановки задания
+	StartedAt     *time.Time `json:"startedAt,omitempty"`                      // Время запуска
+	FinishedAt    *time.Time `json:"finishedAt,omitempty"`                     // Время завершения
+}
+
+// ImportError описывает ошибку обработки одной записи импорта.
+// @Description Ошибка записи импорта
+type ImportError struct {
+	ID      uint   `json:"id" gorm:"primaryKey"`
+	JobID   uint   `json:"jobId" gorm:"index;not null"`     // ID задания импорта
+	Row     int    `json:"row" gorm:"column:record_number"` // Номер записи в файле (с единицы, без заголовка)
+	Message string `json:"message"`                         // Описание ошибки
+	Raw     string `json:"raw"`                             // Исходное содержимое записи
+}
+
+// ImportRowResult описывает действие, выполненное или запланированное для одной записи импорта.
+// @Description Результат обработки записи импорта
+type ImportRowResult struct {
+	Row    int    `json:"row"`              // Номер записи в файле
+	Action string `json:"action"`           // created, updated, skipped или failed
+	SongID uint   `json:"songId,omitempty"` // ID существующей или созданной песни
+	Group  string `json:"group,omitempty"`  // Группа
+	Name   string `json:"song,omitempty"`   // Название песни
+	Error  string `json:"error,omitempty"`  // Описание ошибки
+}
+
+// ImportReport описывает задание импорта вместе с результатами по записям (для пробного запуска).
+// @Description Отчет об импорте
+type ImportReport struct {
+	Job  ImportJob         `json:"job"`            // Задание импорта
+	Rows []ImportRowResult `json:"rows,omitempty"` // Результаты по записям
+}
@@ -1,7 +1,10 @@
 package models
 
 import (
+	"fmt"
 	"time"
+
+	"gorm.io/gorm"
 )
 
 // MyBaseModel добавляет стандартные поля для других моделей.
@@ -15,12 +18,41 @@ type MyBaseModel struct {
 // Song представляет песню в системе.
 // @Description Структура песни
 type Song struct {
-	MyBaseModel        // Включает поля ID, CreatedAt, UpdatedAt и DeletedAt
-	Group       string `json:"group" gorm:"column:artist;not null"` // Группа или исполнитель
-	Name        string `json:"song" gorm:"not null"`                // Название песни
-	ReleaseDate string `json:"releaseDate"`                         // Дата релиза
-	Text        string `json:"text"`                                // Текст песни
-	Link        string `json:"link"`                                // Ссылка на песню
+	MyBaseModel            // Включает поля ID, CreatedAt, UpdatedAt и DeletedAt
+	Group       string     `json:"group" gorm:"column:artist;not null"` // Группа или исполнитель
+	Name        string     `json:"song" gorm:"not null"`                // Название песни
+	ReleaseDate string     `json:"releaseDate"`                         // Дата релиза
+	Text        string     `json:"text"`                                // Текст песни
+	Link        string     `json:"link"`                                // Ссылка на песню
+	Album       string     `json:"album,omitempty"`                     // Альбом
+	FilePath    string     `json:"filePath,omitempty" gorm:"index"`     // Путь к локальному аудиофайлу
+	FileSize    int64      `json:"fileSize,omitempty"`                  // Размер аудиофайла в байтах
+	FileModTime *time.Time `json:"fileModTime,omitempty"`               // Время изменения аудиофайла при последнем сканировании
+	ContentHash string     `json:"contentHash,omitempty" gorm:"index"`  // SHA-256 содержимого аудиофайла
+	Duration    int        `json:"duration,omitempty"`                  // Длительность в секундах
+	Bitrate     int        `json:"bitrate,omitempty"`                   // Битрейт в кбит/с
+	Version     uint       `json:"version" gorm:"not null;default:1"`   // Версия записи для оптимистичной блокировки
+	CreatedBy   *uint      `json:"createdBy,omitempty"`                 // ID пользователя, создавшего песню
+	UpdatedBy   *uint      `json:"updatedBy,omitempty"`                 // ID пользователя, последним изменившего песню
+	ETag        string     `json:"etag,omitempty" gorm:"-"`             // ETag текущей версии записи
+	Enriched    bool       `json:"-" gorm:"-"`                          // Данные получены из внешнего API при создании
+}
+
+// ComputeETag возвращает ETag песни, построенный по ее ID и версии.
+func (s *Song) ComputeETag() string {
+	return fmt.Sprintf(`"%d-%d"`, s.ID, s.Version)
+}
+
+// AfterFind заполняет ETag у песен, загруженных из базы данных.
+func (s *Song) AfterFind(tx *gorm.DB) error {
+	s.ETag = s.ComputeETag()
+	return nil
+}
+
+// AfterSave обновляет ETag после создания или изменения песни.
+func (s *Song) AfterSave(tx *gorm.DB) error {
+	s.ETag = s.ComputeETag()
+	return nil
 }
 
 // SongDetail содержит дополнительные детали о песне.
@@ -0,0 +1,33 @@
+package models
+
+// PlaylistSuggestion описывает песню каталога, похожую на несопоставленную запись плейлиста.
+// @Description Предлагаемая песня для записи плейлиста
+type PlaylistSuggestion struct {
+	SongID uint    `json:"songId"` // ID песни
+	Group  string  `json:"group"`  // Группа
+	Name   string  `json:"song"`   // Название песни
+	Score  float64 `json:"score"`  // Степень сходства от 0 до 1
+}
+
+// PlaylistEntryMatch описывает результат сопоставления записи плейлиста с каталогом.
+// @Description Запись импортированного плейлиста
+type PlaylistEntryMatch struct {
+	Index       int                  `json:"index"`                 // Порядковый номер записи в плейлисте
+	Group       string               `json:"group,omitempty"`       // Исполнитель из плейлиста
+	Name        string               `json:"song,omitempty"`        // Название из плейлиста
+	Location    string               `json:"location,omitempty"`    // Ссылка или путь из плейлиста
+	Duration    int                  `json:"duration,omitempty"`    // Длительность в секундах
+	Matched     bool                 `json:"matched"`               // Найдена ли песня в каталоге
+	SongID      uint                 `json:"songId,omitempty"`      // ID найденной песни
+	Suggestions []PlaylistSuggestion `json:"suggestions,omitempty"` // Похожие песни для несопоставленной записи
+}
+
+// PlaylistImportResult описывает результат импорта плейлиста.
+// @Description Результат импорта плейлиста
+type PlaylistImportResult struct {
+	Format    string               `json:"format"`    // m3u8 или xspf
+	Total     int                  `json:"total"`     // Количество записей в плейлисте
+	Matched   int                  `json:"matched"`   // Количество сопоставленных записей
+	Unmatched int                  `json:"unmatched"` // Количество несопоставленных записей
+	Entries   []PlaylistEntryMatch `json:"entries"`   // Записи в порядке плейлиста
+}
@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// SongRevision - сохраненное содержимое песни на момент одной из ее версий.
+// @Description Ревизия песни
+type SongRevision struct {
+	ID          uint      `json:"-" gorm:"primaryKey"`
+	SongID      uint      `json:"songId" gorm:"not null"`     // ID песни
+	Revision    uint      `json:"revision" gorm:"not null"`   // Номер ревизии, совпадает с версией песни
+	CreatedAt   time.Time `json:"createdAt"`                  // Время создания ревизии
+	Actor       string    `json:"actor" gorm:"not null"`      // Участник: user:<id>, api_key:<id> или system
+	ActorName   string    `json:"actorName,omitempty"`        // Имя пользователя или ключа доступа
+	Group       string    `json:"group" gorm:"column:artist"` // Группа или исполнитель
+	Name        string    `json:"song"`                       // Название песни
+	ReleaseDate string    `json:"releaseDate"`                // Дата релиза
+	Text        string    `json:"text"`                       // Текст песни
+	Link        string    `json:"link"`                       // Ссылка на песню
+	Album       string    `json:"album,omitempty"`            // Альбом
+}
+
+// RevisionDiff - построчный diff текста песни между двумя ревизиями.
+// @Description Diff текста между ревизиями
+type RevisionDiff struct {
+	SongID uint       `json:"songId"` // ID песни
+	From   uint       `json:"from"`   // Исходная ревизия
+	To     uint       `json:"to"`     // Конечная ревизия
+	Hunks  []DiffHunk `json:"hunks"`  // Фрагменты с изменениями, пустой список - тексты совпадают
+}
+
+// DiffHunk - фрагмент diff в духе unified diff.
+// @Description Фрагмент diff
+type DiffHunk struct {
+	OldStart int      `json:"oldStart"` // Первая строка фрагмента в исходной ревизии, с единицы
+	OldLines int      `json:"oldLines"` // Количество строк фрагмента в исходной ревизии
+	NewStart int      `json:"newStart"` // Первая строка фрагмента в конечной ревизии, с единицы
+	NewLines int      `json:"newLines"` // Количество строк фрагмента в конечной ревизии
+	Lines    []string `json:"lines"`    // Строки с префиксом " " (без изменений), "-" (удалена) или "+" (добавлена)
+}
@@ -0,0 +1,20 @@
+package models
+
+// ScanFileResult описывает результат обработки одного аудиофайла при сканировании.
+type ScanFileResult struct {
+	Path   string `json:"path"`             // Путь к файлу или к каталогу, который не удалось прочитать
+	Action string `json:"action"`           // created, updated, moved, unchanged, skipped или failed
+	SongID uint   `json:"songId,omitempty"` // ID созданной или обновленной песни
+	Error  string `json:"error,omitempty"`  // Описание ошибки
+}
+
+// ScanSummary содержит итоги сканирования каталога с аудиофайлами.
+type ScanSummary struct {
+	Scanned   int `json:"scanned"`   // Найдено аудиофайлов
+	Created   int `json:"created"`   // Создано песен
+	Updated   int `json:"updated"`   // Обновлено песен
+	Moved     int `json:"moved"`     // Найдено перемещенных файлов
+	Unchanged int `json:"unchanged"` // Файлов без изменений
+	Skipped   int `json:"skipped"`   // Пропущено дубликатов
+	Failed    int `json:"failed"`    // Файлов и каталогов с ошибками
+}
@@ -0,0 +1,62 @@
+package models
+
+import "time"
+
+// User описывает учетную запись пользователя.
+// @Description Пользователь
+type User struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	CreatedAt    time.Time `json:"createdAt"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+	Username     string    `json:"username" gorm:"not null;uniqueIndex"` // Имя для входа
+	PasswordHash string    `json:"-" gorm:"not null"`                    // Хеш пароля bcrypt
+	Role         string    `json:"role" gorm:"not null"`                 // viewer, editor или admin
+}
+
+// RefreshToken описывает выданный пользователю токен обновления. Сам токен не хранится, только его хеш.
+type RefreshToken struct {
+	ID        uint `gorm:"primaryKey"`
+	CreatedAt time.Time
+	UserID    uint       `gorm:"not null;index"`
+	TokenHash string     `gorm:"not null;uniqueIndex"` // SHA-256 токена
+	ExpiresAt time.Time  `gorm:"not null"`             // Срок действия
+	RevokedAt *time.Time // Время обмена на новый токен
+}
+
+// Credentials - имя пользователя и пароль для регистрации или входа.
+// @Description Учетные данные пользователя
+type Credentials struct {
+	Username string `json:"username"` // Имя для входа
+	Password string `json:"password"` // Пароль, не короче 8 символов
+}
+
+// RefreshRequest - запрос на обновление токенов или выход.
+// @Description Токен обновления
+type RefreshRequest struct {
+	RefreshToken string `json:"refreshToken"` // Токен обновления, полученный при входе
+}
+
+// TokenResponse - токены, выданные при входе или обновлении.
+// @Description Токены пользователя
+type TokenResponse struct {
+	AccessToken  string `json:"accessToken"`  // Токен доступа для заголовка Authorization: Bearer
+	TokenType    string `json:"tokenType"`    // Всегда Bearer
+	ExpiresIn    int    `json:"expiresIn"`    // Время жизни токена доступа в секундах
+	RefreshToken string `json:"refreshToken"` // Одноразовый токен обновления
+}
+
+// UpdateUserRequest - изменение роли пользователя.
+// @Description Новая роль пользователя
+type UpdateUserRequest struct {
+	Role string `json:"role"` // viewer, editor или admin
+}
+
+// Identity описывает участника, от имени которого выполняется запрос.
+// @Description Текущий участник запроса
+type Identity struct {
+	Type   string   `json:"type"`           // anonymous, api_key или user
+	ID     uint     `json:"id,omitempty"`   // ID ключа доступа или пользователя
+	Name   string   `json:"name,omitempty"` // Имя ключа доступа или пользователя
+	Role   string   `json:"role,omitempty"` // Роль пользователя
+	Scopes []string `json:"scopes"`         // Разрешения
+}
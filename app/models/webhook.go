@@ -0,0 +1,100 @@
+package models
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"time"
+)
+
+// Webhook - подписка внешней системы на события песен.
+// @Description Подписка на события
+type Webhook struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+	URL         string    `json:"url" gorm:"not null"`                                         // Адрес, на который отправляются события
+	Secret      string    `json:"-" gorm:"not null"`                                           // Секрет подписи HMAC-SHA256
+	Events      Scopes    `json:"events" gorm:"type:text;not null" swaggertype:"array,string"` // События: song.created, song.updated, song.deleted, song.enriched
+	Active      bool      `json:"active" gorm:"not null"`                                      // Отправлять ли события
+	Description string    `json:"description,omitempty"`                                       // Назначение подписки
+}
+
+// WebhookRequest - параметры новой подписки или изменения существующей.
+// При изменении пустые поля не меняются.
+// @Description Параметры подписки на события
+type WebhookRequest struct {
+	URL         string   `json:"url"`                   // Адрес http или https
+	Events      []string `json:"events"`                // События подписки
+	Active      *bool    `json:"active,omitempty"`      // Отправлять ли события, по умолчанию true
+	Description *string  `json:"description,omitempty"` // Назначение подписки
+	Secret      string   `json:"secret,omitempty"`      // Секрет подписи, по умолчанию генерируется
+}
+
+// CreatedWebhook - созданная подписка. Секрет подписи возвращается только один раз.
+// @Description Созданная подписка на события
+type CreatedWebhook struct {
+	Webhook
+	Secret string `json:"secret"` // Секрет для проверки заголовка X-Webhook-Signature
+}
+
+// WebhookDelivery - доставка одного события одной подписке.
+// @Description Доставка события
+type WebhookDelivery struct {
+	ID             uint             `json:"id" gorm:"primaryKey"`
+	CreatedAt      time.Time        `json:"createdAt"`
+	WebhookID      uint             `json:"webhookId" gorm:"not null"`                               // ID подписки
+	EventID        string           `json:"eventId" gorm:"not null"`                                 // ID события в журнале событий, общий для всех подписок
+	Event          string           `json:"event" gorm:"not null"`                                   // Тип события
+	SongID         uint             `json:"songId,omitempty"`                                        // ID песни
+	Payload        RawJSON          `json:"payload" gorm:"type:jsonb;not null" swaggertype:"object"` // Тело запроса к подписчику
+	Status         string           `json:"status" gorm:"not null"`                                  // pending, delivered или failed
+	Attempts       int              `json:"attempts" gorm:"not null;default:0"`                      // Сделано попыток
+	NextAttemptAt  *time.Time       `json:"nextAttemptAt,omitempty"`                                 // Время следующей попытки
+	LastAttemptAt  *time.Time       `json:"lastAttemptAt,omitempty"`                                 // Время последней попытки
+	ResponseStatus int              `json:"responseStatus,omitempty"`                                // Код ответа на последнюю попытку
+	LastError      string           `json:"lastError,omitempty"`                                     // Ошибка последней попытки
+	DeliveredAt    *time.Time       `json:"deliveredAt,omitempty"`                                   // Время успешной доставки
+	Log            []WebhookAttempt `json:"log,omitempty" gorm:"foreignKey:DeliveryID"`              // Попытки доставки, только для одной доставки
+}
+
+// WebhookAttempt - запись журнала попыток доставки.
+// @Description Попытка доставки события
+type WebhookAttempt struct {
+	ID             uint      `json:"-" gorm:"primaryKey"`
+	CreatedAt      time.Time `json:"createdAt"`                  // Время попытки
+	DeliveryID     uint      `json:"-" gorm:"not null"`          // ID доставки
+	ResponseStatus int       `json:"responseStatus,omitempty"`   // Код ответа подписчика
+	Error          string    `json:"error,omitempty"`            // Ошибка запроса или текст неуспешного ответа
+	DurationMs     int64     `json:"durationMs" gorm:"not null"` // Длительность запроса в миллисекундах
+}
+
+// RawJSON - готовый JSON, хранящийся в базе данных как есть.
+type RawJSON []byte
+
+// MarshalJSON возвращает JSON без изменений.
+func (j RawJSON) MarshalJSON() ([]byte, error) {
+	if len(j) == 0 {
+		return []byte("null"), nil
+	}
+	return j, nil
+}
+
+// Value сохраняет JSON строкой, чтобы драйвер не передавал его как bytea.
+func (j RawJSON) Value() (driver.Value, error) {
+	return string(j), nil
+}
+
+// Scan читает сохраненный JSON.
+func (j *RawJSON) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case string:
+		*j = RawJSON(v)
+	case []byte:
+		*j = append(RawJSON(nil), v...)
+	case nil:
+		*j = nil
+	default:
+		return fmt.Errorf("unsupported JSON value %T", value)
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+package playlist
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"music-library/app/models"
+)
+
+// suggestionThreshold задает минимальную степень сходства, при которой песня предлагается для записи.
+const suggestionThreshold = 0.5
+
+// songKey содержит нормализованные исполнителя и название песни.
+type songKey struct {
+	artist, title string
+}
+
+// Candidate описывает песню каталога, с которой сопоставляются записи плейлиста.
+type Candidate struct {
+	ID       uint
+	Artist   string
+	Title    string
+	Link     string
+	FilePath string
+}
+
+// Normalize приводит название к виду для сравнения: нижний регистр, без пояснений в скобках,
+// пунктуации и лишних пробелов.
+func Normalize(value string) string {
+	var builder strings.Builder
+	depth := 0
+	space := false
+	for _, r := range strings.ToLower(value) {
+		switch {
+		case r == '(' || r == '[':
+			depth++
+			continue
+		case r == ')' || r == ']':
+			if depth > 0 {
+				depth--
+			}
+			continue
+		case depth > 0:
+			continue
+		case r == 'ё':
+			r = 'е'
+		case r == '&':
+			r = ' '
+		}
+
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if space && builder.Len() > 0 {
+				builder.WriteByte(' ')
+			}
+			builder.WriteRune(r)
+			space = false
+		} else {
+			space = true
+		}
+	}
+	return builder.String()
+}
+
+// MatchEntries сопоставляет записи плейлиста с песнями каталога по ссылке или по нормализованным
+// исполнителю и названию. Для несопоставленных записей предлагается до maxSuggestions похожих песен.
+func MatchEntries(entries []Entry, candidates []Candidate, maxSuggestions int) []models.PlaylistEntryMatch {
+	byKey := make(map[songKey]uint, len(candidates))
+	byTitle := make(map[string][]uint)
+	byLink := make(map[string]uint, len(candidates))
+	keys := make([]songKey, len(candidates))
+
+	for i, candidate := range candidates {
+		key := songKey{Normalize(candidate.Artist), Normalize(candidate.Title)}
+		keys[i] = key
+		if _, ok := byKey[key]; !ok {
+			byKey[key] = candidate.ID
+		}
+		byTitle[key.title] = append(byTitle[key.title], candidate.ID)
+		byLink[SongURI(candidate.ID)] = candidate.ID
+		if candidate.Link != "" {
+			byLink[candidate.Link] = candidate.ID
+		}
+		if candidate.FilePath != "" {
+			byLink[candidate.FilePath] = candidate.ID
+		}
+	}
+
+	matches := make([]models.PlaylistEntryMatch, len(entries))
+	for i, entry := range entries {
+		match := models.PlaylistEntryMatch{
+			Index:    i,
+			Group:    entry.Artist,
+			Name:     entry.Title,
+			Location: entry.Location,
+		}
+		if entry.Duration > 0 {
+			match.Duration = entry.Duration
+		}
+
+		key := songKey{Normalize(entry.Artist), Normalize(entry.Title)}
+		if id, ok := byLink[entry.Location]; ok && entry.Location != "" {
+			match.Matched, match.SongID = true, id
+		} else if id, ok := byKey[key]; ok && key.artist != "" {
+			match.Matched, match.SongID = true, id
+		} else if ids := byTitle[key.title]; key.artist == "" && key.title != "" && len(ids) == 1 {
+			match.Matched, match.SongID = true, ids[0]
+		}
+
+		if !match.Matched && key.title != "" {
+			match.Suggestions = suggest(key.artist, key.title, candidates, keys, maxSuggestions)
+		}
+		matches[i] = match
+	}
+	return matches
+}
+
+// suggest подбирает самые похожие песни каталога по расстоянию Левенштейна.
+func suggest(artist, title string, candidates []Candidate, keys []songKey, limit int) []models.PlaylistSuggestion {
+	var suggestions []models.PlaylistSuggestion
+	for i, candidate := range candidates {
+		score := similarity(title, keys[i].title)
+		if artist != "" {
+			score = (score + similarity(artist, keys[i].artist)) / 2
+		}
+		if score < suggestionThreshold {
+			continue
+		}
+		suggestions = append(suggestions, models.PlaylistSuggestion{
+			SongID: candidate.ID,
+			Group:  candidate.Artist,
+			Name:   candidate.Title,
+			Score:  float64(int(score*100+0.5)) / 100,
+		})
+	}
+
+	sort.SliceStable(suggestions, func(a, b int) bool {
+		return suggestions[a].Score > suggestions[b].Score
+	})
+	if len(suggestions) > limit {
+		suggestions = suggestions[:limit]
+	}
+	return suggestions
+}
+
+// similarity возвращает степень сходства строк от 0 до 1 на основе расстояния Левенштейна.
+func similarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+func levenshtein(a, b []rune) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
@@ -0,0 +1,254 @@
+package playlist
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Поддерживаемые форматы плейлистов.
+const (
+	FormatM3U8 = "m3u8"
+	FormatXSPF = "xspf"
+)
+
+// SongURIPrefix задает адрес записи для песни без ссылки, чтобы плейлист оставался корректным
+// и при обратном импорте запись сопоставлялась с той же песней.
+const SongURIPrefix = "music-library:songs/"
+
+// SongURI возвращает адрес записи плейлиста для песни без ссылки.
+func SongURI(id uint) string {
+	return SongURIPrefix + strconv.FormatUint(uint64(id), 10)
+}
+
+// ParseSongURI возвращает ID песни из адреса записи, построенного SongURI.
+func ParseSongURI(uri string) (uint, bool) {
+	value, ok := strings.CutPrefix(uri, SongURIPrefix)
+	if !ok {
+		return 0, false
+	}
+	id, err := strconv.ParseUint(value, 10, 64)
+	if err != nil || id == 0 {
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// Entry описывает одну запись плейлиста.
+type Entry struct {
+	Title    string // Название песни
+	Artist   string // Группа или исполнитель
+	Album    string // Альбом
+	Location string // Ссылка на песню или путь к файлу
+	Duration int    // Длительность в секундах, -1 если неизвестна
+}
+
+// ValidFormat проверяет, поддерживается ли формат плейлиста.
+func ValidFormat(format string) bool {
+	return format == FormatM3U8 || format == FormatXSPF
+}
+
+// DetectFormat определяет формат плейлиста по имени файла или, если его нет, по содержимому.
+func DetectFormat(fileName string, content []byte) string {
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".m3u", ".m3u8":
+		return FormatM3U8
+	case ".xspf":
+		return FormatXSPF
+	}
+
+	trimmed := strings.TrimSpace(strings.TrimPrefix(string(content), "\ufeff"))
+	if strings.HasPrefix(trimmed, "<") {
+		return FormatXSPF
+	}
+	return FormatM3U8
+}
+
+// ContentType возвращает MIME-тип плейлиста.
+func ContentType(format string) string {
+	if format == FormatXSPF {
+		return "application/xspf+xml"
+	}
+	return "application/vnd.apple.mpegurl"
+}
+
+// Write записывает плейлист в указанном формате.
+func Write(w io.Writer, format, title string, entries []Entry) error {
+	switch format {
+	case FormatM3U8:
+		return writeM3U8(w, title, entries)
+	case FormatXSPF:
+		return writeXSPF(w, title, entries)
+	}
+	return fmt.Errorf("unsupported playlist format %q", format)
+}
+
+// Parse читает записи плейлиста в указанном формате.
+func Parse(r io.Reader, format string) ([]Entry, error) {
+	switch format {
+	case FormatM3U8:
+		return parseM3U8(r)
+	case FormatXSPF:
+		return parseXSPF(r)
+	}
+	return nil, fmt.Errorf("unsupported playlist format %q", format)
+}
+
+// writeM3U8 записывает расширенный M3U в кодировке UTF-8.
+func writeM3U8(w io.Writer, title string, entries []Entry) error {
+	out := bufio.NewWriter(w)
+	out.WriteString("#EXTM3U\n")
+	if title != "" {
+		fmt.Fprintf(out, "#PLAYLIST:%s\n", singleLine(title))
+	}
+	for _, entry := range entries {
+		duration := entry.Duration
+		if duration <= 0 {
+			duration = -1
+		}
+		fmt.Fprintf(out, "#EXTINF:%d,%s\n", duration, singleLine(displayName(entry)))
+		if entry.Album != "" {
+			fmt.Fprintf(out, "#EXTALB:%s\n", singleLine(entry.Album))
+		}
+		fmt.Fprintf(out, "%s\n", singleLine(entry.Location))
+	}
+	return out.Flush()
+}
+
+func parseM3U8(r io.Reader) ([]Entry, error) {
+	var entries []Entry
+	current := Entry{Duration: -1}
+	hasInfo := false
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(strings.TrimPrefix(scanner.Text(), "\ufeff"))
+		switch {
+		case line == "":
+			continue
+		case strings.HasPrefix(line, "#EXTINF:"):
+			info := strings.TrimPrefix(line, "#EXTINF:")
+			durationPart, name, _ := strings.Cut(info, ",")
+			// После длительности могут идти атрибуты вида key="value", они игнорируются.
+			durationPart, _, _ = strings.Cut(durationPart, " ")
+			if duration, err := strconv.ParseFloat(durationPart, 64); err == nil && duration > 0 {
+				current.Duration = int(duration + 0.5)
+			}
+			current.Artist, current.Title = splitDisplayName(name)
+			hasInfo = true
+		case strings.HasPrefix(line, "#EXTALB:"):
+			current.Album = strings.TrimSpace(strings.TrimPrefix(line, "#EXTALB:"))
+		case strings.HasPrefix(line, "#EXTART:"):
+			current.Artist = strings.TrimSpace(strings.TrimPrefix(line, "#EXTART:"))
+		case strings.HasPrefix(line, "#"):
+			continue
+		default:
+			current.Location = line
+			if !hasInfo {
+				current.Artist, current.Title = splitDisplayName(titleFromLocation(line))
+			}
+			entries = append(entries, current)
+			current = Entry{Duration: -1}
+			hasInfo = false
+		}
+	}
+	return entries, scanner.Err()
+}
+
+type xspfPlaylist struct {
+	XMLName   xml.Name    `xml:"http://xspf.org/ns/0/ playlist"`
+	Version   string      `xml:"version,attr"`
+	Title     string      `xml:"title,omitempty"`
+	TrackList []xspfTrack `xml:"trackList>track"`
+}
+
+type xspfTrack struct {
+	Location string `xml:"location,omitempty"`
+	Title    string `xml:"title,omitempty"`
+	Creator  string `xml:"creator,omitempty"`
+	Album    string `xml:"album,omitempty"`
+	Duration int    `xml:"duration,omitempty"` // В миллисекундах
+}
+
+func writeXSPF(w io.Writer, title string, entries []Entry) error {
+	document := xspfPlaylist{Version: "1", Title: title}
+	for _, entry := range entries {
+		track := xspfTrack{Location: entry.Location, Title: entry.Title, Creator: entry.Artist, Album: entry.Album}
+		if entry.Duration > 0 {
+			track.Duration = entry.Duration * 1000
+		}
+		document.TrackList = append(document.TrackList, track)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(document); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func parseXSPF(r io.Reader) ([]Entry, error) {
+	var document xspfPlaylist
+	if err := xml.NewDecoder(r).Decode(&document); err != nil {
+		return nil, fmt.Errorf("invalid XSPF playlist: %w", err)
+	}
+
+	entries := make([]Entry, 0, len(document.TrackList))
+	for _, track := range document.TrackList {
+		entry := Entry{
+			Title:    strings.TrimSpace(track.Title),
+			Artist:   strings.TrimSpace(track.Creator),
+			Album:    strings.TrimSpace(track.Album),
+			Location: strings.TrimSpace(track.Location),
+			Duration: -1,
+		}
+		if track.Duration > 0 {
+			entry.Duration = (track.Duration + 500) / 1000
+		}
+		if entry.Title == "" && entry.Location != "" {
+			entry.Artist, entry.Title = splitDisplayName(titleFromLocation(entry.Location))
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// displayName возвращает название записи в виде "Исполнитель - Название".
+func displayName(entry Entry) string {
+	if entry.Artist == "" {
+		return entry.Title
+	}
+	return entry.Artist + " - " + entry.Title
+}
+
+// splitDisplayName разбирает строку вида "Исполнитель - Название".
+func splitDisplayName(name string) (artist, title string) {
+	name = strings.TrimSpace(name)
+	if artist, title, ok := strings.Cut(name, " - "); ok {
+		return strings.TrimSpace(artist), strings.TrimSpace(title)
+	}
+	return "", name
+}
+
+// titleFromLocation извлекает название из имени файла, если в плейлисте нет метаданных.
+func titleFromLocation(location string) string {
+	if strings.Contains(location, "://") && !strings.HasPrefix(location, "file://") {
+		return ""
+	}
+	base := filepath.Base(strings.TrimPrefix(location, "file://"))
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
+
+func singleLine(value string) string {
+	return strings.Join(strings.Fields(value), " ")
+}
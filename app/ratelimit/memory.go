@@ -0,0 +1,50 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryStore хранит корзины токенов в памяти процесса.
+type MemoryStore struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	swept   time.Time
+	now     func() time.Time
+}
+
+// NewMemoryStore создает хранилище корзин в памяти.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: make(map[string]*bucket), now: time.Now}
+}
+
+// sweepInterval - как часто из памяти удаляются корзины клиентов, переставших присылать запросы.
+const sweepInterval = time.Minute
+
+// Take берет токен из корзины key, создавая полную корзину для нового клиента.
+func (s *MemoryStore) Take(_ context.Context, key string, limit Limit) (Result, error) {
+	now := s.now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now.Sub(s.swept) > sweepInterval {
+		s.sweep(now, limit.Window)
+	}
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Requests), updated: now}
+		s.buckets[key] = b
+	}
+	return b.take(limit, now), nil
+}
+
+// sweep удаляет корзины, которые не использовались дольше idle и поэтому уже полны.
+func (s *MemoryStore) sweep(now time.Time, idle time.Duration) {
+	for key, b := range s.buckets {
+		if now.Sub(b.updated) > idle {
+			delete(s.buckets, key)
+		}
+	}
+	s.swept = now
+}
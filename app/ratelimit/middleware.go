@@ -0,0 +1,202 @@
+package ratelimit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"music-library/app/auth"
+	"music-library/app/config"
+	"music-library/app/metrics"
+	"music-library/app/models"
+)
+
+// Заголовки ответа с состоянием лимита.
+const (
+	HeaderLimit     = "RateLimit-Limit"
+	HeaderRemaining = "RateLimit-Remaining"
+	HeaderReset     = "RateLimit-Reset"
+	HeaderPolicy    = "RateLimit-Policy"
+)
+
+// exemptRoutes - маршруты проверок состояния, метрик и документации, которые не ограничиваются.
+var exemptRoutes = map[string]bool{
+	"/healthz":  true,
+	"/readyz":   true,
+	"/status":   true,
+	"/metrics":  true,
+	"/swagger/": true,
+}
+
+// Classify возвращает класс запроса: запросы, которые обращаются к внешнему API за данными песен,
+// ограничиваются отдельно от остальных изменений.
+func Classify(r *http.Request, template string) string {
+	switch {
+	case r.Method == http.MethodGet || r.Method == http.MethodHead:
+		return ClassRead
+	case r.Method == http.MethodPost && (template == "/songs" || template == "/songs:batch"):
+		return ClassEnrich
+	case r.Method == http.MethodPost && template == "/imports":
+		if enrich, _ := strconv.ParseBool(r.URL.Query().Get("enrich")); enrich {
+			return ClassEnrich
+		}
+	}
+	return ClassWrite
+}
+
+// Middleware ограничивает частоту запросов клиента корзиной токенов отдельно для каждого класса запросов.
+// Клиент определяется ключом доступа или пользователем, а для запросов без учетных данных - IP-адресом,
+// поэтому middleware подключается после auth.Middleware.
+func Middleware(store Store) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			settings := config.Get().RateLimit
+			template := ""
+			if route := mux.CurrentRoute(r); route != nil {
+				template, _ = route.GetPathTemplate()
+			}
+			if !settings.Enabled || exemptRoutes[template] {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			class := Classify(r, template)
+			principal := auth.PrincipalFrom(r.Context())
+			client := ClientKey(principal, clientIP(r, settings.TrustProxy))
+			limit := Limit{Requests: LimitFor(settings, principal, class), Window: settings.Window}
+			key := class + ":" + client
+			r = r.WithContext(context.WithValue(r.Context(), chargerKey{}, &charger{store: store, principal: principal, client: client}))
+
+			result, err := store.Take(r.Context(), key, limit)
+			if err != nil {
+				// Недоступность общего хранилища не должна останавливать API.
+				slog.WarnContext(r.Context(), "Rate limit store failed, request allowed", "error", err)
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			header := w.Header()
+			header.Set(HeaderLimit, strconv.Itoa(limit.Requests))
+			header.Set(HeaderRemaining, strconv.Itoa(result.Remaining))
+			header.Set(HeaderReset, strconv.Itoa(CeilSeconds(result.Reset)))
+			header.Set(HeaderPolicy, fmt.Sprintf("%d;w=%d", limit.Requests, CeilSeconds(limit.Window)))
+			if result.Allowed {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			retryAfter := CeilSeconds(result.RetryAfter)
+			slog.InfoContext(r.Context(), "Rate limit exceeded", "class", class, "client", key, "retry_after", retryAfter)
+			metrics.ObserveRateLimited(class)
+			header.Set("Retry-After", strconv.Itoa(retryAfter))
+			header.Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusTooManyRequests)
+			json.NewEncoder(w).Encode(models.ErrorResponse{
+				Code:    http.StatusTooManyRequests,
+				Message: fmt.Sprintf("Rate limit for %s requests exceeded, retry in %d seconds", class, retryAfter),
+			})
+		})
+	}
+}
+
+// chargerKey - ключ контекста запроса, в котором Middleware передает корзины клиента обработчику.
+type chargerKey struct{}
+
+// charger берет токены из корзин клиента запроса.
+type charger struct {
+	store     Store
+	principal *auth.Principal
+	client    string
+}
+
+// LimitError возвращается Charge, если лимит класса исчерпан.
+type LimitError struct {
+	Class      string        // Класс запросов
+	RetryAfter time.Duration // Через сколько появится токен
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("Rate limit for %s requests exceeded, retry in %d seconds", e.Class, CeilSeconds(e.RetryAfter))
+}
+
+// Charge дополнительно берет токен класса class у клиента запроса. Нужен обработчикам, которые выполняют
+// несколько операций за один запрос, например мутации GraphQL: каждая операция с обращением к внешнему API
+// расходует лимит enrich так же, как отдельный POST /songs. Если лимиты выключены или запрос не прошел
+// через Middleware, возвращает nil.
+func Charge(ctx context.Context, class string) error {
+	c, ok := ctx.Value(chargerKey{}).(*charger)
+	settings := config.Get().RateLimit
+	if !ok || !settings.Enabled {
+		return nil
+	}
+
+	key := class + ":" + c.client
+	result, err := c.store.Take(ctx, key, Limit{Requests: LimitFor(settings, c.principal, class), Window: settings.Window})
+	if err != nil {
+		slog.WarnContext(ctx, "Rate limit store failed, request allowed", "error", err)
+		return nil
+	}
+	if result.Allowed {
+		return nil
+	}
+	slog.InfoContext(ctx, "Rate limit exceeded", "class", class, "client", key, "retry_after", CeilSeconds(result.RetryAfter))
+	metrics.ObserveRateLimited(class)
+	return &LimitError{Class: class, RetryAfter: result.RetryAfter}
+}
+
+// LimitFor возвращает лимит класса для участника: индивидуальный лимит ключа доступа или лимит из конфигурации.
+func LimitFor(settings config.RateLimitConfig, principal *auth.Principal, class string) int {
+	if principal != nil {
+		if limit, ok := principal.RateLimits[class]; ok {
+			return limit
+		}
+	}
+	switch class {
+	case ClassRead:
+		return settings.Read
+	case ClassEnrich:
+		return settings.Enrich
+	default:
+		return settings.Write
+	}
+}
+
+// ClientKey определяет клиента запроса по участнику, а для запроса без учетных данных - по IP-адресу ip.
+// Ключ не зависит от API, поэтому клиент расходует одни и те же корзины в HTTP и gRPC API.
+func ClientKey(principal *auth.Principal, ip string) string {
+	if principal != nil && principal.Type != auth.PrincipalAnonymous {
+		return principal.Type + ":" + strconv.FormatUint(uint64(principal.ID), 10)
+	}
+	return "ip:" + ip
+}
+
+// clientIP возвращает адрес клиента. X-Forwarded-For учитывается, только если сервис стоит за доверенным прокси,
+// иначе клиент мог бы обходить лимит, подставляя произвольный адрес.
+func clientIP(r *http.Request, trustProxy bool) string {
+	if trustProxy {
+		if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+			first, _, _ := strings.Cut(forwarded, ",")
+			if ip := strings.TrimSpace(first); ip != "" {
+				return ip
+			}
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// CeilSeconds округляет длительность вверх до целых секунд.
+func CeilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
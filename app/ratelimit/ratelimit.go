@@ -0,0 +1,84 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// Классы запросов с отдельными лимитами.
+const (
+	ClassRead   = "read"   // Чтение
+	ClassWrite  = "write"  // Изменение без обращения к внешнему API
+	ClassEnrich = "enrich" // Изменение с запросом данных песен во внешнем API
+)
+
+// Classes перечисляет все классы запросов.
+var Classes = []string{ClassRead, ClassWrite, ClassEnrich}
+
+// ValidClass сообщает, известен ли класс запросов.
+func ValidClass(class string) bool {
+	for _, known := range Classes {
+		if known == class {
+			return true
+		}
+	}
+	return false
+}
+
+// Limit - емкость корзины и время ее полного наполнения: не больше Requests запросов за Window.
+type Limit struct {
+	Requests int
+	Window   time.Duration
+}
+
+// rate возвращает скорость пополнения корзины в токенах в секунду.
+func (l Limit) rate() float64 {
+	return float64(l.Requests) / l.Window.Seconds()
+}
+
+// Result - исход попытки взять токен из корзины.
+type Result struct {
+	Allowed    bool          // Запрос разрешен
+	Remaining  int           // Токенов осталось после запроса
+	Reset      time.Duration // Через сколько корзина наполнится полностью
+	RetryAfter time.Duration // Через сколько появится токен, если запрос отклонен
+}
+
+// Store хранит корзины токенов. Хранилище в памяти подходит для одного экземпляра сервиса,
+// для нескольких экземпляров нужна реализация поверх общего хранилища, например Redis.
+type Store interface {
+	// Take берет токен из корзины key с лимитом limit.
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// bucket - корзина токенов.
+type bucket struct {
+	tokens  float64   // Токенов в корзине на момент updated
+	updated time.Time // Время последнего пополнения
+}
+
+// take пополняет корзину на момент now и пытается взять из нее токен.
+func (b *bucket) take(limit Limit, now time.Time) Result {
+	capacity := float64(limit.Requests)
+	rate := limit.rate()
+	if elapsed := now.Sub(b.updated).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(capacity, b.tokens+elapsed*rate)
+	}
+	b.updated = now
+
+	result := Result{Allowed: b.tokens >= 1}
+	if result.Allowed {
+		b.tokens--
+	} else {
+		result.RetryAfter = seconds((1 - b.tokens) / rate)
+	}
+	result.Remaining = int(b.tokens)
+	result.Reset = seconds((capacity - b.tokens) / rate)
+	return result
+}
+
+// seconds переводит число секунд в time.Duration.
+func seconds(value float64) time.Duration {
+	return time.Duration(value * float64(time.Second))
+}
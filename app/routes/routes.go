@@ -3,18 +3,77 @@ package routes
 import (
 	"github.com/gorilla/mux"
 	"github.com/swaggo/http-swagger"
+	"music-library/app/auth"
 	"music-library/app/controllers"
+	"music-library/app/database"
+	"music-library/app/logging"
+	"music-library/app/metrics"
+	"music-library/app/ratelimit"
+	"music-library/app/services"
+	"music-library/app/tracing"
 	_ "music-library/docs"
 )
 
-func RegisterRoutes() *mux.Router {
+func RegisterRoutes(limits ratelimit.Store) *mux.Router {
 	router := mux.NewRouter()
+	router.Use(tracing.Middleware, logging.Middleware, metrics.Middleware)
+	router.Use(auth.Middleware(services.Authenticator(database.DB)), ratelimit.Middleware(limits))
+
+	router.HandleFunc("/healthz", controllers.Healthz).Methods("GET")
+	router.HandleFunc("/readyz", controllers.Readyz).Methods("GET")
+	router.HandleFunc("/status", controllers.Status).Methods("GET")
+	router.Handle("/metrics", metrics.Handler()).Methods("GET")
+
+	router.HandleFunc("/auth/register", controllers.Register).Methods("POST")
+	router.HandleFunc("/auth/login", controllers.Login).Methods("POST")
+	router.HandleFunc("/auth/refresh", controllers.RefreshToken).Methods("POST")
+	router.HandleFunc("/auth/logout", controllers.Logout).Methods("POST")
+	router.HandleFunc("/auth/me", controllers.GetIdentity).Methods("GET")
 
 	router.HandleFunc("/songs", controllers.GetSongs).Methods("GET")
-	router.HandleFunc("/songs/{id}/text", controllers.GetSongTextWithPagination).Methods("GET")
+	router.HandleFunc("/songs/export", controllers.ExportSongs).Methods("GET")
+	router.HandleFunc("/songs/playlist", controllers.ExportPlaylist).Methods("GET")
+	router.HandleFunc("/songs/{id:[0-9]+}", controllers.GetSong).Methods("GET")
+	router.HandleFunc("/songs/{id:[0-9]+}/text", controllers.GetSongTextWithPagination).Methods("GET")
+	router.HandleFunc("/songs/{id}/history", controllers.GetSongHistory).Methods("GET")
+	router.HandleFunc("/songs/{id}/revisions", controllers.GetSongRevisions).Methods("GET")
+	router.HandleFunc("/songs/{id}/revisions/diff", controllers.DiffSongRevisions).Methods("GET")
+	router.HandleFunc("/songs/{id}/revisions/{rev:[0-9]+}", controllers.GetSongRevision).Methods("GET")
+	router.HandleFunc("/songs/{id}/revisions/{rev:[0-9]+}/revert", controllers.RevertSong).Methods("POST")
 	router.HandleFunc("/songs", controllers.AddSong).Methods("POST")
-	router.HandleFunc("/songs/{id}", controllers.UpdateSong).Methods("PUT")
-	router.HandleFunc("/songs/{id}", controllers.DeleteSong).Methods("DELETE")
+	router.HandleFunc("/songs:batch", controllers.CreateSongsBatch).Methods("POST")
+	router.HandleFunc("/songs:batch", controllers.UpdateSongsBatch).Methods("PUT")
+	router.HandleFunc("/songs:batch", controllers.DeleteSongsBatch).Methods("DELETE")
+	router.HandleFunc("/songs/{id:[0-9]+}", controllers.UpdateSong).Methods("PUT", "PATCH")
+	router.HandleFunc("/songs/{id:[0-9]+}", controllers.DeleteSong).Methods("DELETE")
+
+	router.HandleFunc("/imports", controllers.CreateImport).Methods("POST")
+	router.HandleFunc("/imports/{id}", controllers.GetImport).Methods("GET")
+	router.HandleFunc("/imports/{id}/errors", controllers.GetImportErrors).Methods("GET")
+	router.HandleFunc("/imports/{id}/resume", controllers.ResumeImport).Methods("POST")
+
+	router.HandleFunc("/audit", controllers.GetAudit).Methods("GET")
+	router.HandleFunc("/events", controllers.StreamEvents).Methods("GET")
+	router.HandleFunc("/graphql", controllers.GraphQL).Methods("GET", "POST")
+
+	router.HandleFunc("/webhooks", controllers.CreateWebhook).Methods("POST")
+	router.HandleFunc("/webhooks", controllers.GetWebhooks).Methods("GET")
+	router.HandleFunc("/webhooks/{id}", controllers.GetWebhook).Methods("GET")
+	router.HandleFunc("/webhooks/{id}", controllers.UpdateWebhook).Methods("PATCH")
+	router.HandleFunc("/webhooks/{id}", controllers.DeleteWebhook).Methods("DELETE")
+	router.HandleFunc("/webhooks/{id}/deliveries", controllers.GetWebhookDeliveries).Methods("GET")
+	router.HandleFunc("/webhooks/{id}/deliveries/{deliveryId}", controllers.GetWebhookDelivery).Methods("GET")
+	router.HandleFunc("/webhooks/{id}/deliveries/{deliveryId}/redeliver", controllers.RedeliverWebhook).Methods("POST")
+
+	router.HandleFunc("/playlists/import", controllers.ImportPlaylist).Methods("POST")
+
+	router.HandleFunc("/admin/backup", controllers.GetBackup).Methods("GET")
+	router.HandleFunc("/admin/restore", controllers.RestoreBackup).Methods("POST")
+	router.HandleFunc("/admin/api-keys", controllers.CreateAPIKey).Methods("POST")
+	router.HandleFunc("/admin/api-keys", controllers.GetAPIKeys).Methods("GET")
+	router.HandleFunc("/admin/api-keys/{id}", controllers.RevokeAPIKey).Methods("DELETE")
+	router.HandleFunc("/admin/users", controllers.GetUsers).Methods("GET")
+	router.HandleFunc("/admin/users/{id}", controllers.UpdateUser).Methods("PATCH")
 
 	router.PathPrefix("/swagger/").Handler(httpSwagger.WrapHandler)
 
@@ -0,0 +1,246 @@
+package scanner
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// errUnknownAudio возвращается, если не удалось определить параметры аудиопотока.
+var errUnknownAudio = errors.New("unrecognized audio stream")
+
+// audioProperties содержит параметры аудиопотока файла.
+type audioProperties struct {
+	Duration int // Длительность в секундах
+	Bitrate  int // Средний битрейт в кбит/с
+}
+
+// readAudioProperties определяет длительность и битрейт MP3, FLAC или Ogg (Vorbis, Opus) файла.
+func readAudioProperties(r io.ReadSeeker, size int64) (audioProperties, error) {
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return audioProperties{}, err
+	}
+	start, err := skipID3v2(r)
+	if err != nil {
+		return audioProperties{}, err
+	}
+
+	magic := make([]byte, 4)
+	if _, err := io.ReadFull(r, magic); err != nil {
+		return audioProperties{}, err
+	}
+	if _, err := r.Seek(start, io.SeekStart); err != nil {
+		return audioProperties{}, err
+	}
+
+	var props audioProperties
+	switch string(magic) {
+	case "fLaC":
+		props, err = readFLACProperties(r)
+	case "OggS":
+		props, err = readOggProperties(r, size)
+	default:
+		props, err = readMP3Properties(r, start, size)
+	}
+	if err != nil {
+		return props, err
+	}
+
+	if props.Bitrate == 0 && props.Duration > 0 {
+		props.Bitrate = int((size - start) * 8 / int64(props.Duration) / 1000)
+	}
+	return props, nil
+}
+
+// skipID3v2 пропускает тег ID3v2 в начале файла и возвращает смещение аудиоданных.
+func skipID3v2(r io.ReadSeeker) (int64, error) {
+	header := make([]byte, 10)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return 0, err
+	}
+	if string(header[:3]) != "ID3" {
+		_, err := r.Seek(0, io.SeekStart)
+		return 0, err
+	}
+
+	size := int64(header[6])<<21 | int64(header[7])<<14 | int64(header[8])<<7 | int64(header[9])
+	offset := 10 + size
+	if header[5]&0x10 != 0 {
+		offset += 10
+	}
+	_, err := r.Seek(offset, io.SeekStart)
+	return offset, err
+}
+
+// readFLACProperties читает блок STREAMINFO.
+func readFLACProperties(r io.Reader) (audioProperties, error) {
+	header := make([]byte, 4+4+34)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return audioProperties{}, err
+	}
+	if header[4]&0x7f != 0 {
+		return audioProperties{}, errUnknownAudio
+	}
+
+	info := header[8:]
+	sampleRate := int64(info[10])<<12 | int64(info[11])<<4 | int64(info[12])>>4
+	totalSamples := int64(info[13]&0x0f)<<32 | int64(binary.BigEndian.Uint32(info[14:18]))
+	if sampleRate == 0 {
+		return audioProperties{}, errUnknownAudio
+	}
+	return audioProperties{Duration: int((totalSamples + sampleRate/2) / sampleRate)}, nil
+}
+
+// readOggProperties определяет частоту дискретизации по заголовку кодека и длительность
+// по позиции гранулы последней страницы.
+func readOggProperties(r io.ReadSeeker, size int64) (audioProperties, error) {
+	page := make([]byte, 27+255+64)
+	n, err := io.ReadFull(r, page)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return audioProperties{}, err
+	}
+	page = page[:n]
+	if len(page) < 28 || len(page) < 27+int(page[26]) {
+		return audioProperties{}, errUnknownAudio
+	}
+	packet := page[27+int(page[26]):]
+
+	var props audioProperties
+	var sampleRate, preSkip int64
+	switch {
+	case bytes.HasPrefix(packet, []byte("\x01vorbis")) && len(packet) >= 24:
+		sampleRate = int64(binary.LittleEndian.Uint32(packet[12:16]))
+		props.Bitrate = int(int32(binary.LittleEndian.Uint32(packet[20:24])) / 1000)
+	case bytes.HasPrefix(packet, []byte("OpusHead")) && len(packet) >= 12:
+		// Позиция гранулы в Opus всегда отсчитывается с частотой 48 кГц.
+		sampleRate = 48000
+		preSkip = int64(binary.LittleEndian.Uint16(packet[10:12]))
+	default:
+		return props, errUnknownAudio
+	}
+	if props.Bitrate < 0 {
+		props.Bitrate = 0
+	}
+
+	tailSize := int64(64 * 1024)
+	if tailSize > size {
+		tailSize = size
+	}
+	if _, err := r.Seek(size-tailSize, io.SeekStart); err != nil {
+		return props, err
+	}
+	tail := make([]byte, tailSize)
+	if _, err := io.ReadFull(r, tail); err != nil {
+		return props, err
+	}
+
+	last := bytes.LastIndex(tail, []byte("OggS"))
+	if last < 0 || last+14 > len(tail) || sampleRate == 0 {
+		return props, errUnknownAudio
+	}
+	granule := int64(binary.LittleEndian.Uint64(tail[last+6 : last+14]))
+	if granule > preSkip {
+		props.Duration = int((granule - preSkip + sampleRate/2) / sampleRate)
+	}
+	return props, nil
+}
+
+var (
+	mpegSampleRates = map[byte][3]int{
+		3: {44100, 48000, 32000}, // MPEG 1
+		2: {22050, 24000, 16000}, // MPEG 2
+		0: {11025, 12000, 8000},  // MPEG 2.5
+	}
+	mpeg1Bitrates = map[byte][15]int{
+		3: {0, 32, 64, 96, 128, 160, 192, 224, 256, 288, 320, 352, 384, 416, 448}, // Layer I
+		2: {0, 32, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 384},    // Layer II
+		1: {0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320},     // Layer III
+	}
+	mpeg2Bitrates = map[byte][15]int{
+		3: {0, 32, 48, 56, 64, 80, 96, 112, 128, 144, 160, 176, 192, 224, 256}, // Layer I
+		2: {0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160},      // Layer II
+		1: {0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160},      // Layer III
+	}
+)
+
+// readMP3Properties находит первый MPEG-кадр и определяет длительность по заголовку Xing/Info/VBRI,
+// а при его отсутствии - по постоянному битрейту.
+func readMP3Properties(r io.Reader, start, size int64) (audioProperties, error) {
+	buffer := make([]byte, 64*1024)
+	n, err := io.ReadFull(r, buffer)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return audioProperties{}, err
+	}
+	buffer = buffer[:n]
+
+	for i := 0; i+4 <= len(buffer); i++ {
+		if buffer[i] != 0xff || buffer[i+1]&0xe0 != 0xe0 {
+			continue
+		}
+		version := buffer[i+1] >> 3 & 0x03
+		layer := buffer[i+1] >> 1 & 0x03
+		bitrateIndex := buffer[i+2] >> 4
+		sampleRateIndex := buffer[i+2] >> 2 & 0x03
+		channelMode := buffer[i+3] >> 6
+		if version == 1 || layer == 0 || bitrateIndex == 0 || bitrateIndex == 15 || sampleRateIndex == 3 {
+			continue
+		}
+
+		sampleRate := mpegSampleRates[version][sampleRateIndex]
+		bitrate := mpeg2Bitrates[layer][bitrateIndex]
+		if version == 3 {
+			bitrate = mpeg1Bitrates[layer][bitrateIndex]
+		}
+
+		samplesPerFrame := 1152
+		switch {
+		case layer == 3:
+			samplesPerFrame = 384
+		case layer == 1 && version != 3:
+			samplesPerFrame = 576
+		}
+
+		if frames := vbrFrameCount(buffer[i:], version, channelMode); frames > 0 {
+			duration := int64(frames) * int64(samplesPerFrame) / int64(sampleRate)
+			props := audioProperties{Duration: int(duration)}
+			if duration > 0 {
+				props.Bitrate = int((size - start - int64(i)) * 8 / duration / 1000)
+			}
+			return props, nil
+		}
+
+		audioSize := size - start - int64(i)
+		return audioProperties{
+			Duration: int(audioSize * 8 / int64(bitrate*1000)),
+			Bitrate:  bitrate,
+		}, nil
+	}
+	return audioProperties{}, errUnknownAudio
+}
+
+// vbrFrameCount возвращает количество кадров из заголовка Xing/Info или VBRI первого кадра.
+func vbrFrameCount(frame []byte, version, channelMode byte) uint32 {
+	sideInfo := 17
+	switch {
+	case version == 3 && channelMode != 3:
+		sideInfo = 32
+	case version != 3 && channelMode == 3:
+		sideInfo = 9
+	}
+
+	xing := 4 + sideInfo
+	if len(frame) >= xing+12 {
+		tag := string(frame[xing : xing+4])
+		flags := binary.BigEndian.Uint32(frame[xing+4 : xing+8])
+		if (tag == "Xing" || tag == "Info") && flags&0x01 != 0 {
+			return binary.BigEndian.Uint32(frame[xing+8 : xing+12])
+		}
+	}
+
+	vbri := 4 + 32
+	if len(frame) >= vbri+18 && string(frame[vbri:vbri+4]) == "VBRI" {
+		return binary.BigEndian.Uint32(frame[vbri+14 : vbri+18])
+	}
+	return 0
+}
@@ -0,0 +1,147 @@
+package scanner
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dhowden/tag"
+)
+
+// supportedExtensions перечисляет расширения аудиофайлов, которые обрабатывает сканер.
+var supportedExtensions = map[string]bool{
+	".mp3":  true,
+	".flac": true,
+	".ogg":  true,
+	".oga":  true,
+	".opus": true,
+}
+
+// File описывает аудиофайл вместе с метаданными из его тегов.
+type File struct {
+	Path     string    // Абсолютный путь к файлу
+	Size     int64     // Размер в байтах
+	ModTime  time.Time // Время последнего изменения
+	Hash     string    // SHA-256 содержимого
+	Artist   string    // Исполнитель
+	Title    string    // Название
+	Album    string    // Альбом
+	Year     int       // Год выпуска
+	Lyrics   string    // Текст песни из тега несинхронизированной лирики
+	Duration int       // Длительность в секундах, 0 если не удалось определить
+	Bitrate  int       // Битрейт в кбит/с, 0 если не удалось определить
+}
+
+// IsAudioFile сообщает, обрабатывает ли сканер файл с таким именем.
+func IsAudioFile(path string) bool {
+	return supportedExtensions[strings.ToLower(filepath.Ext(path))]
+}
+
+// Walk обходит дерево каталогов и вызывает fn для каждого поддерживаемого аудиофайла.
+// Если файл или каталог внутри root не удалось прочитать, fn получает ошибку вместо info,
+// а обход продолжается без этого каталога. Ошибка чтения самого root возвращается сразу.
+func Walk(root string, fn func(path string, info fs.FileInfo, err error) error) error {
+	return filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if path == root {
+				return err
+			}
+			if err := fn(path, nil, err); err != nil {
+				return err
+			}
+			if entry != nil && entry.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if entry.IsDir() || !IsAudioFile(path) {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return fn(path, nil, err)
+		}
+		return fn(path, info, nil)
+	})
+}
+
+// ReadFile читает теги, параметры аудиопотока и хеш содержимого файла.
+// Если в тегах нет исполнителя или названия, они берутся из имени файла вида "Исполнитель - Название".
+func ReadFile(path string) (*File, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	result := &File{Path: path, Size: info.Size(), ModTime: info.ModTime()}
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return nil, err
+	}
+	result.Hash = hex.EncodeToString(hash.Sum(nil))
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	if metadata, err := tag.ReadFrom(file); err == nil {
+		result.Artist = strings.TrimSpace(metadata.Artist())
+		if result.Artist == "" {
+			result.Artist = strings.TrimSpace(metadata.AlbumArtist())
+		}
+		result.Title = strings.TrimSpace(metadata.Title())
+		result.Album = strings.TrimSpace(metadata.Album())
+		result.Year = metadata.Year()
+		result.Lyrics = lyrics(metadata)
+	}
+
+	if props, err := readAudioProperties(file, info.Size()); err == nil {
+		result.Duration = props.Duration
+		result.Bitrate = props.Bitrate
+	}
+
+	if result.Artist == "" || result.Title == "" {
+		base := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		if artist, title, ok := strings.Cut(base, " - "); ok {
+			if result.Artist == "" {
+				result.Artist = strings.TrimSpace(artist)
+			}
+			if result.Title == "" {
+				result.Title = strings.TrimSpace(title)
+			}
+		} else if result.Title == "" {
+			result.Title = strings.TrimSpace(base)
+		}
+	}
+	return result, nil
+}
+
+// ReleaseDate возвращает год выпуска в виде строки для Song.ReleaseDate.
+func (f *File) ReleaseDate() string {
+	if f.Year <= 0 {
+		return ""
+	}
+	return strconv.Itoa(f.Year)
+}
+
+// lyrics возвращает текст из тега несинхронизированной лирики (USLT в ID3v2, LYRICS/UNSYNCEDLYRICS в Vorbis).
+func lyrics(metadata tag.Metadata) string {
+	if text := strings.TrimSpace(metadata.Lyrics()); text != "" {
+		return text
+	}
+	if value, ok := metadata.Raw()["unsyncedlyrics"].(string); ok {
+		return strings.TrimSpace(value)
+	}
+	return ""
+}
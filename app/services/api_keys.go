@@ -0,0 +1,136 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"music-library/app/auth"
+	"music-library/app/models"
+	"music-library/app/ratelimit"
+)
+
+// apiKeyPrefix начинает каждый ключ доступа, чтобы его было легко узнать в конфигурации и журналах.
+const apiKeyPrefix = "mlk_"
+
+// apiKeyVisibleLength - длина начала ключа, которое хранится открыто для опознания.
+const apiKeyVisibleLength = len(apiKeyPrefix) + 8
+
+// apiKeyTouchInterval - не чаще этого интервала обновляется время последнего использования ключа.
+const apiKeyTouchInterval = time.Minute
+
+var (
+	// ErrAPIKeyNotFound возвращается, если ключ доступа с указанным ID отсутствует.
+	ErrAPIKeyNotFound = errors.New("API key not found")
+	// ErrInvalidAPIKey возвращается, если у ключа нет имени или разрешений, разрешение неизвестно
+	// или лимит запросов задан неверно.
+	ErrInvalidAPIKey = errors.New("API key must have a name, known scopes and positive read, write or enrich rate limits")
+)
+
+// CreateAPIKey создает ключ доступа и возвращает его вместе с самим ключом, который больше нигде не сохраняется.
+func CreateAPIKey(db *gorm.DB, request models.CreateAPIKeyRequest) (models.CreatedAPIKey, error) {
+	name := strings.TrimSpace(request.Name)
+	if name == "" || len(request.Scopes) == 0 {
+		return models.CreatedAPIKey{}, ErrInvalidAPIKey
+	}
+	for _, scope := range request.Scopes {
+		if !auth.ValidScope(scope) {
+			return models.CreatedAPIKey{}, ErrInvalidAPIKey
+		}
+	}
+	for class, limit := range request.RateLimits {
+		if !ratelimit.ValidClass(class) || limit <= 0 {
+			return models.CreatedAPIKey{}, ErrInvalidAPIKey
+		}
+	}
+
+	var secret [24]byte
+	if _, err := rand.Read(secret[:]); err != nil {
+		return models.CreatedAPIKey{}, err
+	}
+	key := apiKeyPrefix + base64.RawURLEncoding.EncodeToString(secret[:])
+
+	created := models.CreatedAPIKey{
+		APIKey: models.APIKey{
+			Name:       name,
+			Prefix:     key[:apiKeyVisibleLength],
+			KeyHash:    hashAPIKey(key),
+			Scopes:     request.Scopes,
+			RateLimits: request.RateLimits,
+			ExpiresAt:  request.ExpiresAt,
+		},
+		Key: key,
+	}
+	if err := db.Create(&created.APIKey).Error; err != nil {
+		return models.CreatedAPIKey{}, err
+	}
+	slog.InfoContext(db.Statement.Context, "Created API key", "api_key_id", created.ID, "name", name, "scopes", request.Scopes)
+	return created, nil
+}
+
+// ListAPIKeys возвращает все ключи доступа, включая отозванные.
+func ListAPIKeys(db *gorm.DB) ([]models.APIKey, error) {
+	var keys []models.APIKey
+	err := db.Order("id").Find(&keys).Error
+	return keys, err
+}
+
+// RevokeAPIKey отзывает ключ доступа. Повторный отзыв не меняет время отзыва.
+func RevokeAPIKey(db *gorm.DB, id interface{}) (models.APIKey, error) {
+	var key models.APIKey
+	if err := db.First(&key, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return key, ErrAPIKeyNotFound
+		}
+		return key, err
+	}
+	if key.RevokedAt != nil {
+		return key, nil
+	}
+
+	now := time.Now()
+	if err := db.Model(&key).UpdateColumn("revoked_at", now).Error; err != nil {
+		return key, err
+	}
+	key.RevokedAt = &now
+	slog.InfoContext(db.Statement.Context, "Revoked API key", "api_key_id", key.ID, "name", key.Name)
+	return key, nil
+}
+
+// AuthenticateAPIKey находит действующий ключ доступа и возвращает участника запроса с его разрешениями.
+func AuthenticateAPIKey(db *gorm.DB, key string) (*auth.Principal, error) {
+	if !strings.HasPrefix(key, apiKeyPrefix) {
+		return nil, auth.ErrUnauthenticated
+	}
+
+	var stored models.APIKey
+	if err := db.Where("key_hash = ?", hashAPIKey(key)).First(&stored).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, auth.ErrUnauthenticated
+		}
+		return nil, err
+	}
+	now := time.Now()
+	if !stored.Active(now) {
+		return nil, auth.ErrUnauthenticated
+	}
+
+	if stored.LastUsedAt == nil || now.Sub(*stored.LastUsedAt) > apiKeyTouchInterval {
+		if err := db.Model(&stored).UpdateColumn("last_used_at", now).Error; err != nil {
+			slog.WarnContext(db.Statement.Context, "Failed to record API key usage", "api_key_id", stored.ID, "error", err)
+		}
+	}
+	return &auth.Principal{Type: auth.PrincipalAPIKey, ID: stored.ID, Name: stored.Name, Scopes: stored.Scopes, RateLimits: stored.RateLimits}, nil
+}
+
+// hashAPIKey возвращает SHA-256 ключа или токена. Они случайны и длинны, поэтому медленный хеш не нужен.
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,197 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+	"music-library/app/auth"
+	"music-library/app/logging"
+	"music-library/app/models"
+)
+
+// Операции в журнале изменений.
+const (
+	AuditCreate  = "create"
+	AuditUpdate  = "update"
+	AuditDelete  = "delete"
+	AuditRestore = "restore"
+)
+
+// AuditOperations перечисляет все операции журнала изменений.
+var AuditOperations = []string{AuditCreate, AuditUpdate, AuditDelete, AuditRestore}
+
+// auditSystemActor - участник изменений, сделанных не через API: командами CLI и фоновыми задачами без участника.
+const auditSystemActor = "system"
+
+// auditIgnoredFields - служебные поля песни, изменения которых не записываются в журнал.
+var auditIgnoredFields = map[string]bool{
+	"id":        true,
+	"createdAt": true,
+	"updatedAt": true,
+	"version":   true,
+	"etag":      true,
+	"createdBy": true,
+	"updatedBy": true,
+}
+
+// ErrInvalidAuditFilter возвращается для неизвестной операции в фильтре журнала.
+var ErrInvalidAuditFilter = errors.New("operation must be create, update, delete or restore")
+
+// AuditFilter описывает фильтры и пагинацию журнала изменений.
+type AuditFilter struct {
+	Actor     string     // Участник: user:<id>, api_key:<id> или system
+	Operation string     // Операция
+	SongID    uint       // ID песни
+	From      *time.Time // Изменения не раньше этого времени
+	To        *time.Time // Изменения раньше этого времени
+	Limit     int        // Максимальное количество записей, 0 - без ограничения
+	Offset    int        // Количество пропускаемых записей
+}
+
+// ListAudit возвращает записи журнала изменений от новых к старым.
+func ListAudit(db *gorm.DB, filter AuditFilter) ([]models.AuditEntry, error) {
+	if filter.Operation != "" && !validAuditOperation(filter.Operation) {
+		return nil, ErrInvalidAuditFilter
+	}
+
+	query := db.Order("id DESC")
+	if filter.Actor != "" {
+		query = query.Where("actor = ?", filter.Actor)
+	}
+	if filter.Operation != "" {
+		query = query.Where("operation = ?", filter.Operation)
+	}
+	if filter.SongID != 0 {
+		query = query.Where("song_id = ?", filter.SongID)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", *filter.To)
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+	if filter.Offset > 0 {
+		query = query.Offset(filter.Offset)
+	}
+
+	entries := []models.AuditEntry{}
+	err := query.Find(&entries).Error
+	return entries, err
+}
+
+// SongHistory возвращает историю изменений песни от новых записей к старым.
+// История удаленной песни остается доступной, ErrSongNotFound возвращается, только если песни никогда не было.
+func SongHistory(db *gorm.DB, id uint, limit, offset int) ([]models.AuditEntry, error) {
+	entries, err := ListAudit(db, AuditFilter{SongID: id, Limit: limit, Offset: offset})
+	if err != nil || len(entries) > 0 || offset > 0 {
+		return entries, err
+	}
+	if _, err := GetSong(db, id); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// recordAudit записывает изменение песни в журнал. Вызывается в транзакции изменения,
+// чтобы запись в журнале и само изменение сохранялись или откатывались вместе.
+// before равен nil для новой песни, after - для удаленной.
+func recordAudit(tx *gorm.DB, operation string, before, after *models.Song) error {
+	entry, err := newAuditEntry(tx, operation, before, after)
+	if err != nil {
+		return err
+	}
+	return tx.Create(&entry).Error
+}
+
+// newAuditEntry собирает запись журнала об изменении песни от имени участника запроса.
+func newAuditEntry(tx *gorm.DB, operation string, before, after *models.Song) (models.AuditEntry, error) {
+	changes, err := diffSongs(before, after)
+	if err != nil {
+		return models.AuditEntry{}, err
+	}
+
+	entry := models.AuditEntry{
+		RequestID: logging.RequestID(tx.Statement.Context),
+		Operation: operation,
+		Changes:   changes,
+	}
+	entry.Actor, entry.ActorName = auditActor(tx)
+	if after != nil {
+		entry.SongID = after.ID
+	} else if before != nil {
+		entry.SongID = before.ID
+	}
+	return entry, nil
+}
+
+// auditActor возвращает участника, от имени которого выполняется запрос к базе данных, и его имя.
+func auditActor(db *gorm.DB) (string, string) {
+	principal := auth.PrincipalFrom(db.Statement.Context)
+	if principal == nil || principal.Type == auth.PrincipalAnonymous {
+		return auditSystemActor, ""
+	}
+	return principal.Type + ":" + strconv.FormatUint(uint64(principal.ID), 10), principal.Name
+}
+
+// diffSongs сравнивает песни по JSON-полям и возвращает изменившиеся поля.
+func diffSongs(before, after *models.Song) (models.FieldChanges, error) {
+	old, err := songFields(before)
+	if err != nil {
+		return nil, err
+	}
+	current, err := songFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := models.FieldChanges{}
+	for name, value := range current {
+		if !reflect.DeepEqual(old[name], value) {
+			changes[name] = models.FieldChange{Old: old[name], New: value}
+		}
+	}
+	for name, value := range old {
+		if _, ok := current[name]; !ok {
+			changes[name] = models.FieldChange{Old: value, New: nil}
+		}
+	}
+	return changes, nil
+}
+
+// songFields возвращает непустые поля песни по их JSON-именам без служебных полей.
+func songFields(song *models.Song) (map[string]interface{}, error) {
+	fields := make(map[string]interface{})
+	if song == nil {
+		return fields, nil
+	}
+	data, err := json.Marshal(song)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	for name, value := range fields {
+		if auditIgnoredFields[name] || value == nil || value == "" {
+			delete(fields, name)
+		}
+	}
+	return fields, nil
+}
+
+// validAuditOperation сообщает, известна ли операция журнала.
+func validAuditOperation(operation string) bool {
+	for _, known := range AuditOperations {
+		if known == operation {
+			return true
+		}
+	}
+	return false
+}
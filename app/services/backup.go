@@ -0,0 +1,524 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"music-library/app/backup"
+	"music-library/app/database"
+	"music-library/app/models"
+)
+
+// Режимы восстановления из резервной копии.
+const (
+	RestoreModeEmpty = "empty" // Только в пустую базу, с сохранением исходных ID
+	RestoreModeMerge = "merge" // Слияние с существующей библиотекой, записи получают новые ID
+)
+
+// Таблицы резервной копии.
+const (
+	backupTableUsers             = "users"
+	backupTableRefreshTokens     = "refresh_tokens"
+	backupTableAPIKeys           = "api_keys"
+	backupTableSongs             = "songs"
+	backupTableImportJobs        = "import_jobs"
+	backupTableImportErrors      = "import_errors"
+	backupTableRevisions         = "song_revisions"
+	backupTableAudit             = "audit_log"
+	backupTableEvents            = "song_events"
+	backupTableWebhooks          = "webhooks"
+	backupTableWebhookDeliveries = "webhook_deliveries"
+	backupTableWebhookAttempts   = "webhook_attempts"
+)
+
+// restoreBatchSize - количество записей в одном INSERT при восстановлении в пустую базу.
+const restoreBatchSize = 500
+
+var (
+	ErrInvalidRestoreMode = errors.New("restore mode must be empty or merge")
+	ErrDatabaseNotEmpty   = errors.New("database is not empty, use merge mode")
+	ErrUnsupportedSchema  = errors.New("backup was made with a newer database schema")
+	ErrInvalidBackup      = backup.ErrInvalidArchive
+)
+
+// Строки резервной копии для моделей, часть полей которых скрыта в API.
+type (
+	userRecord struct {
+		models.User
+		PasswordHash string `json:"passwordHash"`
+	}
+	apiKeyRecord struct {
+		models.APIKey
+		KeyHash string `json:"keyHash"`
+	}
+	importJobRecord struct {
+		models.ImportJob
+		Source []byte `json:"source,omitempty"`
+	}
+	revisionRecord struct {
+		models.SongRevision
+		ID uint `json:"id"`
+	}
+	songEventRecord struct {
+		models.SongEvent
+		Artist string          `json:"artist"`
+		Song   json.RawMessage `json:"song"`
+	}
+	webhookRecord struct {
+		models.Webhook
+		Secret string `json:"secret"`
+	}
+	webhookDeliveryRecord struct {
+		models.WebhookDelivery
+		Payload json.RawMessage `json:"payload"`
+	}
+	webhookAttemptRecord struct {
+		models.WebhookAttempt
+		ID         uint `json:"id"`
+		DeliveryID uint `json:"deliveryId"`
+	}
+)
+
+// backupTable описывает таблицу резервной копии.
+type backupTable struct {
+	name    string
+	model   interface{}
+	dump    func(tx *gorm.DB, encode func(interface{}) error) (int, error)
+	restore func(r *restorer) error // Восстанавливает строки с исходными ID
+}
+
+// newBackupTable описывает таблицу модели T, строки которой хранятся в архиве в виде R:
+// toRecord добавляет к строке поля, скрытые в API, а fromRecord возвращает их в модель.
+func newBackupTable[T any, R any](name string, toRecord func(*T) R, fromRecord func(*R) T) backupTable {
+	return backupTable{
+		name:  name,
+		model: new(T),
+		dump: func(tx *gorm.DB, encode func(interface{}) error) (int, error) {
+			return dumpTable(tx, func(row *T) error { return encode(toRecord(row)) })
+		},
+		restore: func(r *restorer) error {
+			return restoreInBatches(r, name, fromRecord)
+		},
+	}
+}
+
+// asIs - преобразование строки для таблиц, все поля которых есть в JSON модели.
+func asIs[T any](row *T) T {
+	return *row
+}
+
+// backupTables - все таблицы базы данных, кроме schema_migrations, в порядке восстановления:
+// таблица идет после таблиц, на которые ссылаются ее записи.
+var backupTables = []backupTable{
+	newBackupTable(backupTableUsers,
+		func(user *models.User) userRecord { return userRecord{User: *user, PasswordHash: user.PasswordHash} },
+		func(record *userRecord) models.User {
+			user := record.User
+			user.PasswordHash = record.PasswordHash
+			return user
+		}),
+	newBackupTable(backupTableRefreshTokens, asIs[models.RefreshToken], asIs[models.RefreshToken]),
+	newBackupTable(backupTableAPIKeys,
+		func(key *models.APIKey) apiKeyRecord { return apiKeyRecord{APIKey: *key, KeyHash: key.KeyHash} },
+		func(record *apiKeyRecord) models.APIKey {
+			key := record.APIKey
+			key.KeyHash = record.KeyHash
+			return key
+		}),
+	newBackupTable(backupTableSongs, asIs[models.Song], asIs[models.Song]),
+	newBackupTable(backupTableImportJobs,
+		func(job *models.ImportJob) importJobRecord {
+			return importJobRecord{ImportJob: *job, Source: job.Source}
+		},
+		func(record *importJobRecord) models.ImportJob {
+			job := record.ImportJob
+			job.Source = record.Source
+			return job
+		}),
+	newBackupTable(backupTableImportErrors, asIs[models.ImportError], asIs[models.ImportError]),
+	newBackupTable(backupTableRevisions,
+		func(revision *models.SongRevision) revisionRecord {
+			return revisionRecord{SongRevision: *revision, ID: revision.ID}
+		},
+		func(record *revisionRecord) models.SongRevision {
+			revision := record.SongRevision
+			revision.ID = record.ID
+			return revision
+		}),
+	newBackupTable(backupTableAudit, asIs[models.AuditEntry], asIs[models.AuditEntry]),
+	newBackupTable(backupTableEvents,
+		func(event *models.SongEvent) songEventRecord {
+			return songEventRecord{SongEvent: *event, Artist: event.Artist, Song: json.RawMessage(event.Song)}
+		},
+		func(record *songEventRecord) models.SongEvent {
+			event := record.SongEvent
+			event.Artist = record.Artist
+			event.Song = models.RawJSON(record.Song)
+			return event
+		}),
+	newBackupTable(backupTableWebhooks,
+		func(webhook *models.Webhook) webhookRecord {
+			return webhookRecord{Webhook: *webhook, Secret: webhook.Secret}
+		},
+		func(record *webhookRecord) models.Webhook {
+			webhook := record.Webhook
+			webhook.Secret = record.Secret
+			return webhook
+		}),
+	newBackupTable(backupTableWebhookDeliveries,
+		func(delivery *models.WebhookDelivery) webhookDeliveryRecord {
+			return webhookDeliveryRecord{WebhookDelivery: *delivery, Payload: json.RawMessage(delivery.Payload)}
+		},
+		func(record *webhookDeliveryRecord) models.WebhookDelivery {
+			delivery := record.WebhookDelivery
+			delivery.Payload = models.RawJSON(record.Payload)
+			return delivery
+		}),
+	newBackupTable(backupTableWebhookAttempts,
+		func(attempt *models.WebhookAttempt) webhookAttemptRecord {
+			return webhookAttemptRecord{WebhookAttempt: *attempt, ID: attempt.ID, DeliveryID: attempt.DeliveryID}
+		},
+		func(record *webhookAttemptRecord) models.WebhookAttempt {
+			attempt := record.WebhookAttempt
+			attempt.ID, attempt.DeliveryID = record.ID, record.DeliveryID
+			return attempt
+		}),
+}
+
+// ValidRestoreMode сообщает, поддерживается ли режим восстановления.
+func ValidRestoreMode(mode string) bool {
+	return mode == RestoreModeEmpty || mode == RestoreModeMerge
+}
+
+// CreateBackup выгружает все таблицы базы данных в сжатый архив и пишет его в w: песни, задания импорта,
+// ревизии, журналы изменений и событий, подписки с доставками, а также пользователей и ключи доступа.
+// Таблицы читаются в одной транзакции, чтобы копия была согласованной.
+func CreateBackup(db *gorm.DB, w io.Writer) (backup.Manifest, error) {
+	version, err := database.CurrentVersion(db)
+	if err != nil {
+		return backup.Manifest{}, err
+	}
+	writer, err := backup.NewWriter(version)
+	if err != nil {
+		return backup.Manifest{}, err
+	}
+	defer writer.Close()
+
+	var opts *sql.TxOptions
+	if db.Dialector.Name() == "postgres" {
+		opts = &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true}
+	}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		for _, table := range backupTables {
+			err := writer.Table(table.name, func(encode func(interface{}) error) (int, error) {
+				return table.dump(tx, encode)
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}, opts)
+	if err != nil {
+		return backup.Manifest{}, err
+	}
+
+	if _, err := writer.WriteTo(w); err != nil {
+		return backup.Manifest{}, err
+	}
+	manifest := writer.Manifest()
+	slog.InfoContext(db.Statement.Context, "Backup created", "tables", len(manifest.Tables), "schema_version", manifest.SchemaVersion)
+	return manifest, nil
+}
+
+// dumpTable построчно читает таблицу модели T в порядке ID.
+func dumpTable[T any](tx *gorm.DB, fn func(*T) error) (int, error) {
+	rows, err := tx.Model(new(T)).Order("id").Rows()
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	count := 0
+	for rows.Next() {
+		var row T
+		if err := tx.ScanRows(rows, &row); err != nil {
+			return count, err
+		}
+		if err := fn(&row); err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, rows.Err()
+}
+
+// RestoreBackup проверяет архив целиком и затем восстанавливает из него библиотеку в одной транзакции.
+// В режиме empty все таблицы базы должны быть пустыми, а записи всех таблиц сохраняют исходные ID.
+// В режиме merge переносятся только песни и задания импорта: песни, уже существующие в библиотеке
+// (та же группа и название), пропускаются, остальные записи получают новые ID, а ссылки на них пересчитываются.
+// Пользователи, ключи доступа, подписки, ревизии и журналы относятся к исходной базе и при слиянии пропускаются.
+func RestoreBackup(db *gorm.DB, r io.Reader, mode string) (models.RestoreResult, error) {
+	result := models.RestoreResult{Mode: mode, Tables: make([]models.RestoreTableResult, 0, len(backupTables))}
+	if !ValidRestoreMode(mode) {
+		return result, ErrInvalidRestoreMode
+	}
+
+	archive, err := backup.Open(r)
+	if err != nil {
+		return result, err
+	}
+	defer archive.Close()
+
+	result.SchemaVersion = archive.Manifest.SchemaVersion
+	current, err := database.CurrentVersion(db)
+	if err != nil {
+		return result, err
+	}
+	if archive.Manifest.SchemaVersion > current {
+		return result, fmt.Errorf("%w: backup schema %d, database schema %d",
+			ErrUnsupportedSchema, archive.Manifest.SchemaVersion, current)
+	}
+
+	err = songTransaction(db, func(tx *gorm.DB) error {
+		restore := &restorer{tx: tx, archive: archive, result: &result}
+		if mode == RestoreModeEmpty {
+			return restore.intoEmpty()
+		}
+		return restore.merge()
+	})
+	if err != nil {
+		return models.RestoreResult{Mode: mode, SchemaVersion: result.SchemaVersion}, err
+	}
+
+	slog.InfoContext(db.Statement.Context, "Backup restored", "mode", mode, "remapped_ids", result.RemappedIDs)
+	return result, nil
+}
+
+// restorer переносит таблицы архива в базу в рамках транзакции восстановления.
+type restorer struct {
+	tx      *gorm.DB
+	archive *backup.Archive
+	result  *models.RestoreResult
+}
+
+// table добавляет в итог восстановления запись о таблице.
+func (r *restorer) table(name string) *models.RestoreTableResult {
+	info, _ := r.archive.Manifest.Table(name)
+	r.result.Tables = append(r.result.Tables, models.RestoreTableResult{Name: name, Rows: info.Rows})
+	return &r.result.Tables[len(r.result.Tables)-1]
+}
+
+// intoEmpty восстанавливает записи всех таблиц с исходными ID.
+func (r *restorer) intoEmpty() error {
+	for _, table := range backupTables {
+		var count int64
+		if err := r.tx.Model(table.model).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return fmt.Errorf("%w: table %s has %d rows", ErrDatabaseNotEmpty, table.name, count)
+		}
+	}
+
+	for _, table := range backupTables {
+		if err := table.restore(r); err != nil {
+			return err
+		}
+	}
+	if err := r.resetSequences(); err != nil {
+		return err
+	}
+
+	// Записи о восстановлении добавляются после журнала из архива, чтобы не занять его ID.
+	var songs []models.Song
+	return r.tx.Model(&models.Song{}).Order("id").FindInBatches(&songs, restoreBatchSize, func(*gorm.DB, int) error {
+		return r.auditSongs(songs)
+	}).Error
+}
+
+// restoreInBatches вставляет строки таблицы архива пачками по restoreBatchSize.
+func restoreInBatches[R any, T any](r *restorer, name string, convert func(*R) T) error {
+	table := r.table(name)
+	batch := make([]T, 0, restoreBatchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := r.tx.Omit(clause.Associations).Create(&batch).Error; err != nil {
+			return err
+		}
+		table.Created += len(batch)
+		batch = batch[:0]
+		return nil
+	}
+
+	err := r.archive.Table(name, func(decode func(interface{}) error) error {
+		var row R
+		if err := decode(&row); err != nil {
+			return err
+		}
+		batch = append(batch, convert(&row))
+		if len(batch) == restoreBatchSize {
+			return flush()
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return flush()
+}
+
+// auditSongs записывает восстановленные песни в журнал изменений и сохраняет их ревизии.
+func (r *restorer) auditSongs(songs []models.Song) error {
+	entries := make([]models.AuditEntry, 0, len(songs))
+	revisions := make([]models.SongRevision, 0, len(songs))
+	for i := range songs {
+		entry, err := newAuditEntry(r.tx, AuditRestore, nil, &songs[i])
+		if err != nil {
+			return err
+		}
+		entries = append(entries, entry)
+		revisions = append(revisions, newRevision(r.tx, &songs[i]))
+	}
+	if err := r.tx.Create(&entries).Error; err != nil {
+		return err
+	}
+	// Ревизия текущей версии песни обычно уже восстановлена из архива.
+	return r.tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&revisions).Error
+}
+
+// resetSequences сдвигает счетчики ID PostgreSQL за максимальный восстановленный ID.
+func (r *restorer) resetSequences() error {
+	if r.tx.Dialector.Name() != "postgres" {
+		return nil
+	}
+	for _, table := range backupTables {
+		query := fmt.Sprintf("SELECT setval(pg_get_serial_sequence('%[1]s', 'id'), COALESCE((SELECT MAX(id) FROM %[1]s), 0) + 1, false)", table.name)
+		if err := r.tx.Exec(query).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// merge добавляет песни и задания импорта из архива к существующей библиотеке с новыми ID.
+func (r *restorer) merge() error {
+	jobIDs := make(map[uint]uint)
+	for _, table := range backupTables {
+		var err error
+		switch table.name {
+		case backupTableSongs:
+			err = r.mergeSongs()
+		case backupTableImportJobs:
+			err = r.mergeImportJobs(jobIDs)
+		case backupTableImportErrors:
+			err = r.mergeImportErrors(jobIDs)
+		default:
+			info := r.table(table.name)
+			info.Skipped = info.Rows
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mergeSongs добавляет песни, которых еще нет в библиотеке. Пользователи исходной базы не переносятся,
+// поэтому автором добавленных песен становится участник, выполняющий восстановление.
+func (r *restorer) mergeSongs() error {
+	songs := r.table(backupTableSongs)
+	return r.archive.Table(backupTableSongs, func(decode func(interface{}) error) error {
+		var song models.Song
+		if err := decode(&song); err != nil {
+			return err
+		}
+		existing, err := FindDuplicate(r.tx, song.Group, song.Name)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			songs.Skipped++
+			return nil
+		}
+
+		oldID := song.ID
+		song.ID = 0
+		song.CreatedBy = actor(r.tx)
+		song.UpdatedBy = song.CreatedBy
+		if err := r.tx.Create(&song).Error; err != nil {
+			return err
+		}
+		if err := recordAudit(r.tx, AuditRestore, nil, &song); err != nil {
+			return err
+		}
+		if err := recordRevision(r.tx, &song); err != nil {
+			return err
+		}
+		if err := recordSongEvents(r.tx, nil, &song); err != nil {
+			return err
+		}
+		songs.Created++
+		if song.ID != oldID {
+			r.result.RemappedIDs++
+		}
+		return nil
+	})
+}
+
+// mergeImportJobs добавляет задания импорта и запоминает их новые ID в jobIDs.
+func (r *restorer) mergeImportJobs(jobIDs map[uint]uint) error {
+	jobs := r.table(backupTableImportJobs)
+	return r.archive.Table(backupTableImportJobs, func(decode func(interface{}) error) error {
+		var record importJobRecord
+		if err := decode(&record); err != nil {
+			return err
+		}
+		job := record.ImportJob
+		job.Source = record.Source
+		job.ID = 0
+		if err := r.tx.Create(&job).Error; err != nil {
+			return err
+		}
+		jobs.Created++
+		jobIDs[record.ID] = job.ID
+		if job.ID != record.ID {
+			r.result.RemappedIDs++
+		}
+		return nil
+	})
+}
+
+// mergeImportErrors добавляет ошибки записей импорта, пересчитывая ID заданий по jobIDs.
+func (r *restorer) mergeImportErrors(jobIDs map[uint]uint) error {
+	importErrors := r.table(backupTableImportErrors)
+	return r.archive.Table(backupTableImportErrors, func(decode func(interface{}) error) error {
+		var importError models.ImportError
+		if err := decode(&importError); err != nil {
+			return err
+		}
+		jobID, ok := jobIDs[importError.JobID]
+		if !ok {
+			return fmt.Errorf("%w: import error %d refers to missing import job %d", ErrInvalidBackup, importError.ID, importError.JobID)
+		}
+		oldID := importError.ID
+		importError.ID = 0
+		importError.JobID = jobID
+		if err := r.tx.Create(&importError).Error; err != nil {
+			return err
+		}
+		importErrors.Created++
+		if importError.ID != oldID {
+			r.result.RemappedIDs++
+		}
+		return nil
+	})
+}
@@ -0,0 +1,320 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strconv"
+	"sync"
+
+	"gorm.io/gorm"
+	"music-library/app/config"
+	"music-library/app/models"
+)
+
+// Статусы элементов пакетных операций.
+const (
+	BatchStatusCreated              = "created"
+	BatchStatusUpdated              = "updated"
+	BatchStatusDeleted              = "deleted"
+	BatchStatusDuplicate            = "duplicate"
+	BatchStatusInvalid              = "invalid"
+	BatchStatusEnrichmentFailed     = "enrichment_failed"
+	BatchStatusNotFound             = "not_found"
+	BatchStatusConflict             = "conflict"
+	BatchStatusPreconditionRequired = "precondition_required"
+	BatchStatusForbidden            = "forbidden"
+	BatchStatusFailed               = "failed"
+	BatchStatusRolledBack           = "rolled_back"
+)
+
+// Режимы выполнения пакетных операций.
+const (
+	BatchModeAtomic     = "atomic"
+	BatchModeBestEffort = "best-effort"
+)
+
+// errBatchRollback прерывает транзакцию пакета в атомарном режиме.
+var errBatchRollback = errors.New("batch rolled back")
+
+// BatchOptions задает режим выполнения пакетной операции.
+type BatchOptions struct {
+	Mode        string // atomic или best-effort
+	Enrich      bool   // Запрашивать ли данные песен во внешнем API
+	Concurrency int    // Количество параллельных запросов к внешнему API
+}
+
+// Atomic сообщает, должна ли операция быть выполнена по принципу "все или ничего".
+func (o BatchOptions) Atomic() bool {
+	return o.Mode == BatchModeAtomic
+}
+
+// ParseBatchMode проверяет режим пакетной операции, по умолчанию используется best-effort.
+func ParseBatchMode(mode string) (string, bool) {
+	switch mode {
+	case "":
+		return BatchModeBestEffort, true
+	case BatchModeAtomic, BatchModeBestEffort:
+		return mode, true
+	}
+	return "", false
+}
+
+// NewBatchResponse собирает ответ пакетной операции со сводкой по статусам.
+func NewBatchResponse(opts BatchOptions, committed bool, results []models.BatchItemResult) models.BatchResponse {
+	summary := make(map[string]int)
+	for _, result := range results {
+		summary[result.Status]++
+	}
+	return models.BatchResponse{
+		Mode:      opts.Mode,
+		Committed: committed,
+		Summary:   summary,
+		Results:   results,
+	}
+}
+
+// CreateSongs создает песни пакетом. Валидация и поиск дубликатов внутри пакета выполняются заранее,
+// обогащение через внешний API идет параллельно с ограничением opts.Concurrency.
+// В атомарном режиме любая ошибка отменяет весь пакет.
+func CreateSongs(db *gorm.DB, songs []models.Song, opts BatchOptions) ([]models.BatchItemResult, bool, error) {
+	results := make([]models.BatchItemResult, len(songs))
+	pending := make([]int, 0, len(songs))
+	seen := make(map[string]int)
+
+	for i := range songs {
+		results[i].Index = i
+		if err := ValidateSong(songs[i]); err != nil {
+			results[i].Status = BatchStatusInvalid
+			results[i].Error = err.Error()
+			continue
+		}
+
+		key := songKey(songs[i].Group, songs[i].Name)
+		if first, ok := seen[key]; ok {
+			results[i].Status = BatchStatusDuplicate
+			results[i].Error = "duplicates item " + strconv.Itoa(first) + " of the batch"
+			continue
+		}
+		seen[key] = i
+		pending = append(pending, i)
+	}
+
+	if opts.Atomic() && len(pending) != len(songs) {
+		markRolledBack(results, pending)
+		return results, false, nil
+	}
+
+	if opts.Enrich {
+		pending = enrichSongs(db.Statement.Context, songs, pending, results, opts.Concurrency)
+		if opts.Atomic() && len(pending) != len(songs) {
+			markRolledBack(results, pending)
+			return results, false, nil
+		}
+	}
+
+	create := func(tx *gorm.DB, i int) bool {
+		song := songs[i]
+		if err := CreateSong(tx, &song); err != nil {
+			results[i].Status = statusForError(err)
+			results[i].Error = err.Error()
+			return false
+		}
+		results[i].Status = BatchStatusCreated
+		results[i].ID = song.ID
+		results[i].Song = &song
+		return true
+	}
+
+	if !opts.Atomic() {
+		for _, i := range pending {
+			create(db, i)
+		}
+		return results, true, nil
+	}
+
+	err := songTransaction(db, func(tx *gorm.DB) error {
+		for n, i := range pending {
+			if !create(tx, i) {
+				markRolledBack(results, append(pending[:n:n], pending[n+1:]...))
+				return errBatchRollback
+			}
+		}
+		return nil
+	})
+	if errors.Is(err, errBatchRollback) {
+		return results, false, nil
+	}
+	if err != nil {
+		return results, false, err
+	}
+	return results, true, nil
+}
+
+// UpdateSongs применяет пакет изменений к песням с проверкой ETag каждой из них.
+func UpdateSongs(db *gorm.DB, items []models.BatchUpdateItem, opts BatchOptions) ([]models.BatchItemResult, bool, error) {
+	return runBatch(db, len(items), opts, func(tx *gorm.DB, i int) models.BatchItemResult {
+		item := items[i]
+		result := models.BatchItemResult{Index: i, ID: item.ID}
+
+		existing, err := loadForChange(tx, item.ID, item.IfMatch)
+		if err != nil {
+			result.Status = statusForError(err)
+			result.Error = err.Error()
+			return result
+		}
+
+		updated, err := UpdateSong(tx, existing, item.Changes)
+		if err != nil {
+			result.Status = statusForError(err)
+			result.Error = err.Error()
+			return result
+		}
+		result.Status = BatchStatusUpdated
+		result.Song = &updated
+		return result
+	})
+}
+
+// DeleteSongs удаляет песни пакетом с проверкой ETag каждой из них.
+func DeleteSongs(db *gorm.DB, items []models.BatchDeleteItem, opts BatchOptions) ([]models.BatchItemResult, bool, error) {
+	return runBatch(db, len(items), opts, func(tx *gorm.DB, i int) models.BatchItemResult {
+		item := items[i]
+		result := models.BatchItemResult{Index: i, ID: item.ID}
+
+		existing, err := loadForChange(tx, item.ID, item.IfMatch)
+		if err == nil {
+			err = DeleteSong(tx, existing)
+		}
+		if err != nil {
+			result.Status = statusForError(err)
+			result.Error = err.Error()
+			return result
+		}
+		result.Status = BatchStatusDeleted
+		return result
+	})
+}
+
+// errPreconditionRequired возвращается, если для элемента пакета не передан обязательный ifMatch.
+var errPreconditionRequired = errors.New("ifMatch is required")
+
+// loadForChange загружает песню и проверяет переданный клиентом ETag.
+func loadForChange(db *gorm.DB, id uint, ifMatch string) (models.Song, error) {
+	song, err := GetSong(db, id)
+	if err != nil {
+		return song, err
+	}
+
+	if ifMatch == "" {
+		if config.Get().Features.RequireIfMatch {
+			return song, errPreconditionRequired
+		}
+		return song, nil
+	}
+	if !MatchETag(ifMatch, song.ETag) {
+		return song, ErrVersionConflict
+	}
+	return song, nil
+}
+
+// runBatch выполняет операцию для каждого элемента пакета: в атомарном режиме в одной транзакции,
+// иначе независимо друг от друга.
+func runBatch(db *gorm.DB, n int, opts BatchOptions, apply func(tx *gorm.DB, i int) models.BatchItemResult) ([]models.BatchItemResult, bool, error) {
+	results := make([]models.BatchItemResult, n)
+	if !opts.Atomic() {
+		for i := 0; i < n; i++ {
+			results[i] = apply(db, i)
+		}
+		return results, true, nil
+	}
+
+	err := songTransaction(db, func(tx *gorm.DB) error {
+		for i := 0; i < n; i++ {
+			results[i] = apply(tx, i)
+			if results[i].Error == "" {
+				continue
+			}
+			for j := range results {
+				if j != i {
+					results[j] = models.BatchItemResult{Index: j, Status: BatchStatusRolledBack}
+				}
+			}
+			return errBatchRollback
+		}
+		return nil
+	})
+	if errors.Is(err, errBatchRollback) {
+		return results, false, nil
+	}
+	if err != nil {
+		return results, false, err
+	}
+	return results, true, nil
+}
+
+// enrichSongs параллельно запрашивает данные песен во внешнем API и возвращает индексы успешно обогащенных.
+func enrichSongs(ctx context.Context, songs []models.Song, pending []int, results []models.BatchItemResult, concurrency int) []int {
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, concurrency)
+	failed := make([]bool, len(songs))
+
+	for _, i := range pending {
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			if err := EnrichSong(ctx, &songs[i]); err != nil {
+				slog.InfoContext(ctx, "Failed to enrich batch item", "index", i, "error", err)
+				results[i].Status = BatchStatusEnrichmentFailed
+				results[i].Error = err.Error()
+				failed[i] = true
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	enriched := pending[:0]
+	for _, i := range pending {
+		if !failed[i] {
+			enriched = append(enriched, i)
+		}
+	}
+	return enriched
+}
+
+// markRolledBack помечает успешно подготовленные элементы как отмененные вместе с пакетом.
+func markRolledBack(results []models.BatchItemResult, indexes []int) {
+	for _, i := range indexes {
+		results[i].Status = BatchStatusRolledBack
+		results[i].Song = nil
+		results[i].ID = 0
+	}
+}
+
+// statusForError сопоставляет ошибку сервиса со статусом элемента пакета.
+func statusForError(err error) string {
+	switch {
+	case errors.Is(err, ErrInvalidSong):
+		return BatchStatusInvalid
+	case errors.Is(err, ErrDuplicateSong):
+		return BatchStatusDuplicate
+	case errors.Is(err, ErrEnrichmentFailed):
+		return BatchStatusEnrichmentFailed
+	case errors.Is(err, ErrSongNotFound):
+		return BatchStatusNotFound
+	case errors.Is(err, ErrVersionConflict):
+		return BatchStatusConflict
+	case errors.Is(err, errPreconditionRequired):
+		return BatchStatusPreconditionRequired
+	case errors.Is(err, ErrForbidden):
+		return BatchStatusForbidden
+	}
+	return BatchStatusFailed
+}
@@ -0,0 +1,31 @@
+package services
+
+import "strings"
+
+// MatchETag проверяет, содержит ли значение заголовка If-Match указанный ETag.
+// Сравнение строгое (RFC 9110, 13.1.1): слабые валидаторы (W/"...") не совпадают ни с каким ETag,
+// "*" совпадает с любым ETag.
+func MatchETag(header, etag string) bool {
+	return matchETag(header, etag, func(a, b string) bool {
+		return !strings.HasPrefix(a, "W/") && a == b
+	})
+}
+
+// MatchWeakETag проверяет, содержит ли значение заголовка If-None-Match указанный ETag.
+// Слабые валидаторы (W/"...") сравниваются по значению, "*" совпадает с любым ETag.
+func MatchWeakETag(header, etag string) bool {
+	return matchETag(header, etag, func(a, b string) bool {
+		return strings.TrimPrefix(a, "W/") == strings.TrimPrefix(b, "W/")
+	})
+}
+
+// matchETag ищет в списке ETag-ов заголовка значение, совпадающее с etag по правилу equal.
+func matchETag(header, etag string, equal func(a, b string) bool) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || equal(candidate, etag) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,283 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"music-library/app/config"
+	"music-library/app/metrics"
+	"music-library/app/models"
+)
+
+// События песен.
+const (
+	EventSongCreated  = "song.created"
+	EventSongUpdated  = "song.updated"
+	EventSongDeleted  = "song.deleted"
+	EventSongEnriched = "song.enriched"
+)
+
+// SongEventTypes - все типы событий песен.
+var SongEventTypes = []string{EventSongCreated, EventSongUpdated, EventSongDeleted, EventSongEnriched}
+
+// songEventsLockKey - ключ advisory-блокировки, под которой записываются события.
+const songEventsLockKey = 7_310_201
+
+// songEventsPageSize - сколько событий читается из журнала за один запрос.
+const songEventsPageSize = 500
+
+// EventFilter отбирает события потока по типу и группе. Пустые поля не ограничивают выборку.
+type EventFilter struct {
+	Types  []string // Типы событий
+	Artist string   // Группа песни, без учета регистра
+}
+
+// Match сообщает, подходит ли событие под фильтр.
+func (f EventFilter) Match(event *models.SongEvent) bool {
+	if len(f.Types) > 0 && !slices.Contains(f.Types, event.Type) {
+		return false
+	}
+	return f.Artist == "" || strings.EqualFold(f.Artist, event.Artist)
+}
+
+// ListSongEvents возвращает до limit событий журнала с ID больше after, подходящих под фильтр, по возрастанию ID.
+func ListSongEvents(db *gorm.DB, after uint, filter EventFilter, limit int) ([]models.SongEvent, error) {
+	query := db.Where("id > ?", after).Order("id").Limit(limit)
+	if len(filter.Types) > 0 {
+		query = query.Where("event IN ?", filter.Types)
+	}
+	if filter.Artist != "" {
+		query = query.Where("LOWER(artist) = LOWER(?)", filter.Artist)
+	}
+	var events []models.SongEvent
+	err := query.Find(&events).Error
+	return events, err
+}
+
+// LatestSongEventID возвращает ID последнего события журнала или 0, если журнал пуст.
+func LatestSongEventID(db *gorm.DB) (uint, error) {
+	var id uint
+	err := db.Model(&models.SongEvent{}).Select("COALESCE(MAX(id), 0)").Scan(&id).Error
+	return id, err
+}
+
+// songTransaction выполняет fn в транзакции, события песен которой записываются в журнал последними
+// перед фиксацией. ID событий должны расти в порядке фиксации транзакций: иначе поток, уже отдавший событие
+// с большим ID, пропустил бы событие транзакции, зафиксированной позже. Поэтому события пишутся под общей
+// advisory-блокировкой, а чтобы она не упорядочивала изменения песен целиком, берется только в конце транзакции.
+// Вложенный вызов добавляет события к внешней транзакции и отбрасывает их, если его точка сохранения откатывается.
+func songTransaction(db *gorm.DB, fn func(tx *gorm.DB) error) error {
+	if pending, ok := db.Statement.Context.Value(pendingEventsKey{}).(*pendingEvents); ok {
+		mark := len(pending.events)
+		err := db.Transaction(fn)
+		if err != nil {
+			pending.events = pending.events[:mark]
+		}
+		return err
+	}
+
+	pending := &pendingEvents{}
+	ctx := context.WithValue(db.Statement.Context, pendingEventsKey{}, pending)
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := fn(tx); err != nil {
+			return err
+		}
+		return writeEvents(tx, pending.events)
+	})
+}
+
+// pendingEventsKey - ключ контекста, в котором songTransaction копит события транзакции.
+type pendingEventsKey struct{}
+
+// pendingEvents - события транзакции, еще не записанные в журнал.
+type pendingEvents struct {
+	events []models.SongEvent
+}
+
+// recordEvent добавляет событие песни к событиям транзакции изменения песни, поэтому событие появляется
+// только вместе с изменением. Вне songTransaction событие записывается сразу.
+func recordEvent(tx *gorm.DB, eventType string, song *models.Song, changes models.FieldChanges) error {
+	data, err := json.Marshal(song)
+	if err != nil {
+		return err
+	}
+	event := models.SongEvent{
+		CreatedAt: time.Now(),
+		Type:      eventType,
+		SongID:    song.ID,
+		Artist:    song.Group,
+		Song:      data,
+		Changes:   changes,
+	}
+	if pending, ok := tx.Statement.Context.Value(pendingEventsKey{}).(*pendingEvents); ok {
+		pending.events = append(pending.events, event)
+		return nil
+	}
+	return writeEvents(tx, []models.SongEvent{event})
+}
+
+// writeEvents записывает события в журнал и ставит их в очередь доставки подписчикам.
+// Блокировка держится до конца транзакции, поэтому после записи событий транзакция должна фиксироваться сразу.
+func writeEvents(tx *gorm.DB, events []models.SongEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	if tx.Dialector.Name() == "postgres" {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", songEventsLockKey).Error; err != nil {
+			return err
+		}
+	}
+	for i := range events {
+		if err := tx.Create(&events[i]).Error; err != nil {
+			return err
+		}
+		if err := queueDeliveries(tx, &events[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recordSongEvents записывает события изменения песни: before равен nil для новой песни, after - для удаленной.
+// Для новой песни с данными из внешнего API дополнительно записывается song.enriched.
+func recordSongEvents(tx *gorm.DB, before, after *models.Song) error {
+	switch {
+	case before == nil:
+		if err := recordEvent(tx, EventSongCreated, after, nil); err != nil {
+			return err
+		}
+		if after.Enriched {
+			return recordEvent(tx, EventSongEnriched, after, nil)
+		}
+		return nil
+	case after == nil:
+		return recordEvent(tx, EventSongDeleted, before, nil)
+	default:
+		changes, err := diffSongs(before, after)
+		if err != nil {
+			return err
+		}
+		return recordEvent(tx, EventSongUpdated, after, changes)
+	}
+}
+
+// EventSubscription - подписка на новые события журнала.
+type EventSubscription struct {
+	Events <-chan models.SongEvent // Новые события по возрастанию ID
+	Closed <-chan struct{}         // Закрывается, если подписчик не успевал принимать события или сервис останавливается
+
+	events chan models.SongEvent
+	closed chan struct{}
+}
+
+// eventFeed раздает подписчикам события, прочитанные из журнала.
+type eventFeed struct {
+	mu          sync.Mutex
+	subscribers map[*EventSubscription]struct{}
+	stopped     bool
+}
+
+var feed = &eventFeed{subscribers: make(map[*EventSubscription]struct{})}
+
+// SubscribeSongEvents подписывает на новые события журнала. Возвращаемую функцию нужно вызвать,
+// когда события больше не нужны.
+func SubscribeSongEvents() (*EventSubscription, func()) {
+	events := make(chan models.SongEvent, config.Get().Events.BufferSize)
+	closed := make(chan struct{})
+	subscription := &EventSubscription{Events: events, Closed: closed, events: events, closed: closed}
+
+	feed.mu.Lock()
+	if feed.stopped {
+		close(closed)
+	} else {
+		feed.subscribers[subscription] = struct{}{}
+		metrics.SetEventSubscribers(len(feed.subscribers))
+	}
+	feed.mu.Unlock()
+
+	return subscription, func() {
+		feed.mu.Lock()
+		defer feed.mu.Unlock()
+		delete(feed.subscribers, subscription)
+		metrics.SetEventSubscribers(len(feed.subscribers))
+	}
+}
+
+// publish отдает событие всем подписчикам. Подписчик с заполненной очередью отключается, а не задерживает
+// остальных: он продолжит с последнего полученного события по журналу.
+func (f *eventFeed) publish(event models.SongEvent) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for subscription := range f.subscribers {
+		select {
+		case subscription.events <- event:
+		default:
+			close(subscription.closed)
+			delete(f.subscribers, subscription)
+			metrics.ObserveEventSubscriberDropped()
+		}
+	}
+	metrics.SetEventSubscribers(len(f.subscribers))
+}
+
+// CloseEventStreams отключает всех подписчиков и больше не принимает новых. Вызывается при остановке
+// сервиса, чтобы открытые потоки событий не задерживали завершение HTTP-сервера.
+func CloseEventStreams() {
+	feed.mu.Lock()
+	defer feed.mu.Unlock()
+	feed.stopped = true
+	for subscription := range feed.subscribers {
+		close(subscription.closed)
+		delete(feed.subscribers, subscription)
+	}
+	metrics.SetEventSubscribers(0)
+}
+
+// StartEventFeed запускает фоновое чтение новых событий из журнала для подписчиков потока.
+// Журнал общий для всех экземпляров сервиса, поэтому подписчик получает и изменения, сделанные через другие экземпляры.
+func StartEventFeed(db *gorm.DB) {
+	interval := config.Get().Events.PollInterval
+	startWorker(func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		var cursor uint
+		started := false
+		for {
+			if !started {
+				latest, err := LatestSongEventID(db)
+				if err != nil {
+					slog.Warn("Failed to read song event log position", "error", err)
+				} else {
+					cursor, started = latest, true
+				}
+			}
+			for started {
+				events, err := ListSongEvents(db, cursor, EventFilter{}, songEventsPageSize)
+				if err != nil {
+					slog.Warn("Failed to read song event log", "error", err)
+					break
+				}
+				for _, event := range events {
+					feed.publish(event)
+					cursor = event.ID
+				}
+				if len(events) < songEventsPageSize {
+					break
+				}
+			}
+
+			select {
+			case <-stopWorkers:
+				return
+			case <-ticker.C:
+			}
+		}
+	})
+}
@@ -0,0 +1,34 @@
+package services
+
+import (
+	"gorm.io/gorm"
+	"music-library/app/exporter"
+	"music-library/app/models"
+)
+
+// ExportSongs построчно читает песни из базы данных и передает их в writer,
+// не загружая весь каталог в память. Возвращает количество выгруженных песен.
+func ExportSongs(db *gorm.DB, filter SongFilter, writer exporter.Writer) (int, error) {
+	query := ApplySongFilter(db.Model(&models.Song{}), filter).Order("id")
+	rows, err := query.Rows()
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	count := 0
+	for rows.Next() {
+		var song models.Song
+		if err := db.ScanRows(rows, &song); err != nil {
+			return count, err
+		}
+		if err := writer.WriteSong(song); err != nil {
+			return count, err
+		}
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		return count, err
+	}
+	return count, writer.Close()
+}
@@ -0,0 +1,50 @@
+package services
+
+import (
+	"strings"
+
+	"gorm.io/gorm"
+	"music-library/app/models"
+)
+
+// SongFilter описывает фильтры и пагинацию списка песен, общие для GetSongs и экспорта.
+type SongFilter struct {
+	Group  string // Точное название группы
+	Name   string // Точное название песни
+	Search string // Подстрока группы, названия или альбома без учета регистра
+	Limit  int    // Максимальное количество песен, 0 - без ограничения
+	Offset int    // Количество пропускаемых песен
+}
+
+// likeEscaper экранирует спецсимволы шаблона LIKE, чтобы строка поиска сравнивалась буквально.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// ApplySongFilter добавляет условия фильтра к запросу песен.
+func ApplySongFilter(query *gorm.DB, filter SongFilter) *gorm.DB {
+	if filter.Group != "" {
+		query = query.Where("artist = ?", filter.Group)
+	}
+	if filter.Name != "" {
+		query = query.Where("name = ?", filter.Name)
+	}
+	if filter.Search != "" {
+		pattern := "%" + likeEscaper.Replace(strings.ToLower(filter.Search)) + "%"
+		query = query.Where(`LOWER(artist) LIKE ? ESCAPE '\' OR LOWER(name) LIKE ? ESCAPE '\' OR LOWER(album) LIKE ? ESCAPE '\'`,
+			pattern, pattern, pattern)
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+	if filter.Offset > 0 {
+		query = query.Offset(filter.Offset)
+	}
+	return query
+}
+
+// CountSongs возвращает количество песен, подходящих под фильтр, без учета пагинации.
+func CountSongs(db *gorm.DB, filter SongFilter) (int64, error) {
+	filter.Limit, filter.Offset = 0, 0
+	var total int64
+	err := ApplySongFilter(db.Model(&models.Song{}), filter).Count(&total).Error
+	return total, err
+}
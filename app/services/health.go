@@ -0,0 +1,73 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"gorm.io/gorm"
+	"music-library/app/config"
+	"music-library/app/database"
+	"music-library/app/health"
+)
+
+// Имена проверок готовности.
+const (
+	HealthCheckDatabase   = "database"
+	HealthCheckMigrations = "migrations"
+	HealthCheckProvider   = "provider"
+)
+
+// HealthChecks возвращает проверки зависимостей сервиса: базы данных, актуальности схемы
+// и внешнего API. Критичность и таймауты задаются в секции health конфигурации.
+func HealthChecks(db *gorm.DB) []health.Check {
+	settings := config.Get().Health
+	check := func(name string, run func(ctx context.Context) error) health.Check {
+		return health.Check{
+			Name:     name,
+			Critical: !settings.NonCritical(name),
+			Timeout:  settings.CheckTimeout,
+			Run:      run,
+		}
+	}
+
+	return []health.Check{
+		check(HealthCheckDatabase, func(ctx context.Context) error {
+			sqlDB, err := db.DB()
+			if err != nil {
+				return err
+			}
+			return sqlDB.PingContext(ctx)
+		}),
+		check(HealthCheckMigrations, func(ctx context.Context) error {
+			current, err := database.CurrentVersion(db.WithContext(ctx))
+			if err != nil {
+				return err
+			}
+			if expected := database.SchemaVersion(); current < expected {
+				return fmt.Errorf("schema version %d is behind %d", current, expected)
+			}
+			return nil
+		}),
+		check(HealthCheckProvider, PingProvider),
+	}
+}
+
+// PingProvider проверяет, что внешний API отвечает. Любой ответ без ошибки сервера считается доступностью.
+func PingProvider(ctx context.Context) error {
+	provider := config.Get().Provider
+	request, err := newProviderRequest(ctx, strings.TrimSuffix(provider.URL, "/")+"/info")
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("provider responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
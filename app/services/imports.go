@@ -0,0 +1,394 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+	"music-library/app/importer"
+	"music-library/app/models"
+)
+
+// Статусы заданий импорта.
+const (
+	ImportStatusPending   = "pending"
+	ImportStatusRunning   = "running"
+	ImportStatusCompleted = "completed"
+	ImportStatusFailed    = "failed"
+)
+
+// Действия над записями импорта.
+const (
+	ImportActionCreated = "created"
+	ImportActionUpdated = "updated"
+	ImportActionSkipped = "skipped"
+	ImportActionFailed  = "failed"
+)
+
+// Обработка записей, совпадающих с уже существующими песнями.
+const (
+	OnDuplicateSkip   = "skip"
+	OnDuplicateUpdate = "update"
+)
+
+// importLeaseTimeout - через сколько времени без сохранения прогресса задание в статусе running считается
+// брошенным (например, после падения сервиса) и может быть возобновлено.
+const importLeaseTimeout = 5 * time.Minute
+
+var (
+	// ErrImportNotFound возвращается, если задание импорта отсутствует.
+	ErrImportNotFound = errors.New("import job not found")
+	// ErrImportCompleted возвращается при попытке возобновить завершенное задание.
+	ErrImportCompleted = errors.New("import job is already completed")
+	// ErrImportRunning возвращается при попытке возобновить задание, которое уже выполняется.
+	ErrImportRunning = errors.New("import job is already running")
+	// ErrInvalidImport возвращается, если параметры импорта некорректны.
+	ErrInvalidImport = errors.New("invalid import options")
+)
+
+// ImportOptions задает параметры нового задания импорта.
+type ImportOptions struct {
+	FileName    string // Имя исходного файла
+	Format      string // csv, json или ndjson, по умолчанию определяется по имени файла
+	Mapping     string // Сопоставление полей песни с колонками: "group=Artist,song=Title"
+	Delimiter   string // Разделитель колонок CSV
+	DryRun      bool   // Только показать, что будет создано, обновлено или пропущено
+	Enrich      bool   // Запрашивать ли данные новых песен во внешнем API
+	OnDuplicate string // skip или update для песен, совпадающих с существующими по группе и названию без учета регистра
+}
+
+// CreateImportJob проверяет параметры импорта, подсчитывает записи и сохраняет задание вместе с содержимым файла.
+func CreateImportJob(db *gorm.DB, source []byte, opts ImportOptions) (*models.ImportJob, error) {
+	if opts.Format == "" {
+		opts.Format = importer.DetectFormat(opts.FileName)
+	}
+	if !importer.ValidFormat(opts.Format) {
+		return nil, fmt.Errorf("%w: format must be csv, json or ndjson", ErrInvalidImport)
+	}
+	if opts.OnDuplicate == "" {
+		opts.OnDuplicate = OnDuplicateSkip
+	}
+	if opts.OnDuplicate != OnDuplicateSkip && opts.OnDuplicate != OnDuplicateUpdate {
+		return nil, fmt.Errorf("%w: onDuplicate must be skip or update", ErrInvalidImport)
+	}
+
+	job := &models.ImportJob{
+		Status:      ImportStatusPending,
+		FileName:    opts.FileName,
+		Format:      opts.Format,
+		Mapping:     opts.Mapping,
+		Delimiter:   opts.Delimiter,
+		DryRun:      opts.DryRun,
+		Enrich:      opts.Enrich,
+		OnDuplicate: opts.OnDuplicate,
+		Source:      source,
+	}
+
+	readerOpts, err := importReaderOptions(job)
+	if err != nil {
+		return nil, err
+	}
+	err = importer.Read(bytes.NewReader(source), readerOpts, func(importer.Record) error {
+		job.TotalRows++
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImport, err)
+	}
+
+	if err := db.Create(job).Error; err != nil {
+		return nil, err
+	}
+	slog.InfoContext(db.Statement.Context, "Created import job", "job_id", job.ID, "records", job.TotalRows)
+	return job, nil
+}
+
+// GetImportJob загружает задание импорта по идентификатору.
+func GetImportJob(db *gorm.DB, id interface{}) (*models.ImportJob, error) {
+	var job models.ImportJob
+	if err := db.First(&job, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrImportNotFound
+		}
+		return nil, err
+	}
+	return &job, nil
+}
+
+// GetImportErrors возвращает ошибки записей задания импорта в порядке следования записей.
+func GetImportErrors(db *gorm.DB, jobID uint) ([]models.ImportError, error) {
+	var importErrors []models.ImportError
+	err := db.Where("job_id = ?", jobID).Order("record_number").Find(&importErrors).Error
+	return importErrors, err
+}
+
+// RunImportJob обрабатывает записи задания, начиная с первой необработанной, так что прерванное
+// задание можно возобновить повторным вызовом. onRow, если задан, получает результат каждой записи.
+func RunImportJob(db *gorm.DB, job *models.ImportJob, onRow func(models.ImportRowResult)) error {
+	if err := claimImportJob(db, job); err != nil {
+		return err
+	}
+	return runImportJob(db, job, onRow)
+}
+
+// StartImportJob занимает задание импорта и запускает его обработку в фоне.
+// Фоновая обработка меняет копию задания, поэтому job можно сразу вернуть клиенту.
+// При остановке сервиса задание прерывается после текущей записи и может быть возобновлено.
+func StartImportJob(db *gorm.DB, job *models.ImportJob) error {
+	if err := claimImportJob(db, job); err != nil {
+		return err
+	}
+	running := *job
+	startWorker(func() {
+		if err := runImportJob(db, &running, nil); err != nil {
+			slog.InfoContext(db.Statement.Context, "Background import job stopped", "job_id", running.ID, "error", err)
+		}
+	})
+	return nil
+}
+
+// claimImportJob переводит задание в статус running одним условным UPDATE, чтобы два обработчика
+// не выполняли его одновременно, и перечитывает прогресс задания.
+func claimImportJob(db *gorm.DB, job *models.ImportJob) error {
+	now := time.Now()
+	result := db.Model(&models.ImportJob{}).
+		Where("id = ?", job.ID).
+		Where("status IN ? OR (status = ? AND updated_at < ?)",
+			[]string{ImportStatusPending, ImportStatusFailed}, ImportStatusRunning, now.Add(-importLeaseTimeout)).
+		Updates(map[string]interface{}{
+			"status":      ImportStatusRunning,
+			"last_error":  "",
+			"finished_at": nil,
+			"started_at":  gorm.Expr("COALESCE(started_at, ?)", now),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	claimed := result.RowsAffected > 0
+	if err := db.First(job, job.ID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrImportNotFound
+		}
+		return err
+	}
+	if !claimed {
+		if job.Status == ImportStatusCompleted {
+			return ErrImportCompleted
+		}
+		return ErrImportRunning
+	}
+	return nil
+}
+
+// runImportJob обрабатывает записи занятого задания. Изменения каждой записи сохраняются в одной транзакции
+// с прогрессом задания, поэтому возобновленное задание не повторяет уже учтенных записей.
+func runImportJob(db *gorm.DB, job *models.ImportJob, onRow func(models.ImportRowResult)) error {
+	readerOpts, err := importReaderOptions(job)
+	if err != nil {
+		return failImportJob(db, job, err)
+	}
+	slog.InfoContext(db.Statement.Context, "Running import job", "job_id", job.ID, "from_record", job.ProcessedRows+1)
+
+	// В пробном запуске песни не сохраняются, поэтому повторы внутри файла отслеживаются отдельно.
+	seen := make(map[string]uint)
+
+	err = importer.Read(bytes.NewReader(job.Source), readerOpts, func(record importer.Record) error {
+		if record.Row <= job.ProcessedRows {
+			return nil
+		}
+		if stopRequested() {
+			return ErrShuttingDown
+		}
+
+		// Обогащение выполняется до транзакции, чтобы не держать ее открытой во время запроса к внешнему API.
+		row := prepareImportRow(db, job, record, seen)
+		progress := *job
+		err := songTransaction(db, func(tx *gorm.DB) error {
+			applyImportRow(tx, job, &row)
+			switch row.result.Action {
+			case ImportActionCreated:
+				job.CreatedRows++
+			case ImportActionUpdated:
+				job.UpdatedRows++
+			case ImportActionSkipped:
+				job.SkippedRows++
+			case ImportActionFailed:
+				job.FailedRows++
+				importError := models.ImportError{JobID: job.ID, Row: record.Row, Message: row.result.Error, Raw: record.Raw}
+				if err := tx.Create(&importError).Error; err != nil {
+					return err
+				}
+			}
+			job.ProcessedRows = record.Row
+			return saveImportProgress(tx, job)
+		})
+		if err != nil {
+			*job = progress
+			return err
+		}
+
+		if onRow != nil {
+			onRow(row.result)
+		}
+		return nil
+	})
+	if err != nil {
+		return failImportJob(db, job, err)
+	}
+
+	finished := time.Now()
+	job.FinishedAt = &finished
+	job.Status = ImportStatusCompleted
+	slog.InfoContext(db.Statement.Context, "Import job completed", "job_id", job.ID,
+		"created", job.CreatedRows, "updated", job.UpdatedRows, "skipped", job.SkippedRows, "failed", job.FailedRows)
+	return saveImportProgress(db, job)
+}
+
+// failImportJob сохраняет причину остановки задания, чтобы его можно было возобновить.
+func failImportJob(db *gorm.DB, job *models.ImportJob, err error) error {
+	slog.InfoContext(db.Statement.Context, "Import job failed", "job_id", job.ID, "record", job.ProcessedRows+1, "error", err)
+	finished := time.Now()
+	job.FinishedAt = &finished
+	job.Status = ImportStatusFailed
+	job.LastError = err.Error()
+	if saveErr := saveImportProgress(db, job); saveErr != nil {
+		slog.InfoContext(db.Statement.Context, "Failed to save import job progress", "job_id", job.ID, "error", saveErr)
+	}
+	return err
+}
+
+// importRow - запись импорта, подготовленная к сохранению.
+type importRow struct {
+	result   models.ImportRowResult
+	song     models.Song
+	existing *models.Song
+}
+
+// prepareImportRow решает, создать, обновить или пропустить песню из одной записи импорта,
+// и для новой песни запрашивает недостающие данные во внешнем API.
+// Дубликаты определяются так же, как в CreateSong: по группе и названию песни.
+func prepareImportRow(db *gorm.DB, job *models.ImportJob, record importer.Record, seen map[string]uint) importRow {
+	row := importRow{
+		result: models.ImportRowResult{Row: record.Row, Group: record.Song.Group, Name: record.Song.Name},
+		song:   record.Song,
+	}
+	if record.Err != nil {
+		row.fail(record.Err)
+		return row
+	}
+	if err := ValidateSong(row.song); err != nil {
+		row.fail(err)
+		return row
+	}
+
+	existing, err := FindDuplicate(db, row.song.Group, row.song.Name)
+	if err != nil {
+		row.fail(err)
+		return row
+	}
+
+	key := songKey(row.song.Group, row.song.Name)
+	if existing == nil && job.DryRun {
+		if id, ok := seen[key]; ok {
+			existing = &models.Song{MyBaseModel: models.MyBaseModel{ID: id}}
+		}
+	}
+
+	if existing != nil {
+		row.existing = existing
+		row.result.SongID = existing.ID
+		row.result.Action = ImportActionSkipped
+		if job.OnDuplicate == OnDuplicateUpdate {
+			row.result.Action = ImportActionUpdated
+		}
+		return row
+	}
+
+	row.result.Action = ImportActionCreated
+	if job.DryRun {
+		seen[key] = 0
+		return row
+	}
+	if job.Enrich {
+		if err := enrichMissing(db.Statement.Context, &row.song); err != nil {
+			row.fail(err)
+		}
+	}
+	return row
+}
+
+// applyImportRow сохраняет подготовленную запись импорта. В пробном запуске данные не изменяются.
+func applyImportRow(tx *gorm.DB, job *models.ImportJob, row *importRow) {
+	if job.DryRun {
+		return
+	}
+	switch row.result.Action {
+	case ImportActionUpdated:
+		if _, err := UpdateSong(tx, *row.existing, row.song); err != nil {
+			row.fail(err)
+		}
+	case ImportActionCreated:
+		if err := CreateSong(tx, &row.song); err != nil {
+			if errors.Is(err, ErrDuplicateSong) {
+				row.result.Action = ImportActionSkipped
+				return
+			}
+			row.fail(err)
+			return
+		}
+		row.result.SongID = row.song.ID
+	}
+}
+
+// fail отмечает запись импорта как необработанную.
+func (row *importRow) fail(err error) {
+	row.result.Action = ImportActionFailed
+	row.result.Error = err.Error()
+}
+
+// enrichMissing заполняет данными внешнего API только те поля, которых нет в файле импорта.
+func enrichMissing(ctx context.Context, song *models.Song) error {
+	if song.ReleaseDate != "" && song.Text != "" && song.Link != "" {
+		return nil
+	}
+
+	detail, err := FetchSongDetail(ctx, song.Group, song.Name)
+	if err != nil {
+		return err
+	}
+	if song.ReleaseDate == "" {
+		song.ReleaseDate = detail.ReleaseDate
+	}
+	if song.Text == "" {
+		song.Text = detail.Text
+	}
+	if song.Link == "" {
+		song.Link = detail.Link
+	}
+	song.Enriched = true
+	return nil
+}
+
+func importReaderOptions(job *models.ImportJob) (importer.Options, error) {
+	mapping, err := importer.ParseMapping(job.Mapping)
+	if err != nil {
+		return importer.Options{}, fmt.Errorf("%w: %v", ErrInvalidImport, err)
+	}
+	delimiter, err := importer.ParseDelimiter(job.Delimiter)
+	if err != nil {
+		return importer.Options{}, fmt.Errorf("%w: %v", ErrInvalidImport, err)
+	}
+	return importer.Options{Format: job.Format, Mapping: mapping, Delimiter: delimiter}, nil
+}
+
+func saveImportProgress(db *gorm.DB, job *models.ImportJob) error {
+	return db.Model(job).Select(
+		"status", "processed_rows", "created_rows", "updated_rows", "skipped_rows", "failed_rows",
+		"last_error", "started_at", "finished_at",
+	).Updates(job).Error
+}
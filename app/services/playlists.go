@@ -0,0 +1,137 @@
+package services
+
+import (
+	"strings"
+
+	"gorm.io/gorm"
+	"music-library/app/models"
+	"music-library/app/playlist"
+)
+
+// playlistSuggestions задает количество похожих песен, предлагаемых для несопоставленной записи.
+const playlistSuggestions = 3
+
+// playlistMatchBatch - сколько записей плейлиста сопоставляется с каталогом за один запрос.
+const playlistMatchBatch = 500
+
+// PlaylistEntries собирает записи плейлиста из песен с указанными ID в переданном порядке,
+// а если ID не переданы - из песен, отобранных фильтром.
+func PlaylistEntries(db *gorm.DB, ids []uint, filter SongFilter) ([]playlist.Entry, error) {
+	var songs []models.Song
+	if len(ids) > 0 {
+		if err := db.Where("id IN ?", ids).Find(&songs).Error; err != nil {
+			return nil, err
+		}
+		byID := make(map[uint]models.Song, len(songs))
+		for _, song := range songs {
+			byID[song.ID] = song
+		}
+		songs = songs[:0]
+		for _, id := range ids {
+			if song, ok := byID[id]; ok {
+				songs = append(songs, song)
+			}
+		}
+	} else if err := ApplySongFilter(db.Model(&models.Song{}), filter).Order("id").Find(&songs).Error; err != nil {
+		return nil, err
+	}
+
+	entries := make([]playlist.Entry, 0, len(songs))
+	for _, song := range songs {
+		entry := playlist.Entry{
+			Title:    song.Name,
+			Artist:   song.Group,
+			Album:    song.Album,
+			Location: song.Link,
+			Duration: -1,
+		}
+		if song.Duration > 0 {
+			entry.Duration = song.Duration
+		}
+		if entry.Location == "" {
+			entry.Location = song.FilePath
+		}
+		if entry.Location == "" {
+			entry.Location = playlist.SongURI(song.ID)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// MatchPlaylist сопоставляет записи импортированного плейлиста с песнями каталога.
+// Каталог целиком не загружается: для каждой порции записей читаются только песни, которые могут с ними совпасть.
+func MatchPlaylist(db *gorm.DB, format string, entries []playlist.Entry) (models.PlaylistImportResult, error) {
+	result := models.PlaylistImportResult{Format: format, Total: len(entries)}
+
+	for start := 0; start < len(entries); start += playlistMatchBatch {
+		batch := entries[start:min(start+playlistMatchBatch, len(entries))]
+		candidates, err := playlistCandidates(db, batch)
+		if err != nil {
+			return result, err
+		}
+		for _, match := range playlist.MatchEntries(batch, candidates, playlistSuggestions) {
+			match.Index += start
+			result.Entries = append(result.Entries, match)
+		}
+	}
+
+	for _, entry := range result.Entries {
+		if entry.Matched {
+			result.Matched++
+		}
+	}
+	result.Unmatched = result.Total - result.Matched
+	return result, nil
+}
+
+// playlistCandidates загружает песни, с которыми могут совпасть записи плейлиста: по ссылке, пути к файлу
+// или адресу SongURI, а также песни тех же исполнителей и с теми же названиями без учета регистра.
+// Похожие песни для несопоставленных записей подбираются только среди них.
+func playlistCandidates(db *gorm.DB, entries []playlist.Entry) ([]playlist.Candidate, error) {
+	var locations, artists, titles []string
+	var ids []uint
+	for _, entry := range entries {
+		if entry.Location != "" {
+			locations = append(locations, entry.Location)
+			if id, ok := playlist.ParseSongURI(entry.Location); ok {
+				ids = append(ids, id)
+			}
+		}
+		if entry.Artist != "" {
+			artists = append(artists, strings.ToLower(entry.Artist))
+		}
+		if entry.Title != "" {
+			titles = append(titles, strings.ToLower(entry.Title))
+		}
+	}
+
+	var conditions []string
+	var args []interface{}
+	if len(locations) > 0 {
+		conditions = append(conditions, "link IN ?", "file_path IN ?")
+		args = append(args, locations, locations)
+	}
+	if len(ids) > 0 {
+		conditions = append(conditions, "id IN ?")
+		args = append(args, ids)
+	}
+	if len(artists) > 0 {
+		conditions = append(conditions, "LOWER(artist) IN ?")
+		args = append(args, artists)
+	}
+	if len(titles) > 0 {
+		conditions = append(conditions, "LOWER(name) IN ?")
+		args = append(args, titles)
+	}
+	if len(conditions) == 0 {
+		return nil, nil
+	}
+
+	var candidates []playlist.Candidate
+	err := db.Model(&models.Song{}).
+		Select("id", "artist", "name AS title", "link", "file_path").
+		Where("("+strings.Join(conditions, " OR ")+")", args...).
+		Scan(&candidates).Error
+	return candidates, err
+}
@@ -0,0 +1,115 @@
+package services
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+	"music-library/app/models"
+	"music-library/app/textdiff"
+)
+
+// ErrRevisionNotFound возвращается, если у песни нет ревизии с указанным номером.
+var ErrRevisionNotFound = errors.New("revision not found")
+
+// revisionColumns - колонки песни, которые сохраняются в ревизии и восстанавливаются при откате.
+var revisionColumns = []string{"artist", "name", "release_date", "text", "link", "album"}
+
+// ListRevisions возвращает ревизии песни от новых к старым.
+// Ревизии удаленной песни остаются доступными, ErrSongNotFound возвращается, только если песни никогда не было.
+func ListRevisions(db *gorm.DB, songID uint, limit, offset int) ([]models.SongRevision, error) {
+	query := db.Where("song_id = ?", songID).Order("revision DESC").Offset(offset)
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	var revisions []models.SongRevision
+	if err := query.Find(&revisions).Error; err != nil {
+		return nil, err
+	}
+	if len(revisions) > 0 || offset > 0 {
+		return revisions, nil
+	}
+	if _, err := GetSong(db, songID); err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
+
+// GetRevision возвращает ревизию песни с указанным номером.
+func GetRevision(db *gorm.DB, songID, revision uint) (models.SongRevision, error) {
+	var rev models.SongRevision
+	err := db.Where("song_id = ? AND revision = ?", songID, revision).First(&rev).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return rev, ErrRevisionNotFound
+	}
+	return rev, err
+}
+
+// DiffRevisions строит построчный diff текста песни между ревизиями from и to.
+func DiffRevisions(db *gorm.DB, songID, from, to uint) (models.RevisionDiff, []textdiff.Hunk, error) {
+	diff := models.RevisionDiff{SongID: songID, From: from, To: to, Hunks: []models.DiffHunk{}}
+	a, err := GetRevision(db, songID, from)
+	if err != nil {
+		return diff, nil, err
+	}
+	b, err := GetRevision(db, songID, to)
+	if err != nil {
+		return diff, nil, err
+	}
+
+	hunks := textdiff.Hunks(textdiff.Diff(textdiff.Lines(a.Text), textdiff.Lines(b.Text)), textdiff.DefaultContext)
+	for _, hunk := range hunks {
+		lines := make([]string, len(hunk.Lines))
+		for i, line := range hunk.Lines {
+			lines[i] = string(line.Kind) + line.Text
+		}
+		diff.Hunks = append(diff.Hunks, models.DiffHunk{
+			OldStart: hunk.OldStart,
+			OldLines: hunk.OldLines,
+			NewStart: hunk.NewStart,
+			NewLines: hunk.NewLines,
+			Lines:    lines,
+		})
+	}
+	return diff, hunks, nil
+}
+
+// RevertSong возвращает содержимое песни к указанной ревизии. Откат - обычное изменение:
+// версия песни увеличивается, а в журнале и списке ревизий появляются новые записи.
+func RevertSong(db *gorm.DB, existing models.Song, revision uint) (models.Song, error) {
+	rev, err := GetRevision(db, existing.ID, revision)
+	if err != nil {
+		return existing, err
+	}
+	changes := models.Song{
+		Group:       rev.Group,
+		Name:        rev.Name,
+		ReleaseDate: rev.ReleaseDate,
+		Text:        rev.Text,
+		Link:        rev.Link,
+		Album:       rev.Album,
+	}
+	return updateSong(db, existing, changes, revisionColumns)
+}
+
+// recordRevision сохраняет содержимое песни как ревизию с номером ее текущей версии.
+// Вызывается в транзакции изменения вместе с recordAudit.
+func recordRevision(tx *gorm.DB, song *models.Song) error {
+	revision := newRevision(tx, song)
+	return tx.Create(&revision).Error
+}
+
+// newRevision собирает ревизию песни от имени участника запроса.
+func newRevision(tx *gorm.DB, song *models.Song) models.SongRevision {
+	revision := models.SongRevision{
+		SongID:      song.ID,
+		Revision:    song.Version,
+		Group:       song.Group,
+		Name:        song.Name,
+		ReleaseDate: song.ReleaseDate,
+		Text:        song.Text,
+		Link:        song.Link,
+		Album:       song.Album,
+	}
+	revision.Actor, revision.ActorName = auditActor(tx)
+	return revision
+}
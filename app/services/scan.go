@@ -0,0 +1,206 @@
+package services
+
+import (
+	"errors"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"gorm.io/gorm"
+	"music-library/app/models"
+	"music-library/app/scanner"
+)
+
+// Действия над аудиофайлами при сканировании.
+const (
+	ScanActionCreated   = "created"
+	ScanActionUpdated   = "updated"
+	ScanActionMoved     = "moved"
+	ScanActionUnchanged = "unchanged"
+	ScanActionSkipped   = "skipped"
+	ScanActionFailed    = "failed"
+)
+
+// ScanOptions задает режим сканирования каталога.
+type ScanOptions struct {
+	Full   bool // Перечитывать все файлы, а не только измененные с прошлого сканирования
+	DryRun bool // Только показать, что будет сделано, без изменения данных
+}
+
+// ScanLibrary обходит каталог с аудиофайлами и создает или обновляет песни по их тегам.
+// Файлы, у которых не изменились время модификации и размер, пропускаются без чтения, если не задан opts.Full.
+// Перемещенные файлы находятся по хешу содержимого.
+func ScanLibrary(db *gorm.DB, root string, opts ScanOptions, onFile func(models.ScanFileResult)) (models.ScanSummary, error) {
+	var summary models.ScanSummary
+
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return summary, err
+	}
+	slog.InfoContext(db.Statement.Context, "Scanning audio files", "root", root)
+
+	err = scanner.Walk(root, func(path string, info fs.FileInfo, err error) error {
+		var result models.ScanFileResult
+		if err != nil {
+			// Нечитаемый файл или каталог не прерывает сканирование остальной библиотеки.
+			slog.InfoContext(db.Statement.Context, "Failed to read path", "path", path, "error", err)
+			result = models.ScanFileResult{Path: path, Action: ScanActionFailed, Error: err.Error()}
+		} else {
+			summary.Scanned++
+			result = scanFile(db, path, info, opts)
+		}
+		switch result.Action {
+		case ScanActionCreated:
+			summary.Created++
+		case ScanActionUpdated:
+			summary.Updated++
+		case ScanActionMoved:
+			summary.Moved++
+		case ScanActionUnchanged:
+			summary.Unchanged++
+		case ScanActionSkipped:
+			summary.Skipped++
+		case ScanActionFailed:
+			summary.Failed++
+		}
+		if onFile != nil {
+			onFile(result)
+		}
+		return nil
+	})
+
+	slog.InfoContext(db.Statement.Context, "Scan finished", "files", summary.Scanned, "created", summary.Created,
+		"updated", summary.Updated, "moved", summary.Moved, "unchanged", summary.Unchanged,
+		"skipped", summary.Skipped, "failed", summary.Failed)
+	return summary, err
+}
+
+// scanFile обрабатывает один аудиофайл.
+func scanFile(db *gorm.DB, path string, info fs.FileInfo, opts ScanOptions) models.ScanFileResult {
+	result := models.ScanFileResult{Path: path}
+	fail := func(err error) models.ScanFileResult {
+		slog.InfoContext(db.Statement.Context, "Failed to scan file", "path", path, "error", err)
+		result.Action = ScanActionFailed
+		result.Error = err.Error()
+		return result
+	}
+
+	byPath, err := findSongBy(db, "file_path", path)
+	if err != nil {
+		return fail(err)
+	}
+	if byPath != nil && !opts.Full && byPath.FileSize == info.Size() &&
+		byPath.FileModTime != nil && byPath.FileModTime.Equal(info.ModTime()) {
+		result.Action = ScanActionUnchanged
+		result.SongID = byPath.ID
+		return result
+	}
+
+	file, err := scanner.ReadFile(path)
+	if err != nil {
+		return fail(err)
+	}
+
+	existing, action := byPath, ScanActionUpdated
+	if existing != nil && existing.ContentHash == file.Hash {
+		action = ScanActionUnchanged
+	}
+
+	if existing == nil {
+		// Песня с тем же содержимым, файл которой больше не существует, считается перемещенной.
+		byHash, err := findSongBy(db, "content_hash", file.Hash)
+		if err != nil {
+			return fail(err)
+		}
+		if byHash != nil && !fileExists(byHash.FilePath) {
+			existing, action = byHash, ScanActionMoved
+		}
+	}
+
+	if existing == nil {
+		duplicate, err := FindDuplicate(db, file.Artist, file.Title)
+		if err != nil {
+			return fail(err)
+		}
+		if duplicate != nil {
+			if duplicate.FilePath != "" && fileExists(duplicate.FilePath) {
+				result.Action = ScanActionSkipped
+				result.SongID = duplicate.ID
+				result.Error = "song is already linked to " + duplicate.FilePath
+				return result
+			}
+			existing = duplicate
+		}
+	}
+
+	song := songFromFile(file)
+	if existing == nil {
+		if err := ValidateSong(song); err != nil {
+			return fail(err)
+		}
+		result.Action = ScanActionCreated
+		if !opts.DryRun {
+			if err := CreateSong(db, &song); err != nil {
+				return fail(err)
+			}
+			result.SongID = song.ID
+		}
+		return result
+	}
+
+	result.SongID = existing.ID
+	result.Action = action
+	if action == ScanActionUnchanged {
+		// Содержимое не изменилось: запоминаем только новое время модификации, не меняя версию песни.
+		if !opts.DryRun {
+			err := db.Model(existing).UpdateColumns(models.Song{FileSize: song.FileSize, FileModTime: song.FileModTime}).Error
+			if err != nil {
+				return fail(err)
+			}
+		}
+		return result
+	}
+	if !opts.DryRun {
+		if _, err := UpdateSong(db, *existing, song); err != nil {
+			return fail(err)
+		}
+	}
+	return result
+}
+
+// songFromFile собирает изменения песни из тегов и параметров файла.
+// Пустые значения не затирают данные песни, так как UpdateSong обновляет только заполненные поля.
+func songFromFile(file *scanner.File) models.Song {
+	modTime := file.ModTime
+	return models.Song{
+		Group:       file.Artist,
+		Name:        file.Title,
+		ReleaseDate: file.ReleaseDate(),
+		Text:        file.Lyrics,
+		Album:       file.Album,
+		FilePath:    file.Path,
+		FileSize:    file.Size,
+		FileModTime: &modTime,
+		ContentHash: file.Hash,
+		Duration:    file.Duration,
+		Bitrate:     file.Bitrate,
+	}
+}
+
+func findSongBy(db *gorm.DB, column, value string) (*models.Song, error) {
+	var song models.Song
+	err := db.Where(column+" = ?", value).Order("id").Limit(1).Find(&song).Error
+	if err != nil || song.ID == 0 {
+		return nil, err
+	}
+	return &song, nil
+}
+
+func fileExists(path string) bool {
+	if path == "" {
+		return false
+	}
+	_, err := os.Stat(path)
+	return !errors.Is(err, fs.ErrNotExist)
+}
@@ -0,0 +1,319 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+	"music-library/app/auth"
+	"music-library/app/config"
+	"music-library/app/logging"
+	"music-library/app/metrics"
+	"music-library/app/models"
+	"music-library/app/tracing"
+)
+
+var (
+	// ErrSongNotFound возвращается, если песня с указанным ID отсутствует.
+	ErrSongNotFound = errors.New("song not found")
+	// ErrInvalidSong возвращается, если у песни не заполнены группа или название.
+	ErrInvalidSong = errors.New("group and song name must not be empty")
+	// ErrDuplicateSong возвращается, если песня с такой группой и названием уже есть.
+	ErrDuplicateSong = errors.New("song already exists")
+	// ErrVersionConflict возвращается, если песня была изменена с момента чтения.
+	ErrVersionConflict = errors.New("song was modified by another request")
+	// ErrEnrichmentFailed возвращается, если внешний API не вернул данные о песне.
+	ErrEnrichmentFailed = errors.New("failed to retrieve data from external API")
+)
+
+// ValidateSong проверяет обязательные поля песни.
+func ValidateSong(song models.Song) error {
+	if song.Group == "" || song.Name == "" {
+		return ErrInvalidSong
+	}
+	return nil
+}
+
+// FetchSongDetail запрашивает дату релиза, текст и ссылку песни во внешнем API.
+// ID запроса из ctx передается внешнему API в заголовке X-Request-ID.
+func FetchSongDetail(ctx context.Context, group, name string) (models.SongDetail, error) {
+	var detail models.SongDetail
+	provider := config.Get().Provider
+	ctx, span := tracing.Tracer().Start(ctx, "provider.FetchSongDetail", trace.WithAttributes(
+		tracing.AttrProviderName.String(provider.Name),
+		tracing.AttrSongGroup.String(group),
+		tracing.AttrSongName.String(name),
+	))
+	defer span.End()
+
+	started := time.Now()
+	outcome := metrics.ProviderOK
+	defer func() {
+		metrics.ObserveProvider(outcome, time.Since(started))
+		span.SetAttributes(attribute.String("provider.outcome", outcome))
+		if outcome != metrics.ProviderOK {
+			span.SetStatus(codes.Error, outcome)
+		}
+	}()
+
+	apiURL := strings.TrimSuffix(provider.URL, "/") + "/info?group=" + url.QueryEscape(group) + "&song=" + url.QueryEscape(name)
+	request, err := newProviderRequest(ctx, apiURL)
+	if err != nil {
+		return detail, err
+	}
+	client := &http.Client{Timeout: provider.Timeout, Transport: tracing.Transport(http.DefaultTransport)}
+	resp, err := client.Do(request)
+	if err != nil {
+		outcome = metrics.ProviderFailed
+		if os.IsTimeout(err) {
+			outcome = metrics.ProviderTimeout
+		}
+		slog.InfoContext(ctx, "Error calling external API", "error", err)
+		return detail, fmt.Errorf("%w: %v", ErrEnrichmentFailed, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		outcome = metrics.ProviderError
+		if resp.StatusCode == http.StatusNotFound {
+			outcome = metrics.ProviderNotFound
+		}
+		slog.InfoContext(ctx, "Non-200 response from external API", "status", resp.StatusCode)
+		return detail, fmt.Errorf("%w: status %d", ErrEnrichmentFailed, resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&detail); err != nil {
+		outcome = metrics.ProviderError
+		slog.InfoContext(ctx, "Failed to decode external API response", "error", err)
+		return detail, fmt.Errorf("%w: %v", ErrEnrichmentFailed, err)
+	}
+	return detail, nil
+}
+
+// newProviderRequest создает GET-запрос к внешнему API с ID текущего запроса.
+func newProviderRequest(ctx context.Context, apiURL string) (*http.Request, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if id := logging.RequestID(ctx); id != "" {
+		request.Header.Set(logging.HeaderRequestID, id)
+	}
+	return request, nil
+}
+
+// EnrichSong дополняет песню данными из внешнего API.
+func EnrichSong(ctx context.Context, song *models.Song) error {
+	detail, err := FetchSongDetail(ctx, song.Group, song.Name)
+	if err != nil {
+		return err
+	}
+
+	song.ReleaseDate = detail.ReleaseDate
+	song.Text = detail.Text
+	song.Link = detail.Link
+	song.Enriched = true
+	return nil
+}
+
+// Enrichment возвращает статус обогащения песни (complete, partial или missing) и поля,
+// которые внешний API не заполнил.
+func Enrichment(detail models.SongDetail) (string, []string) {
+	var missing []string
+	if detail.ReleaseDate == "" {
+		missing = append(missing, "releaseDate")
+	}
+	if detail.Text == "" {
+		missing = append(missing, "text")
+	}
+	if detail.Link == "" {
+		missing = append(missing, "link")
+	}
+
+	switch len(missing) {
+	case 0:
+		return "complete", nil
+	case 3:
+		return "missing", missing
+	default:
+		return "partial", missing
+	}
+}
+
+// SplitVerses разбивает текст песни на куплеты, разделенные пустой строкой.
+// Экранированные переводы строк "\n" в тексте из внешнего API считаются настоящими.
+func SplitVerses(text string) []string {
+	text = strings.ReplaceAll(text, "\\n", "\n")
+	return strings.Split(text, "\n\n")
+}
+
+// GetSong загружает песню по идентификатору.
+func GetSong(db *gorm.DB, id interface{}) (models.Song, error) {
+	var song models.Song
+	if err := db.First(&song, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return song, ErrSongNotFound
+		}
+		return song, err
+	}
+	return song, nil
+}
+
+// FindDuplicate ищет песню с той же группой и названием без учета регистра, как и songKey.
+func FindDuplicate(db *gorm.DB, group, name string) (*models.Song, error) {
+	var existing models.Song
+	err := db.Where("LOWER(artist) = LOWER(?) AND LOWER(name) = LOWER(?)", group, name).Order("id").Limit(1).Find(&existing).Error
+	if err != nil {
+		return nil, err
+	}
+	if existing.ID == 0 {
+		return nil, nil
+	}
+	return &existing, nil
+}
+
+// songKey возвращает ключ, по которому песни с одной группой и названием считаются дубликатами.
+// Регистр не учитывается, как и в FindDuplicate.
+func songKey(group, name string) string {
+	return strings.ToLower(group) + "\x00" + strings.ToLower(name)
+}
+
+// songKeyLockClass - пространство ключей advisory-блокировок, под которыми создаются песни с одной группой и названием.
+const songKeyLockClass = 7_310_202
+
+// CreateSong сохраняет новую песню, если песни с такой группой и названием еще нет.
+// Проверка и вставка выполняются в одной транзакции под блокировкой группы и названия,
+// поэтому параллельные запросы не создадут двух одинаковых песен.
+func CreateSong(db *gorm.DB, song *models.Song) error {
+	if err := ValidateSong(*song); err != nil {
+		return err
+	}
+
+	return songTransaction(db, func(tx *gorm.DB) error {
+		if tx.Dialector.Name() == "postgres" {
+			if err := tx.Exec("SELECT pg_advisory_xact_lock(?, hashtext(?))", songKeyLockClass, songKey(song.Group, song.Name)).Error; err != nil {
+				return err
+			}
+		}
+		existing, err := FindDuplicate(tx, song.Group, song.Name)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			return ErrDuplicateSong
+		}
+		return insertSong(tx, song)
+	})
+}
+
+// InsertSong сохраняет новую песню без проверки на дубликаты, как POST /songs.
+func InsertSong(db *gorm.DB, song *models.Song) error {
+	if err := ValidateSong(*song); err != nil {
+		return err
+	}
+	return songTransaction(db, func(tx *gorm.DB) error {
+		return insertSong(tx, song)
+	})
+}
+
+// insertSong вставляет песню вместе с записью журнала, первой ревизией и событиями.
+func insertSong(tx *gorm.DB, song *models.Song) error {
+	song.ID = 0
+	song.Version = 1
+	song.CreatedBy = actor(tx)
+	song.UpdatedBy = song.CreatedBy
+	if err := tx.Create(song).Error; err != nil {
+		return err
+	}
+	if err := recordAudit(tx, AuditCreate, nil, song); err != nil {
+		return err
+	}
+	if err := recordRevision(tx, song); err != nil {
+		return err
+	}
+	return recordSongEvents(tx, nil, song)
+}
+
+// UpdateSong применяет изменения к песне, если ее версия не изменилась с момента чтения.
+// Пустые поля changes не меняются. Возвращает песню после изменения.
+func UpdateSong(db *gorm.DB, existing models.Song, changes models.Song) (models.Song, error) {
+	return updateSong(db, existing, changes, nil)
+}
+
+// updateSong изменяет песню с проверкой версии, записывая изменение в журнал и новую ревизию.
+// Если задан columns, перечисленные колонки записываются и пустыми значениями.
+func updateSong(db *gorm.DB, existing models.Song, changes models.Song, columns []string) (models.Song, error) {
+	changes.ID = 0
+	changes.Version = existing.Version + 1
+	changes.CreatedBy = nil
+	changes.UpdatedBy = actor(db)
+
+	var updated models.Song
+	err := songTransaction(db, func(tx *gorm.DB) error {
+		current := existing
+		query := tx.Model(&current).Where("version = ?", existing.Version)
+		if columns != nil {
+			query = query.Select(append(columns, "version", "updated_by", "updated_at"))
+		}
+		result := query.Updates(changes)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrVersionConflict
+		}
+		if columns == nil && changes.UpdatedBy == nil && existing.UpdatedBy != nil {
+			// Updates пропускает пустые поля, а изменение не от имени пользователя должно сбросить автора правки.
+			if err := tx.Model(&current).UpdateColumn("updated_by", nil).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := tx.First(&updated, existing.ID).Error; err != nil {
+			return err
+		}
+		if err := recordAudit(tx, AuditUpdate, &existing, &updated); err != nil {
+			return err
+		}
+		if err := recordRevision(tx, &updated); err != nil {
+			return err
+		}
+		return recordSongEvents(tx, &existing, &updated)
+	})
+	if err != nil {
+		return existing, err
+	}
+	return updated, nil
+}
+
+// DeleteSong удаляет песню, если ее версия не изменилась с момента чтения.
+// Редакторы могут удалять только созданные ими песни.
+func DeleteSong(db *gorm.DB, existing models.Song) error {
+	if !auth.PrincipalFrom(db.Statement.Context).CanDelete(existing.CreatedBy) {
+		return ErrForbidden
+	}
+	return songTransaction(db, func(tx *gorm.DB) error {
+		result := tx.Where("version = ?", existing.Version).Delete(&existing)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrVersionConflict
+		}
+		if err := recordAudit(tx, AuditDelete, &existing, nil); err != nil {
+			return err
+		}
+		return recordSongEvents(tx, &existing, nil)
+	})
+}
@@ -0,0 +1,238 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"log/slog"
+	"regexp"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+	"music-library/app/auth"
+	"music-library/app/config"
+	"music-library/app/models"
+)
+
+// refreshTokenPrefix начинает каждый токен обновления.
+const refreshTokenPrefix = "mlr_"
+
+// minPasswordLength - минимальная длина пароля. Максимальная ограничена bcrypt в 72 байта.
+const minPasswordLength = 8
+
+// usernamePattern - допустимые имена пользователей.
+var usernamePattern = regexp.MustCompile(`^[A-Za-z0-9._-]{3,64}$`)
+
+var (
+	// ErrUserNotFound возвращается, если пользователь с указанным ID отсутствует.
+	ErrUserNotFound = errors.New("user not found")
+	// ErrUserExists возвращается при регистрации занятого имени пользователя.
+	ErrUserExists = errors.New("username is already taken")
+	// ErrInvalidUser возвращается для недопустимого имени пользователя или пароля.
+	ErrInvalidUser = errors.New("username must be 3-64 letters, digits, '.', '_' or '-' and password 8-72 bytes")
+	// ErrInvalidCredentials возвращается при неверном пароле или недействительном токене обновления.
+	ErrInvalidCredentials = errors.New("invalid username, password or refresh token")
+	// ErrForbidden возвращается, если участнику запроса нельзя изменять запись.
+	ErrForbidden = errors.New("not allowed to modify this record")
+)
+
+// dummyPasswordHash сравнивается с паролем при входе несуществующего пользователя,
+// чтобы время ответа не выдавало, зарегистрировано ли имя.
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("music-library"), bcrypt.DefaultCost)
+
+// CreateUser регистрирует пользователя с указанной ролью.
+func CreateUser(db *gorm.DB, username, password, role string) (models.User, error) {
+	username = strings.TrimSpace(username)
+	if !usernamePattern.MatchString(username) || len(password) < minPasswordLength || len(password) > 72 {
+		return models.User{}, ErrInvalidUser
+	}
+	if !auth.ValidRole(role) {
+		return models.User{}, auth.ErrInvalidRole
+	}
+
+	var count int64
+	if err := db.Model(&models.User{}).Where("username = ?", username).Count(&count).Error; err != nil {
+		return models.User{}, err
+	}
+	if count > 0 {
+		return models.User{}, ErrUserExists
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return models.User{}, err
+	}
+	user := models.User{Username: username, PasswordHash: string(hash), Role: role}
+	if err := db.Create(&user).Error; err != nil {
+		return models.User{}, err
+	}
+	slog.InfoContext(db.Statement.Context, "Created user", "user_id", user.ID, "username", username, "role", role)
+	return user, nil
+}
+
+// ListUsers возвращает всех пользователей.
+func ListUsers(db *gorm.DB) ([]models.User, error) {
+	var users []models.User
+	err := db.Order("id").Find(&users).Error
+	return users, err
+}
+
+// GetUser загружает пользователя по идентификатору.
+func GetUser(db *gorm.DB, id interface{}) (models.User, error) {
+	var user models.User
+	if err := db.First(&user, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return user, ErrUserNotFound
+		}
+		return user, err
+	}
+	return user, nil
+}
+
+// SetUserRole меняет роль пользователя. Выданные токены доступа сохраняют прежнюю роль до истечения,
+// новая роль попадает в токены при следующем обновлении.
+func SetUserRole(db *gorm.DB, id interface{}, role string) (models.User, error) {
+	if !auth.ValidRole(role) {
+		return models.User{}, auth.ErrInvalidRole
+	}
+	user, err := GetUser(db, id)
+	if err != nil {
+		return user, err
+	}
+	if err := db.Model(&user).Update("role", role).Error; err != nil {
+		return user, err
+	}
+	user.Role = role
+	slog.InfoContext(db.Statement.Context, "Changed user role", "user_id", user.ID, "role", role)
+	return user, nil
+}
+
+// Login проверяет пароль пользователя и открывает сессию.
+func Login(db *gorm.DB, username, password string) (models.TokenResponse, error) {
+	var user models.User
+	err := db.Where("username = ?", strings.TrimSpace(username)).Limit(1).Find(&user).Error
+	if err != nil {
+		return models.TokenResponse{}, err
+	}
+	if user.ID == 0 {
+		bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(password))
+		return models.TokenResponse{}, ErrInvalidCredentials
+	}
+	if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)) != nil {
+		slog.InfoContext(db.Statement.Context, "Wrong password", "user_id", user.ID)
+		return models.TokenResponse{}, ErrInvalidCredentials
+	}
+
+	return issueTokens(db, user)
+}
+
+// RefreshSession обменивает токен обновления на новую пару токенов. Каждый токен обновления одноразовый:
+// повторное предъявление уже обмененного токена считается кражей, и все сессии пользователя закрываются.
+func RefreshSession(db *gorm.DB, refreshToken string) (models.TokenResponse, error) {
+	var response models.TokenResponse
+	var reusedBy uint
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var stored models.RefreshToken
+		err := tx.Where("token_hash = ?", hashAPIKey(refreshToken)).Limit(1).Find(&stored).Error
+		if err != nil {
+			return err
+		}
+		if stored.ID == 0 {
+			return ErrInvalidCredentials
+		}
+		now := time.Now()
+		if stored.RevokedAt != nil {
+			reusedBy = stored.UserID
+			return ErrInvalidCredentials
+		}
+		if !now.Before(stored.ExpiresAt) {
+			return ErrInvalidCredentials
+		}
+
+		result := tx.Model(&stored).Where("revoked_at IS NULL").UpdateColumn("revoked_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrInvalidCredentials
+		}
+		user, err := GetUser(tx, stored.UserID)
+		if errors.Is(err, ErrUserNotFound) {
+			return ErrInvalidCredentials
+		}
+		if err != nil {
+			return err
+		}
+		response, err = issueTokens(tx, user)
+		return err
+	})
+	if reusedBy != 0 {
+		slog.WarnContext(db.Statement.Context, "Revoked refresh token reused, closing all sessions", "user_id", reusedBy)
+		revokeSessions(db, reusedBy)
+	}
+	return response, err
+}
+
+// Logout закрывает сессию, удаляя токен обновления. Неизвестный токен не считается ошибкой.
+// Токен удаляется, а не отзывается, чтобы его повторное предъявление не принималось за кражу.
+func Logout(db *gorm.DB, refreshToken string) error {
+	return db.Where("token_hash = ?", hashAPIKey(refreshToken)).Delete(&models.RefreshToken{}).Error
+}
+
+// revokeSessions отзывает все токены обновления пользователя.
+func revokeSessions(db *gorm.DB, userID uint) {
+	err := db.Model(&models.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		UpdateColumn("revoked_at", time.Now()).Error
+	if err != nil {
+		slog.ErrorContext(db.Statement.Context, "Failed to revoke user sessions", "user_id", userID, "error", err)
+	}
+}
+
+// issueTokens выпускает токен доступа и сохраняет новый токен обновления пользователя.
+func issueTokens(db *gorm.DB, user models.User) (models.TokenResponse, error) {
+	accessToken, expiresAt, err := auth.IssueAccessToken(user.ID, user.Username, user.Role)
+	if err != nil {
+		return models.TokenResponse{}, err
+	}
+
+	var secret [32]byte
+	if _, err := rand.Read(secret[:]); err != nil {
+		return models.TokenResponse{}, err
+	}
+	refreshToken := refreshTokenPrefix + base64.RawURLEncoding.EncodeToString(secret[:])
+	stored := models.RefreshToken{
+		UserID:    user.ID,
+		TokenHash: hashAPIKey(refreshToken),
+		ExpiresAt: time.Now().Add(config.Get().Auth.RefreshTokenTTL),
+	}
+	if err := db.Create(&stored).Error; err != nil {
+		return models.TokenResponse{}, err
+	}
+
+	return models.TokenResponse{
+		AccessToken:  accessToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(time.Until(expiresAt).Round(time.Second).Seconds()),
+		RefreshToken: refreshToken,
+	}, nil
+}
+
+// Authenticator возвращает проверку учетных данных для auth.Middleware:
+// ключи доступа ищутся в базе данных, токены пользователей проверяются по подписи.
+func Authenticator(db *gorm.DB) auth.Authenticator {
+	return func(ctx context.Context, credential string) (*auth.Principal, error) {
+		if strings.HasPrefix(credential, apiKeyPrefix) {
+			return AuthenticateAPIKey(db.WithContext(ctx), credential)
+		}
+		return auth.ParseAccessToken(credential)
+	}
+}
+
+// actor возвращает ID пользователя, от имени которого выполняется запрос к базе данных.
+func actor(db *gorm.DB) *uint {
+	return auth.PrincipalFrom(db.Statement.Context).UserID()
+}
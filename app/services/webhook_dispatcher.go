@@ -0,0 +1,241 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"music-library/app/config"
+	"music-library/app/metrics"
+	"music-library/app/models"
+	"music-library/app/tracing"
+)
+
+// Заголовки запроса к подписчику.
+const (
+	HeaderWebhookEvent     = "X-Webhook-Event"
+	HeaderWebhookDelivery  = "X-Webhook-Delivery"
+	HeaderWebhookTimestamp = "X-Webhook-Timestamp"
+	HeaderWebhookSignature = "X-Webhook-Signature"
+)
+
+// webhookLeaseMargin добавляется к таймауту запроса, пока доставка числится за обработчиком.
+// Если процесс упадет во время отправки, доставка снова станет доступной по истечении этого срока.
+const webhookLeaseMargin = time.Minute
+
+// webhookErrorBodyLimit - сколько байт ответа подписчика сохраняется в журнале неуспешной попытки.
+const webhookErrorBodyLimit = 512
+
+// webhookWake будит обработчик доставок раньше очередной проверки очереди.
+var webhookWake = make(chan struct{}, 1)
+
+// wakeWebhookDispatcher просит обработчик доставок проверить очередь, не дожидаясь интервала опроса.
+func wakeWebhookDispatcher() {
+	select {
+	case webhookWake <- struct{}{}:
+	default:
+	}
+}
+
+// StartWebhookDispatcher запускает фоновую доставку событий подписчикам, если она включена.
+// Несколько экземпляров сервиса могут доставлять события одновременно: доставка забирается
+// из очереди с блокировкой строки и не отправляется дважды.
+func StartWebhookDispatcher(db *gorm.DB) {
+	settings := config.Get().Webhooks
+	if !settings.Enabled {
+		slog.Info("Webhook delivery is disabled")
+		return
+	}
+	client := &http.Client{Timeout: settings.Timeout, Transport: tracing.Transport(http.DefaultTransport)}
+
+	startWorker(func() {
+		ticker := time.NewTicker(settings.PollInterval)
+		defer ticker.Stop()
+		for {
+			if err := dispatchWebhooks(db, client, settings); err != nil {
+				slog.Warn("Failed to dispatch webhook deliveries", "error", err)
+			}
+			select {
+			case <-stopWorkers:
+				return
+			case <-ticker.C:
+			case <-webhookWake:
+			}
+		}
+	})
+}
+
+// dispatchWebhooks отправляет все доставки, время которых подошло, группами по settings.Concurrency.
+func dispatchWebhooks(db *gorm.DB, client *http.Client, settings config.WebhooksConfig) error {
+	for !stopRequested() {
+		deliveries, webhooks, err := claimDeliveries(db, settings)
+		if err != nil || len(deliveries) == 0 {
+			return err
+		}
+
+		var wg sync.WaitGroup
+		for i := range deliveries {
+			wg.Add(1)
+			go func(delivery *models.WebhookDelivery) {
+				defer wg.Done()
+				deliverWebhook(db, client, settings, delivery, webhooks[delivery.WebhookID])
+			}(&deliveries[i])
+		}
+		wg.Wait()
+	}
+	return nil
+}
+
+// claimDeliveries забирает из очереди доставки активных подписок, время которых подошло, и откладывает
+// их следующую попытку на время отправки, чтобы другие обработчики их не взяли.
+func claimDeliveries(db *gorm.DB, settings config.WebhooksConfig) ([]models.WebhookDelivery, map[uint]models.Webhook, error) {
+	var deliveries []models.WebhookDelivery
+	webhooks := make(map[uint]models.Webhook)
+	err := db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		active := tx.Model(&models.Webhook{}).Select("id").Where("active = ?", true)
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ? AND webhook_id IN (?)", DeliveryPending, now, active).
+			Order("next_attempt_at").Limit(settings.Concurrency).
+			Find(&deliveries).Error
+		if err != nil || len(deliveries) == 0 {
+			return err
+		}
+
+		ids := make([]uint, len(deliveries))
+		webhookIDs := make([]uint, len(deliveries))
+		for i, delivery := range deliveries {
+			ids[i] = delivery.ID
+			webhookIDs[i] = delivery.WebhookID
+		}
+		lease := now.Add(settings.Timeout + webhookLeaseMargin)
+		if err := tx.Model(&models.WebhookDelivery{}).Where("id IN ?", ids).Update("next_attempt_at", lease).Error; err != nil {
+			return err
+		}
+
+		var found []models.Webhook
+		if err := tx.Find(&found, webhookIDs).Error; err != nil {
+			return err
+		}
+		for _, webhook := range found {
+			webhooks[webhook.ID] = webhook
+		}
+		return nil
+	})
+	return deliveries, webhooks, err
+}
+
+// deliverWebhook отправляет событие подписчику и записывает исход попытки. Успехом считается ответ 2xx,
+// после неудачи следующая попытка назначается с паузой settings.RetryBackoff, удваивающейся с каждой попыткой.
+func deliverWebhook(db *gorm.DB, client *http.Client, settings config.WebhooksConfig, delivery *models.WebhookDelivery, webhook models.Webhook) {
+	started := time.Now()
+	status, deliveryErr := sendWebhook(client, delivery, webhook, started)
+	duration := time.Since(started)
+
+	attempt := models.WebhookAttempt{
+		CreatedAt:      started,
+		DeliveryID:     delivery.ID,
+		ResponseStatus: status,
+		DurationMs:     duration.Milliseconds(),
+	}
+	updates := map[string]interface{}{
+		"attempts":        delivery.Attempts + 1,
+		"last_attempt_at": started,
+		"response_status": status,
+		"last_error":      "",
+	}
+	outcome := metrics.WebhookDelivered
+	switch {
+	case deliveryErr == nil:
+		updates["status"] = DeliveryDelivered
+		updates["delivered_at"] = time.Now()
+		updates["next_attempt_at"] = nil
+	case delivery.Attempts+1 >= settings.MaxAttempts:
+		outcome = metrics.WebhookFailed
+		attempt.Error = deliveryErr.Error()
+		updates["status"] = DeliveryFailed
+		updates["last_error"] = attempt.Error
+		updates["next_attempt_at"] = nil
+	default:
+		outcome = metrics.WebhookRetry
+		attempt.Error = deliveryErr.Error()
+		updates["last_error"] = attempt.Error
+		updates["next_attempt_at"] = time.Now().Add(webhookBackoff(settings, delivery.Attempts+1))
+	}
+	metrics.ObserveWebhookDelivery(delivery.Event, outcome, duration)
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&attempt).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.WebhookDelivery{}).Where("id = ?", delivery.ID).Updates(updates).Error
+	})
+	if err != nil {
+		slog.Warn("Failed to record webhook delivery attempt", "delivery_id", delivery.ID, "error", err)
+		return
+	}
+	if deliveryErr != nil {
+		slog.Info("Webhook delivery attempt failed", "webhook_id", webhook.ID, "delivery_id", delivery.ID,
+			"event", delivery.Event, "attempt", delivery.Attempts+1, "outcome", outcome, "error", deliveryErr)
+		return
+	}
+	slog.Debug("Webhook delivered", "webhook_id", webhook.ID, "delivery_id", delivery.ID, "event", delivery.Event,
+		"status", status, "duration", duration.String())
+}
+
+// sendWebhook отправляет тело доставки на адрес подписки и возвращает код ответа.
+// Тело подписывается HMAC-SHA256 секретом подписки от строки "<timestamp>.<body>".
+func sendWebhook(client *http.Client, delivery *models.WebhookDelivery, webhook models.Webhook, now time.Time) (int, error) {
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	request, err := http.NewRequestWithContext(context.Background(), http.MethodPost, webhook.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", "music-library-webhooks")
+	request.Header.Set(HeaderWebhookEvent, delivery.Event)
+	request.Header.Set(HeaderWebhookDelivery, strconv.FormatUint(uint64(delivery.ID), 10))
+	request.Header.Set(HeaderWebhookTimestamp, timestamp)
+	request.Header.Set(HeaderWebhookSignature, "sha256="+SignWebhook(webhook.Secret, timestamp, delivery.Payload))
+
+	response, err := client.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(response.Body, webhookErrorBodyLimit))
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return response.StatusCode, fmt.Errorf("unexpected status %d: %s", response.StatusCode, bytes.TrimSpace(body))
+	}
+	return response.StatusCode, nil
+}
+
+// SignWebhook возвращает подпись тела события в шестнадцатеричном виде. Подписчик проверяет заголовок
+// X-Webhook-Signature, вычисляя ту же подпись по заголовку X-Webhook-Timestamp и телу запроса.
+func SignWebhook(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// webhookBackoff возвращает паузу перед попыткой после attempts неудачных.
+func webhookBackoff(settings config.WebhooksConfig, attempts int) time.Duration {
+	backoff := settings.RetryBackoff
+	for i := 1; i < attempts && backoff < settings.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, settings.MaxBackoff)
+}
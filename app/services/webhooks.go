@@ -0,0 +1,239 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+	"music-library/app/models"
+)
+
+// Статусы доставки события.
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryFailed    = "failed"
+)
+
+// webhookSecretPrefix начинает сгенерированные секреты подписи.
+const webhookSecretPrefix = "whsec_"
+
+var (
+	// ErrWebhookNotFound возвращается, если подписка с указанным ID отсутствует.
+	ErrWebhookNotFound = errors.New("webhook not found")
+	// ErrDeliveryNotFound возвращается, если у подписки нет доставки с указанным ID.
+	ErrDeliveryNotFound = errors.New("webhook delivery not found")
+	// ErrInvalidWebhook возвращается, если адрес подписки не http или https или событие неизвестно.
+	ErrInvalidWebhook = errors.New("webhook must have an absolute http or https URL and known events")
+	// ErrInvalidDeliveryStatus возвращается для неизвестного статуса в фильтре доставок.
+	ErrInvalidDeliveryStatus = errors.New("status must be pending, delivered or failed")
+)
+
+// CreateWebhook создает подписку и возвращает ее вместе с секретом подписи.
+func CreateWebhook(db *gorm.DB, request models.WebhookRequest) (models.CreatedWebhook, error) {
+	if !validWebhookURL(request.URL) || !validWebhookEvents(request.Events) {
+		return models.CreatedWebhook{}, ErrInvalidWebhook
+	}
+	secret := request.Secret
+	if secret == "" {
+		var raw [24]byte
+		if _, err := rand.Read(raw[:]); err != nil {
+			return models.CreatedWebhook{}, err
+		}
+		secret = webhookSecretPrefix + base64.RawURLEncoding.EncodeToString(raw[:])
+	}
+
+	webhook := models.Webhook{
+		URL:    request.URL,
+		Secret: secret,
+		Events: request.Events,
+		Active: request.Active == nil || *request.Active,
+	}
+	if request.Description != nil {
+		webhook.Description = *request.Description
+	}
+	if err := db.Create(&webhook).Error; err != nil {
+		return models.CreatedWebhook{}, err
+	}
+	slog.InfoContext(db.Statement.Context, "Created webhook", "webhook_id", webhook.ID, "url", webhook.URL, "events", request.Events)
+	return models.CreatedWebhook{Webhook: webhook, Secret: secret}, nil
+}
+
+// ListWebhooks возвращает все подписки.
+func ListWebhooks(db *gorm.DB) ([]models.Webhook, error) {
+	var webhooks []models.Webhook
+	err := db.Order("id").Find(&webhooks).Error
+	return webhooks, err
+}
+
+// GetWebhook загружает подписку по ID.
+func GetWebhook(db *gorm.DB, id uint) (models.Webhook, error) {
+	var webhook models.Webhook
+	err := db.First(&webhook, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return webhook, ErrWebhookNotFound
+	}
+	return webhook, err
+}
+
+// UpdateWebhook меняет адрес, события, активность, описание или секрет подписки. Пустые поля не меняются.
+// Уже созданные доставки отправляются по новому адресу с новым секретом.
+func UpdateWebhook(db *gorm.DB, id uint, request models.WebhookRequest) (models.Webhook, error) {
+	webhook, err := GetWebhook(db, id)
+	if err != nil {
+		return webhook, err
+	}
+	if request.URL != "" {
+		if !validWebhookURL(request.URL) {
+			return webhook, ErrInvalidWebhook
+		}
+		webhook.URL = request.URL
+	}
+	if request.Events != nil {
+		if !validWebhookEvents(request.Events) {
+			return webhook, ErrInvalidWebhook
+		}
+		webhook.Events = request.Events
+	}
+	if request.Active != nil {
+		webhook.Active = *request.Active
+	}
+	if request.Description != nil {
+		webhook.Description = *request.Description
+	}
+	if request.Secret != "" {
+		webhook.Secret = request.Secret
+	}
+	if err := db.Save(&webhook).Error; err != nil {
+		return webhook, err
+	}
+	slog.InfoContext(db.Statement.Context, "Updated webhook", "webhook_id", webhook.ID, "active", webhook.Active)
+	return webhook, nil
+}
+
+// DeleteWebhook удаляет подписку вместе с ее доставками.
+func DeleteWebhook(db *gorm.DB, id uint) error {
+	result := db.Delete(&models.Webhook{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrWebhookNotFound
+	}
+	slog.InfoContext(db.Statement.Context, "Deleted webhook", "webhook_id", id)
+	return nil
+}
+
+// ListDeliveries возвращает доставки подписки от новых к старым, при непустом status - только с этим статусом.
+func ListDeliveries(db *gorm.DB, webhookID uint, status string, limit, offset int) ([]models.WebhookDelivery, error) {
+	if status != "" && status != DeliveryPending && status != DeliveryDelivered && status != DeliveryFailed {
+		return nil, ErrInvalidDeliveryStatus
+	}
+	if _, err := GetWebhook(db, webhookID); err != nil {
+		return nil, err
+	}
+
+	query := db.Where("webhook_id = ?", webhookID).Order("id DESC").Offset(offset)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	deliveries := []models.WebhookDelivery{}
+	err := query.Find(&deliveries).Error
+	return deliveries, err
+}
+
+// GetDelivery загружает доставку подписки вместе с журналом попыток.
+func GetDelivery(db *gorm.DB, webhookID, deliveryID uint) (models.WebhookDelivery, error) {
+	var delivery models.WebhookDelivery
+	err := db.Preload("Log", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Where("webhook_id = ?", webhookID).First(&delivery, deliveryID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return delivery, ErrDeliveryNotFound
+	}
+	return delivery, err
+}
+
+// Redeliver ставит доставку в очередь заново с полным числом попыток, в каком бы статусе она ни была.
+func Redeliver(db *gorm.DB, webhookID, deliveryID uint) (models.WebhookDelivery, error) {
+	now := time.Now()
+	result := db.Model(&models.WebhookDelivery{}).
+		Where("id = ? AND webhook_id = ?", deliveryID, webhookID).
+		Updates(map[string]interface{}{
+			"status":          DeliveryPending,
+			"attempts":        0,
+			"next_attempt_at": now,
+			"delivered_at":    nil,
+		})
+	if result.Error != nil {
+		return models.WebhookDelivery{}, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return models.WebhookDelivery{}, ErrDeliveryNotFound
+	}
+	slog.InfoContext(db.Statement.Context, "Webhook delivery queued for redelivery", "webhook_id", webhookID, "delivery_id", deliveryID)
+	wakeWebhookDispatcher()
+	return GetDelivery(db, webhookID, deliveryID)
+}
+
+// queueDeliveries ставит событие в очередь доставки всем активным подпискам на него.
+// Вызывается в транзакции изменения песни: доставки сохраняются или откатываются вместе с изменением
+// и не теряются, даже если подписчик недоступен.
+func queueDeliveries(tx *gorm.DB, event *models.SongEvent) error {
+	var webhooks []models.Webhook
+	if err := tx.Where("active = ?", true).Find(&webhooks).Error; err != nil {
+		return err
+	}
+	webhooks = slices.DeleteFunc(webhooks, func(webhook models.Webhook) bool {
+		return !slices.Contains(webhook.Events, event.Type)
+	})
+	if len(webhooks) == 0 {
+		return nil
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	deliveries := make([]models.WebhookDelivery, len(webhooks))
+	for i, webhook := range webhooks {
+		deliveries[i] = models.WebhookDelivery{
+			WebhookID:     webhook.ID,
+			EventID:       strconv.FormatUint(uint64(event.ID), 10),
+			Event:         event.Type,
+			SongID:        event.SongID,
+			Payload:       payload,
+			Status:        DeliveryPending,
+			NextAttemptAt: &event.CreatedAt,
+		}
+	}
+	return tx.Create(&deliveries).Error
+}
+
+// validWebhookURL проверяет, что адрес подписки - абсолютный адрес http или https.
+func validWebhookURL(raw string) bool {
+	parsed, err := url.Parse(raw)
+	return err == nil && (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != ""
+}
+
+// validWebhookEvents проверяет, что список событий не пуст и состоит из известных событий.
+func validWebhookEvents(events []string) bool {
+	if len(events) == 0 {
+		return false
+	}
+	for _, event := range events {
+		if !slices.Contains(SongEventTypes, event) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,56 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+)
+
+// ErrShuttingDown возвращается фоновыми заданиями, прерванными остановкой сервиса.
+var ErrShuttingDown = errors.New("interrupted by service shutdown")
+
+var (
+	workers     sync.WaitGroup
+	stopOnce    sync.Once
+	stopWorkers = make(chan struct{})
+)
+
+// startWorker запускает фоновое задание, завершения которого StopWorkers дожидается при остановке сервиса.
+func startWorker(fn func()) {
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		fn()
+	}()
+}
+
+// stopRequested сообщает, что сервис останавливается и фоновым заданиям пора прерваться.
+func stopRequested() bool {
+	select {
+	case <-stopWorkers:
+		return true
+	default:
+		return false
+	}
+}
+
+// StopWorkers просит фоновые задания прерваться на ближайшей безопасной точке и ждет их завершения,
+// но не дольше срока ctx.
+func StopWorkers(ctx context.Context) error {
+	stopOnce.Do(func() { close(stopWorkers) })
+
+	done := make(chan struct{})
+	go func() {
+		workers.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		slog.Info("Background workers did not stop before the shutdown deadline")
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,205 @@
+// Package textdiff строит построчный diff двух текстов алгоритмом Майерса
+// и выводит его в формате unified diff или списком фрагментов.
+package textdiff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Виды строк diff.
+const (
+	Equal  = ' ' // Строка есть в обоих текстах
+	Delete = '-' // Строка есть только в старом тексте
+	Insert = '+' // Строка есть только в новом тексте
+)
+
+// DefaultContext - количество неизмененных строк вокруг изменений во фрагменте.
+const DefaultContext = 3
+
+// Line - строка diff.
+type Line struct {
+	Kind byte   // Equal, Delete или Insert
+	Text string // Содержимое строки без перевода строки
+}
+
+// Hunk - фрагмент diff с изменениями и окружающими их строками.
+type Hunk struct {
+	OldStart int    // Номер первой строки фрагмента в старом тексте, с единицы
+	OldLines int    // Количество строк фрагмента в старом тексте
+	NewStart int    // Номер первой строки фрагмента в новом тексте, с единицы
+	NewLines int    // Количество строк фрагмента в новом тексте
+	Lines    []Line // Строки фрагмента
+}
+
+// Lines разбивает текст на строки. Завершающий перевод строки не дает пустой строки в конце.
+func Lines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// Diff возвращает кратчайшую последовательность правок, превращающую a в b.
+func Diff(a, b []string) []Line {
+	n, m := len(a), len(b)
+	max := n + m
+	if max == 0 {
+		return nil
+	}
+
+	// v[k+max] - самая дальняя x на диагонали k, trace хранит v после каждого шага d.
+	v := make([]int, 2*max+2)
+	var trace [][]int
+	for d := 0; d <= max; d++ {
+		trace = append(trace, append([]int(nil), v...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[k-1+max] < v[k+1+max]) {
+				x = v[k+1+max]
+			} else {
+				x = v[k-1+max] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[k+max] = x
+			if x >= n && y >= m {
+				return backtrack(a, b, trace, d, max)
+			}
+		}
+	}
+	return nil
+}
+
+// backtrack восстанавливает правки по сохраненным шагам алгоритма.
+func backtrack(a, b []string, trace [][]int, d, max int) []Line {
+	var reversed []Line
+	x, y := len(a), len(b)
+	for ; d > 0; d-- {
+		v := trace[d]
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && v[k-1+max] < v[k+1+max]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[prevK+max]
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			x--
+			y--
+			reversed = append(reversed, Line{Kind: Equal, Text: a[x]})
+		}
+		if x == prevX {
+			y--
+			reversed = append(reversed, Line{Kind: Insert, Text: b[y]})
+		} else {
+			x--
+			reversed = append(reversed, Line{Kind: Delete, Text: a[x]})
+		}
+	}
+	for x > 0 && y > 0 {
+		x--
+		y--
+		reversed = append(reversed, Line{Kind: Equal, Text: a[x]})
+	}
+
+	lines := make([]Line, len(reversed))
+	for i, line := range reversed {
+		lines[len(reversed)-1-i] = line
+	}
+	return lines
+}
+
+// Hunks группирует правки во фрагменты с context неизмененных строк вокруг изменений.
+func Hunks(lines []Line, context int) []Hunk {
+	var hunks []Hunk
+	oldLine, newLine := 1, 1
+	for i := 0; i < len(lines); {
+		if lines[i].Kind == Equal {
+			oldLine++
+			newLine++
+			i++
+			continue
+		}
+
+		// Фрагмент начинается за context строк до изменения и продолжается, пока изменения
+		// разделены не больше чем 2*context неизмененными строками.
+		start := i
+		for start > 0 && i-start < context && lines[start-1].Kind == Equal {
+			start--
+		}
+		end := i
+		for end < len(lines) {
+			if lines[end].Kind != Equal {
+				end++
+				continue
+			}
+			run := end
+			for run < len(lines) && lines[run].Kind == Equal {
+				run++
+			}
+			if run == len(lines) || run-end > 2*context {
+				end += min(context, run-end)
+				break
+			}
+			end = run
+		}
+
+		hunk := Hunk{OldStart: oldLine - (i - start), NewStart: newLine - (i - start), Lines: lines[start:end]}
+		for _, line := range hunk.Lines {
+			if line.Kind != Insert {
+				hunk.OldLines++
+			}
+			if line.Kind != Delete {
+				hunk.NewLines++
+			}
+		}
+		for _, line := range lines[i:end] {
+			if line.Kind != Insert {
+				oldLine++
+			}
+			if line.Kind != Delete {
+				newLine++
+			}
+		}
+		hunks = append(hunks, hunk)
+		i = end
+	}
+	return hunks
+}
+
+// Unified выводит фрагменты в формате unified diff с заголовками fromName и toName.
+func Unified(fromName, toName string, hunks []Hunk) string {
+	if len(hunks) == 0 {
+		return ""
+	}
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", fromName, toName)
+	for _, hunk := range hunks {
+		fmt.Fprintf(&out, "@@ -%s +%s @@\n", hunkRange(hunk.OldStart, hunk.OldLines), hunkRange(hunk.NewStart, hunk.NewLines))
+		for _, line := range hunk.Lines {
+			out.WriteByte(line.Kind)
+			out.WriteString(line.Text)
+			out.WriteByte('\n')
+		}
+	}
+	return out.String()
+}
+
+// hunkRange выводит диапазон строк фрагмента. Пустой диапазон указывает на строку перед ним.
+func hunkRange(start, lines int) string {
+	switch lines {
+	case 0:
+		return fmt.Sprintf("%d,0", start-1)
+	case 1:
+		return fmt.Sprint(start)
+	default:
+		return fmt.Sprintf("%d,%d", start, lines)
+	}
+}
@@ -0,0 +1,92 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+// Ключи, под которыми в Statement хранятся спан запроса и исходный контекст.
+const (
+	spanKey          = "tracing:span"
+	parentContextKey = "tracing:parent_context"
+)
+
+// InstrumentDatabase подключает к GORM спаны для каждого запроса к базе данных.
+// Спан становится дочерним для контекста, переданного в WithContext.
+func InstrumentDatabase(db *gorm.DB) error {
+	callbacks := db.Callback()
+	hooks := []struct {
+		operation     string
+		before, after callbackRegistrar
+	}{
+		{"create", callbacks.Create().Before("gorm:create"), callbacks.Create().After("gorm:create")},
+		{"query", callbacks.Query().Before("gorm:query"), callbacks.Query().After("gorm:query")},
+		{"update", callbacks.Update().Before("gorm:update"), callbacks.Update().After("gorm:update")},
+		{"delete", callbacks.Delete().Before("gorm:delete"), callbacks.Delete().After("gorm:delete")},
+		{"row", callbacks.Row().Before("gorm:row"), callbacks.Row().After("gorm:row")},
+		{"raw", callbacks.Raw().Before("gorm:raw"), callbacks.Raw().After("gorm:raw")},
+	}
+	system := db.Dialector.Name()
+	if system == "postgres" {
+		system = "postgresql"
+	}
+	for _, hook := range hooks {
+		if err := hook.before.Register("tracing:before_"+hook.operation, startSpan(hook.operation, system)); err != nil {
+			return err
+		}
+		if err := hook.after.Register("tracing:after_"+hook.operation, endSpan); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// callbackRegistrar - точка регистрации обработчика в цепочке колбэков GORM.
+type callbackRegistrar interface {
+	Register(name string, fn func(*gorm.DB)) error
+}
+
+func startSpan(operation, system string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		parent := db.Statement.Context
+		if parent == nil {
+			parent = context.Background()
+		}
+		ctx, span := Tracer().Start(parent, "gorm."+operation,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				attribute.String("db.system", system),
+				attribute.String("db.operation", operation),
+			))
+		db.InstanceSet(spanKey, span)
+		db.InstanceSet(parentContextKey, parent)
+		db.Statement.Context = ctx
+	}
+}
+
+func endSpan(db *gorm.DB) {
+	value, ok := db.InstanceGet(spanKey)
+	if !ok {
+		return
+	}
+	span := value.(trace.Span)
+	defer span.End()
+	if parent, ok := db.InstanceGet(parentContextKey); ok {
+		db.Statement.Context = parent.(context.Context)
+	}
+
+	span.SetAttributes(
+		attribute.String("db.sql.table", db.Statement.Table),
+		attribute.String("db.statement", db.Statement.SQL.String()),
+		attribute.Int64("db.rows_affected", db.Statement.RowsAffected),
+	)
+	if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+		span.RecordError(db.Error)
+		span.SetStatus(codes.Error, db.Error.Error())
+	}
+}
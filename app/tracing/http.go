@@ -0,0 +1,42 @@
+package tracing
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gorilla/mux/otelmux"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Middleware создает серверный спан для каждого маршрута роутера с именем по шаблону маршрута,
+// продолжая трассу из заголовка traceparent входящего запроса.
+func Middleware(next http.Handler) http.Handler {
+	return otelmux.Middleware(instrumentationName)(routeAttributes(next))
+}
+
+// routeAttributes добавляет к спану ID ресурса из пути запроса.
+func routeAttributes(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		span := trace.SpanFromContext(r.Context())
+		if id, ok := mux.Vars(r)["id"]; ok && span.IsRecording() {
+			template, _ := mux.CurrentRoute(r).GetPathTemplate()
+			number, err := strconv.ParseInt(id, 10, 64)
+			switch {
+			case err != nil:
+			case strings.HasPrefix(template, "/songs/"):
+				span.SetAttributes(AttrSongID.Int64(number))
+			case strings.HasPrefix(template, "/imports/"):
+				span.SetAttributes(AttrImportJobID.Int64(number))
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Transport оборачивает base клиентскими спанами и передает контекст трассировки в исходящих запросах.
+func Transport(base http.RoundTripper) http.RoundTripper {
+	return otelhttp.NewTransport(base)
+}
@@ -0,0 +1,95 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"music-library/app/config"
+	"music-library/app/health"
+	"music-library/app/models"
+)
+
+// instrumentationName - имя библиотеки инструментирования в спанах сервиса.
+const instrumentationName = "music-library"
+
+// Экспортеры трасс.
+const (
+	ExporterNone   = "none"   // Трассы не отправляются, но контекст трассировки передается дальше
+	ExporterOTLP   = "otlp"   // OTLP/HTTP-коллектор
+	ExporterStdout = "stdout" // JSON в стандартный вывод для локальной отладки
+)
+
+// Атрибуты спанов.
+var (
+	AttrSongID       = attribute.Key("song.id")
+	AttrSongGroup    = attribute.Key("song.group")
+	AttrSongName     = attribute.Key("song.name")
+	AttrImportJobID  = attribute.Key("import.job_id")
+	AttrProviderName = attribute.Key("provider.name")
+)
+
+// Tracer возвращает трассировщик сервиса. До вызова Setup спаны не записываются.
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// Setup настраивает распространение контекста W3C Trace Context и экспорт трасс по настройкам.
+// Возвращаемая функция отправляет накопленные спаны и останавливает экспорт.
+func Setup(ctx context.Context, settings config.TracingConfig) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch settings.Exporter {
+	case ExporterNone:
+		return func(context.Context) error { return nil }, nil
+	case ExporterOTLP:
+		exporter, err = otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(settings.Endpoint))
+	case ExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	default:
+		return nil, fmt.Errorf("unknown trace exporter %q", settings.Exporter)
+	}
+	if err != nil {
+		return nil, err
+	}
+	provider := Install(exporter, settings.ServiceName, settings.SampleRatio)
+	return provider.Shutdown, nil
+}
+
+// Install делает провайдером трасс по умолчанию провайдер, отправляющий спаны в exporter.
+// Вместе с tracetest.NewInMemoryExporter позволяет проверять трассы без коллектора.
+func Install(exporter sdktrace.SpanExporter, serviceName string, sampleRatio float64) *sdktrace.TracerProvider {
+	attributes := resource.NewSchemaless(
+		attribute.String("service.name", serviceName),
+		attribute.String("service.version", health.Version),
+	)
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(attributes),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(sampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	return provider
+}
+
+// SetSongAttributes добавляет к текущему спану ID, группу и название песни.
+func SetSongAttributes(ctx context.Context, song models.Song) {
+	span := trace.SpanFromContext(ctx)
+	if !span.IsRecording() {
+		return
+	}
+	if song.ID != 0 {
+		span.SetAttributes(AttrSongID.Int64(int64(song.ID)))
+	}
+	span.SetAttributes(AttrSongGroup.String(song.Group), AttrSongName.String(song.Name))
+}
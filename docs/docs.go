@@ -15,60 +15,25 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/songs": {
+        "/admin/api-keys": {
             "get": {
-                "description": "Retrieve a list of songs based on optional group and song name filters, with pagination support using limit and offset parameters.",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Возвращает все ключи доступа, включая отозванные и просроченные. Сами ключи не возвращаются.",
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Get a list of songs",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Group name (artist)",
-                        "name": "group",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Song name",
-                        "name": "song",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Limit the number of songs returned",
-                        "name": "limit",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Offset the returned songs by this amount",
-                        "name": "offset",
-                        "in": "query"
-                    }
-                ],
+                "summary": "Список ключей доступа",
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "Ключи доступа",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/models.Song"
+                                "$ref": "#/definitions/models.APIKey"
                             }
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/models.ErrorResponse"
-                        }
-                    },
                     "500": {
-                        "description": "Internal Server Error",
+                        "description": "Внутренняя ошибка сервера",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -76,30 +41,30 @@ const docTemplate = `{
                 }
             },
             "post": {
-                "description": "Добавляет новую песню в базу данных, сначала запрашивая информацию из внешнего API.",
+                "description": "Создает ключ с указанными разрешениями: songs:read, songs:write, songs:delete или admin.\nВ rateLimits можно задать собственные лимиты запросов за период для классов read, write и enrich.\nКлюч возвращается только в этом ответе, в базе данных хранится лишь его хеш.",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Добавление новой песни",
+                "summary": "Создание ключа доступа",
                 "parameters": [
                     {
-                        "description": "Данные о песне",
-                        "name": "song",
+                        "description": "Параметры ключа",
+                        "name": "key",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.Song"
+                            "$ref": "#/definitions/models.CreateAPIKeyRequest"
                         }
                     }
                 ],
                 "responses": {
                     "201": {
-                        "description": "Добавленная песня",
+                        "description": "Созданный ключ",
                         "schema": {
-                            "$ref": "#/definitions/models.Song"
+                            "$ref": "#/definitions/models.CreatedAPIKey"
                         }
                     },
                     "400": {
@@ -117,37 +82,95 @@ const docTemplate = `{
                 }
             }
         },
-        "/songs/{id}": {
-            "put": {
-                "description": "Обновляет данные песни в базе данных по указанному идентификатору.",
-                "consumes": [
-                    "application/json"
-                ],
+        "/admin/api-keys/{id}": {
+            "delete": {
+                "description": "Отзывает ключ доступа, после чего запросы с ним отклоняются. Повторный отзыв ничего не меняет.",
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Обновление песни по ID",
+                "summary": "Отзыв ключа доступа",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "ID песни",
+                        "description": "ID ключа",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Ключ отозван"
                     },
-                    {
-                        "description": "Данные песни для обновления",
-                        "name": "song",
-                        "in": "body",
-                        "required": true,
+                    "404": {
+                        "description": "Ключ не найден",
                         "schema": {
-                            "$ref": "#/definitions/models.Song"
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     }
+                }
+            }
+        },
+        "/admin/backup": {
+            "get": {
+                "description": "Возвращает сжатый tar-архив с манифестом (версия формата, версия схемы, контрольные суммы)\nи файлами NDJSON всех таблиц базы: песни, задания импорта, ревизии, журналы изменений и событий,\nподписки с доставками, пользователи, токены обновления и ключи доступа (вместе с хешами паролей, ключей и секретами подписок).",
+                "produces": [
+                    "application/gzip"
                 ],
+                "summary": "Резервная копия библиотеки",
                 "responses": {
-                    "204": {
-                        "description": "Песня успешно обновлена"
+                    "200": {
+                        "description": "Архив резервной копии",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/restore": {
+            "post": {
+                "description": "Принимает архив, созданный GET /admin/backup или командой backup, в теле запроса или в поле file формы.\nАрхив проверяется целиком до изменения данных.\nВ режиме empty все таблицы базы, включая пользователей и ключи доступа, должны быть пустыми, а записи сохраняют ID.\nПри включенной авторизации в базе уже есть администратор, поэтому в пустую базу восстанавливают командой restore.\nВ режиме merge песни и задания импорта добавляются с новыми ID, песни с той же группой и названием пропускаются,\nа остальные таблицы архива не переносятся.",
+                "consumes": [
+                    "application/gzip",
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Восстановление из резервной копии",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Режим восстановления: empty (по умолчанию) или merge",
+                        "name": "mode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "file",
+                        "description": "Архив резервной копии",
+                        "name": "file",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Итог восстановления",
+                        "schema": {
+                            "$ref": "#/definitions/models.RestoreResult"
+                        }
                     },
                     "400": {
                         "description": "Ошибка в запросе",
@@ -155,8 +178,20 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Песня не найдена",
+                    "409": {
+                        "description": "Режим empty, а база данных не пуста",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Архив слишком большой",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Архив поврежден или снят с более новой схемы",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -168,28 +203,67 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Удаляет песню из базы данных по указанному идентификатору.",
+            }
+        },
+        "/admin/users": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Список пользователей",
+                "responses": {
+                    "200": {
+                        "description": "Пользователи",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.User"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/users/{id}": {
+            "patch": {
+                "description": "Новая роль попадает в токен доступа пользователя при следующем обновлении токенов.",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Удаление песни по ID",
+                "summary": "Изменение роли пользователя",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "ID песни",
+                        "description": "ID пользователя",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Новая роль",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateUserRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "Песня успешно удалена"
+                    "200": {
+                        "description": "Пользователь",
+                        "schema": {
+                            "$ref": "#/definitions/models.User"
+                        }
                     },
                     "400": {
                         "description": "Ошибка в запросе",
@@ -198,7 +272,7 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "Песня не найдена",
+                        "description": "Пользователь не найден",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -212,69 +286,2970 @@ const docTemplate = `{
                 }
             }
         },
-        "/songs/{id}/text": {
+        "/audit": {
             "get": {
-                "description": "Получает текст песни по ее идентификатору и поддерживает пагинацию для возвращения определённого количества куплетов на странице.",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Возвращает записи журнала изменений всех песен от новых к старым с фильтрами по участнику,\nоперации, песне и интервалу времени [from, to).",
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Получение текста песни с пагинацией по куплетам",
+                "summary": "Журнал изменений",
                 "parameters": [
                     {
                         "type": "string",
+                        "description": "Участник: user:\u003cid\u003e, api_key:\u003cid\u003e или system",
+                        "name": "actor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Операция: create, update, delete или restore",
+                        "name": "operation",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
                         "description": "ID песни",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "name": "songId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Начало интервала в RFC 3339",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Конец интервала в RFC 3339",
+                        "name": "to",
+                        "in": "query"
                     },
                     {
                         "type": "integer",
-                        "description": "Номер страницы для получения (индексация с единицы)",
-                        "name": "page",
+                        "description": "Максимальное количество записей",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Количество пропускаемых записей",
+                        "name": "offset",
                         "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Список куплетов",
+                        "description": "Записи журнала",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "type": "string"
+                                "$ref": "#/definitions/models.AuditEntry"
                             }
                         }
                     },
                     "400": {
-                        "description": "Неверный запрос, ошибка в параметрах",
+                        "description": "Ошибка в запросе",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Песня не найдена",
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "models.ErrorResponse": {
-            "description": "Структура ответа для ошибок API.",
-            "type": "object",
-            "properties": {
-                "code": {
-                    "description": "Код ошибки",
-                    "type": "integer"
-                },
-                "message": {
-                    "description": "Сообщение об ошибке",
-                    "type": "string"
+        },
+        "/auth/login": {
+            "post": {
+                "description": "Проверяет пароль и возвращает короткоживущий токен доступа JWT и одноразовый токен обновления.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Вход пользователя",
+                "parameters": [
+                    {
+                        "description": "Имя пользователя и пароль",
+                        "name": "credentials",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Credentials"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Токены",
+                        "schema": {
+                            "$ref": "#/definitions/models.TokenResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка в запросе",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Неверное имя пользователя или пароль",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/logout": {
+            "post": {
+                "description": "Удаляет токен обновления. Выданный токен доступа действует до истечения срока.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Выход пользователя",
+                "parameters": [
+                    {
+                        "description": "Токен обновления",
+                        "name": "token",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.RefreshRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Сессия закрыта"
+                    },
+                    "400": {
+                        "description": "Ошибка в запросе",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/me": {
+            "get": {
+                "description": "Возвращает вид участника (user, api_key или anonymous), его имя, роль и разрешения.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Текущий участник",
+                "responses": {
+                    "200": {
+                        "description": "Участник запроса",
+                        "schema": {
+                            "$ref": "#/definitions/models.Identity"
+                        }
+                    },
+                    "401": {
+                        "description": "Учетные данные не переданы или недействительны",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/refresh": {
+            "post": {
+                "description": "Выдает новый токен доступа и новый токен обновления, прежний токен обновления перестает действовать.\nПовторное использование обмененного токена закрывает все сессии пользователя.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Обновление токенов",
+                "parameters": [
+                    {
+                        "description": "Токен обновления",
+                        "name": "token",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.RefreshRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Токены",
+                        "schema": {
+                            "$ref": "#/definitions/models.TokenResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка в запросе",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Токен обновления недействителен",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/register": {
+            "post": {
+                "description": "Создает пользователя с ролью viewer. Роль меняет администратор через PATCH /admin/users/{id}.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Регистрация пользователя",
+                "parameters": [
+                    {
+                        "description": "Имя пользователя и пароль",
+                        "name": "credentials",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Credentials"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Зарегистрированный пользователь",
+                        "schema": {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка в запросе",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Регистрация отключена",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Имя пользователя занято",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/events": {
+            "get": {
+                "description": "Отдает события song.created, song.updated, song.deleted и song.enriched по мере изменений в формате\ntext/event-stream: id - ID события в журнале, event - тип, data - models.SongEvent в JSON.\nС заголовком Last-Event-ID (или параметром lastEventId) поток сначала отдает пропущенные события\nиз журнала, без него - только новые. Пока событий нет, раз в events.heartbeat приходит комментарий.\nКлиент, не успевающий принимать события, и клиенты останавливающегося сервиса отключаются\nи продолжают с Last-Event-ID.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "summary": "Поток событий песен",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID последнего полученного события",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID последнего полученного события, если заголовок задать нельзя",
+                        "name": "lastEventId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Типы событий через запятую",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Группа, без учета регистра",
+                        "name": "group",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Поток событий",
+                        "schema": {
+                            "$ref": "#/definitions/models.SongEvent"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка в запросе",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/graphql": {
+            "get": {
+                "description": "Выполняет запрос или мутацию GraphQL по схеме app/graph/schema.graphqls: список песен с фильтрами,\nпоиском и пагинацией, песня по ID с исполнителем, статусом обогащения и куплетами постранично,\nмутации addSong, updateSong и deleteSong. Маршрут требует songs:read, мутации дополнительно\nпроверяют songs:write или songs:delete. GET принимает только запросы без мутаций.\nЗапросы глубже graphql.maxDepth или сложнее graphql.maxComplexity отклоняются до выполнения\nс кодом DEPTH_LIMIT_EXCEEDED или COMPLEXITY_LIMIT_EXCEEDED.\nОшибки возвращаются в поле errors с кодом в extensions.code. Запросы методом POST учитываются\nв ограничении частоты изменений rateLimit.write, методом GET - в ограничении чтения.\nКаждая мутация addSong обращается к внешнему API и дополнительно расходует лимит rateLimit.enrich,\nкак отдельный POST /songs. Когда он исчерпан, мутация завершается ошибкой с кодом RATE_LIMITED\nи числом секунд до появления токена в extensions.retryAfter.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Запрос GraphQL",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Запрос для метода GET",
+                        "name": "query",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Переменные запроса в JSON для метода GET",
+                        "name": "variables",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Ответ GraphQL с полями data и errors",
+                        "schema": {
+                            "type": "object"
+                        }
+                    },
+                    "400": {
+                        "description": "Тело запроса не разобрано",
+                        "schema": {
+                            "type": "object"
+                        }
+                    },
+                    "422": {
+                        "description": "Запрос не прошел проверку по схеме",
+                        "schema": {
+                            "type": "object"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Выполняет запрос или мутацию GraphQL по схеме app/graph/schema.graphqls: список песен с фильтрами,\nпоиском и пагинацией, песня по ID с исполнителем, статусом обогащения и куплетами постранично,\nмутации addSong, updateSong и deleteSong. Маршрут требует songs:read, мутации дополнительно\nпроверяют songs:write или songs:delete. GET принимает только запросы без мутаций.\nЗапросы глубже graphql.maxDepth или сложнее graphql.maxComplexity отклоняются до выполнения\nс кодом DEPTH_LIMIT_EXCEEDED или COMPLEXITY_LIMIT_EXCEEDED.\nОшибки возвращаются в поле errors с кодом в extensions.code. Запросы методом POST учитываются\nв ограничении частоты изменений rateLimit.write, методом GET - в ограничении чтения.\nКаждая мутация addSong обращается к внешнему API и дополнительно расходует лимит rateLimit.enrich,\nкак отдельный POST /songs. Когда он исчерпан, мутация завершается ошибкой с кодом RATE_LIMITED\nи числом секунд до появления токена в extensions.retryAfter.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Запрос GraphQL",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Запрос для метода GET",
+                        "name": "query",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Переменные запроса в JSON для метода GET",
+                        "name": "variables",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Ответ GraphQL с полями data и errors",
+                        "schema": {
+                            "type": "object"
+                        }
+                    },
+                    "400": {
+                        "description": "Тело запроса не разобрано",
+                        "schema": {
+                            "type": "object"
+                        }
+                    },
+                    "422": {
+                        "description": "Запрос не прошел проверку по схеме",
+                        "schema": {
+                            "type": "object"
+                        }
+                    }
+                }
+            }
+        },
+        "/healthz": {
+            "get": {
+                "description": "Отвечает 200, пока процесс способен обрабатывать запросы. Зависимости не проверяются.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Проверка жизнеспособности",
+                "responses": {
+                    "200": {
+                        "description": "Процесс работает",
+                        "schema": {
+                            "$ref": "#/definitions/models.HealthResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/imports": {
+            "post": {
+                "description": "Принимает файл в теле запроса или в поле file формы multipart/form-data.\nВ пробном запуске (dryRun=true) данные не изменяются, а в ответе перечислено, что будет создано, обновлено или пропущено.\nИначе задание выполняется в фоне, его прогресс доступен через GET /imports/{id}.",
+                "consumes": [
+                    "text/csv",
+                    "application/json",
+                    "application/x-ndjson",
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Импорт каталога из файла",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Формат файла: csv, json или ndjson (по умолчанию по имени файла или Content-Type)",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Имя файла, если он передан в теле запроса",
+                        "name": "fileName",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Сопоставление полей песни с колонками, например group=Artist,song=Title",
+                        "name": "mapping",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Разделитель колонок CSV, по умолчанию запятая",
+                        "name": "delimiter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Пробный запуск без изменения данных",
+                        "name": "dryRun",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Запрашивать данные новых песен во внешнем API",
+                        "name": "enrich",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Что делать с песнями, которые уже есть в библиотеке или выше в файле (та же группа и название без учета регистра): skip (по умолчанию) или update. В отличие от POST /songs, импорт не создает дубликатов",
+                        "name": "onDuplicate",
+                        "in": "query"
+                    },
+                    {
+                        "type": "file",
+                        "description": "Файл импорта",
+                        "name": "file",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Результат пробного запуска",
+                        "schema": {
+                            "$ref": "#/definitions/models.ImportReport"
+                        }
+                    },
+                    "202": {
+                        "description": "Задание импорта поставлено в очередь",
+                        "schema": {
+                            "$ref": "#/definitions/models.ImportJob"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка в запросе",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Файл слишком большой",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/imports/{id}": {
+            "get": {
+                "description": "Возвращает статус и прогресс задания импорта.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Получение задания импорта",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID задания импорта",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Задание импорта",
+                        "schema": {
+                            "$ref": "#/definitions/models.ImportJob"
+                        }
+                    },
+                    "404": {
+                        "description": "Задание не найдено",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/imports/{id}/errors": {
+            "get": {
+                "description": "Возвращает CSV-файл с номером, описанием ошибки и исходным содержимым каждой необработанной записи.",
+                "produces": [
+                    "text/csv"
+                ],
+                "summary": "Отчет об ошибках импорта",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID задания импорта",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Отчет об ошибках",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "404": {
+                        "description": "Задание не найдено",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/imports/{id}/resume": {
+            "post": {
+                "description": "Продолжает обработку задания импорта, остановленного из-за ошибки или перезапуска сервиса.\nЗадание, которое уже выполняется, возобновить нельзя. Задание, прогресс которого не сохранялся\n5 минут (например, после падения сервиса), считается прерванным.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Возобновление задания импорта",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID задания импорта",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Задание импорта возобновлено",
+                        "schema": {
+                            "$ref": "#/definitions/models.ImportJob"
+                        }
+                    },
+                    "404": {
+                        "description": "Задание не найдено",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Задание уже завершено или выполняется",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/playlists/import": {
+            "post": {
+                "description": "Записи сопоставляются с песнями по ссылке или по нормализованным исполнителю и названию.\nДля несопоставленных записей предлагаются похожие песни того же исполнителя или с тем же названием.",
+                "consumes": [
+                    "application/vnd.apple.mpegurl",
+                    "application/xspf+xml"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Импорт плейлиста",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Формат плейлиста: m3u8 или xspf (по умолчанию определяется по имени файла или содержимому)",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Имя файла плейлиста",
+                        "name": "fileName",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Результат сопоставления",
+                        "schema": {
+                            "$ref": "#/definitions/models.PlaylistImportResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка в запросе",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Плейлист слишком большой",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/readyz": {
+            "get": {
+                "description": "Проверяет доступность базы данных, актуальность схемы и доступность внешнего API.\nОтказ некритичных проверок (health.nonCriticalChecks) не делает сервис неготовым.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Проверка готовности",
+                "responses": {
+                    "200": {
+                        "description": "Сервис готов",
+                        "schema": {
+                            "$ref": "#/definitions/models.ReadinessResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Критичная зависимость недоступна",
+                        "schema": {
+                            "$ref": "#/definitions/models.ReadinessResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs": {
+            "get": {
+                "description": "Retrieve a list of songs based on optional group and song name filters, with pagination support using limit and offset parameters.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get a list of songs",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Group name (artist)",
+                        "name": "group",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Song name",
+                        "name": "song",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit the number of songs returned",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Offset the returned songs by this amount",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated list of fields to return, e.g. id,group,song",
+                        "name": "fields",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Song"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Добавляет новую песню в базу данных, сначала запрашивая информацию из внешнего API.\nНаличие песни с той же группой и названием не проверяется, POST /songs:batch отмечает такие песни как дубликаты.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Добавление новой песни",
+                "parameters": [
+                    {
+                        "description": "Данные о песне",
+                        "name": "song",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Song"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Добавленная песня",
+                        "schema": {
+                            "$ref": "#/definitions/models.Song"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка в запросе",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/export": {
+            "get": {
+                "description": "Выгружает песни с теми же фильтрами, что и GetSongs, в формате CSV, JSON, NDJSON или XLSX.\nПесни читаются из базы данных построчно и сразу отправляются клиенту. По умолчанию выгружается весь каталог.",
+                "produces": [
+                    "text/csv",
+                    "application/json",
+                    "application/x-ndjson",
+                    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+                ],
+                "summary": "Экспорт каталога",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Формат файла: csv (по умолчанию), json, ndjson или xlsx",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Группа",
+                        "name": "group",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Название песни",
+                        "name": "song",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Максимальное количество песен",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Количество пропускаемых песен",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Файл экспорта",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка в запросе",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/playlist": {
+            "get": {
+                "description": "Формирует плейлист из песен с указанными ids (в переданном порядке) или из песен, отобранных теми же фильтрами, что и GetSongs.\nВ качестве адреса записи используется ссылка на песню.",
+                "produces": [
+                    "application/vnd.apple.mpegurl",
+                    "application/xspf+xml"
+                ],
+                "summary": "Экспорт плейлиста",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Формат плейлиста: m3u8 (по умолчанию) или xspf",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Название плейлиста",
+                        "name": "title",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID песен через запятую",
+                        "name": "ids",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Группа",
+                        "name": "group",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Название песни",
+                        "name": "song",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Максимальное количество песен",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Количество пропускаемых песен",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Плейлист",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка в запросе",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/{id}": {
+            "get": {
+                "description": "Возвращает песню по ее идентификатору вместе с заголовком ETag. Поддерживает If-None-Match для условных запросов,\nвыбор полей через fields= и встраивание связанных ресурсов (artist, album, enrichment) через include=.\ninclude=translations не поддерживается: переводы текстов в библиотеке не хранятся.\nС fields или include заголовок ETag слабый и описывает именно это представление, для If-Match нужен ETag полной песни (поле etag).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Получение песни по ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID песни",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Список полей через запятую, например id,group,song",
+                        "name": "fields",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Связанные ресурсы через запятую: artist, album, enrichment",
+                        "name": "include",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag ранее полученной версии песни",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Песня",
+                        "schema": {
+                            "$ref": "#/definitions/models.Song"
+                        }
+                    },
+                    "304": {
+                        "description": "Песня не изменилась"
+                    },
+                    "400": {
+                        "description": "Неверный запрос, ошибка в параметрах",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Песня не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Обновляет данные песни в базе данных по указанному идентификатору.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Обновление песни по ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID песни",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag текущей версии песни",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Данные песни для обновления",
+                        "name": "song",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Song"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Песня успешно обновлена"
+                    },
+                    "400": {
+                        "description": "Ошибка в запросе",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Песня не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "ETag не совпадает с текущей версией",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "428": {
+                        "description": "Отсутствует заголовок If-Match",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Удаляет песню из базы данных по указанному идентификатору.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Удаление песни по ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID песни",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag текущей версии песни",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Песня успешно удалена"
+                    },
+                    "400": {
+                        "description": "Ошибка в запросе",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Редактор удаляет чужую песню",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Песня не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "ETag не совпадает с текущей версией",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "428": {
+                        "description": "Отсутствует заголовок If-Match",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Обновляет данные песни в базе данных по указанному идентификатору.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Обновление песни по ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID песни",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag текущей версии песни",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Данные песни для обновления",
+                        "name": "song",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Song"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Песня успешно обновлена"
+                    },
+                    "400": {
+                        "description": "Ошибка в запросе",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Песня не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "ETag не совпадает с текущей версией",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "428": {
+                        "description": "Отсутствует заголовок If-Match",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/{id}/history": {
+            "get": {
+                "description": "Возвращает записи журнала изменений песни от новых к старым: кто, когда и в каком запросе\nсоздал, изменил, удалил или восстановил песню, с прежними и новыми значениями полей.\nИстория удаленной песни остается доступной.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "История изменений песни",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID песни",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Максимальное количество записей",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Количество пропускаемых записей",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Записи журнала",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.AuditEntry"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка в запросе",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Песня не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/{id}/revisions": {
+            "get": {
+                "description": "Возвращает сохраненное содержимое песни для каждой ее версии, от новых ревизий к старым.\nНомер ревизии совпадает с версией песни. Ревизии удаленной песни остаются доступными.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Ревизии песни",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID песни",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Максимальное количество ревизий",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Количество пропускаемых ревизий",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Ревизии песни",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.SongRevision"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка в запросе",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Песня не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/{id}/revisions/diff": {
+            "get": {
+                "description": "Строит построчный diff текста песни между ревизиями from и to с тремя строками контекста.\nformat=unified возвращает unified diff (text/x-diff), format=json - список фрагментов.",
+                "produces": [
+                    "application/json",
+                    "text/x-diff"
+                ],
+                "summary": "Diff текста между ревизиями",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID песни",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Исходная ревизия",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Конечная ревизия",
+                        "name": "to",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Формат: json (по умолчанию) или unified",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Diff текста",
+                        "schema": {
+                            "$ref": "#/definitions/models.RevisionDiff"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка в запросе",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Ревизия не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/{id}/revisions/{rev}": {
+            "get": {
+                "description": "Возвращает содержимое песни в указанной ревизии.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Ревизия песни",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID песни",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Номер ревизии",
+                        "name": "rev",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Ревизия песни",
+                        "schema": {
+                            "$ref": "#/definitions/models.SongRevision"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка в запросе",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Ревизия не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/{id}/revisions/{rev}/revert": {
+            "post": {
+                "description": "Восстанавливает группу, название, дату релиза, текст, ссылку и альбом из указанной ревизии.\nОткат создает новую версию и новую ревизию песни, прежние ревизии не меняются.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Откат песни к ревизии",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID песни",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Номер ревизии",
+                        "name": "rev",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag текущей версии песни",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Песня после отката",
+                        "schema": {
+                            "$ref": "#/definitions/models.Song"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка в запросе",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Песня или ревизия не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "ETag не совпадает с текущей версией",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "428": {
+                        "description": "Отсутствует заголовок If-Match",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/{id}/text": {
+            "get": {
+                "description": "Получает текст песни по ее идентификатору и поддерживает пагинацию для возвращения определённого количества куплетов на странице.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Получение текста песни с пагинацией по куплетам",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID песни",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Номер страницы для получения (индексация с единицы)",
+                        "name": "page",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Список куплетов",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный запрос, ошибка в параметрах",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Песня не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs:batch": {
+            "put": {
+                "description": "Принимает массив изменений вида {\"id\", \"ifMatch\", \"changes\"} (application/json) или поток NDJSON.\nifMatch каждого элемента проверяется так же, как заголовок If-Match в UpdateSong.",
+                "consumes": [
+                    "application/json",
+                    "application/x-ndjson"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Пакетное обновление песен",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Режим выполнения: atomic или best-effort (по умолчанию)",
+                        "name": "mode",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Изменения песен",
+                        "name": "items",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.BatchUpdateItem"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Результаты по каждому элементу",
+                        "schema": {
+                            "$ref": "#/definitions/models.BatchResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка в запросе",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Пакет отменен в режиме atomic",
+                        "schema": {
+                            "$ref": "#/definitions/models.BatchResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Принимает массив песен (application/json) или поток NDJSON (application/x-ndjson).\nДля каждой песни параллельно запрашиваются данные во внешнем API, результат возвращается по индексам входных данных.\nВ режиме atomic пакет сохраняется целиком или не сохраняется вовсе, в режиме best-effort каждая песня сохраняется независимо.",
+                "consumes": [
+                    "application/json",
+                    "application/x-ndjson"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Пакетное добавление песен",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Режим выполнения: atomic или best-effort (по умолчанию)",
+                        "name": "mode",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Песни для добавления",
+                        "name": "songs",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Song"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Результаты по каждому элементу",
+                        "schema": {
+                            "$ref": "#/definitions/models.BatchResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка в запросе",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Пакет отменен в режиме atomic",
+                        "schema": {
+                            "$ref": "#/definitions/models.BatchResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Принимает массив элементов вида {\"id\", \"ifMatch\"} (application/json) или поток NDJSON.",
+                "consumes": [
+                    "application/json",
+                    "application/x-ndjson"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Пакетное удаление песен",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Режим выполнения: atomic или best-effort (по умолчанию)",
+                        "name": "mode",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Удаляемые песни",
+                        "name": "items",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.BatchDeleteItem"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Результаты по каждому элементу",
+                        "schema": {
+                            "$ref": "#/definitions/models.BatchResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка в запросе",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Пакет отменен в режиме atomic",
+                        "schema": {
+                            "$ref": "#/definitions/models.BatchResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/status": {
+            "get": {
+                "description": "Возвращает версию сборки, время работы, статистику пула соединений с базой данных\nи результаты проверок зависимостей с их задержками.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Состояние сервиса",
+                "responses": {
+                    "200": {
+                        "description": "Состояние сервиса",
+                        "schema": {
+                            "$ref": "#/definitions/models.StatusResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks": {
+            "get": {
+                "description": "Возвращает все подписки, включая неактивные. Секреты подписи не возвращаются.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Список подписок на события",
+                "responses": {
+                    "200": {
+                        "description": "Подписки",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Webhook"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Создает подписку на события song.created, song.updated, song.deleted и song.enriched. События\nсохраняются в очередь в одной транзакции с изменением песни и отправляются POST-запросом с телом\nmodels.WebhookEvent. Неудачные попытки (не 2xx) повторяются с удваивающейся паузой.\nЗаголовок X-Webhook-Signature содержит \"sha256=\" и HMAC-SHA256 секрета подписки от строки\n\"\u003cX-Webhook-Timestamp\u003e.\u003cтело запроса\u003e\". Секрет возвращается только в этом ответе.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Создание подписки на события",
+                "parameters": [
+                    {
+                        "description": "Параметры подписки",
+                        "name": "webhook",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhookRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Созданная подписка",
+                        "schema": {
+                            "$ref": "#/definitions/models.CreatedWebhook"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка в запросе",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Подписка на события",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID подписки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Подписка",
+                        "schema": {
+                            "$ref": "#/definitions/models.Webhook"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка в запросе",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Подписка не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Удаляет подписку вместе с ее доставками и журналом попыток.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Удаление подписки на события",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID подписки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Подписка удалена"
+                    },
+                    "400": {
+                        "description": "Ошибка в запросе",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Подписка не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Меняет адрес, события, активность, описание или секрет подписки. Пустые поля не меняются.\nСобытия, произошедшие, пока подписка отключена, ей не доставляются, а уже созданные доставки ждут ее включения.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Изменение подписки на события",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID подписки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Изменения подписки",
+                        "name": "webhook",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhookRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Подписка после изменения",
+                        "schema": {
+                            "$ref": "#/definitions/models.Webhook"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка в запросе",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Подписка не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}/deliveries": {
+            "get": {
+                "description": "Возвращает доставки событий подписке от новых к старым с их состоянием и итогом последней попытки.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Доставки подписки",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID подписки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Статус: pending, delivered или failed",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Максимальное количество доставок",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Количество пропускаемых доставок",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Доставки",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.WebhookDelivery"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка в запросе",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Подписка не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}/deliveries/{deliveryId}": {
+            "get": {
+                "description": "Возвращает доставку события с телом запроса и журналом всех попыток: время, код ответа, ошибка и длительность.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Доставка события",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID подписки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID доставки",
+                        "name": "deliveryId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Доставка",
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhookDelivery"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка в запросе",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Доставка не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}/deliveries/{deliveryId}/redeliver": {
+            "post": {
+                "description": "Ставит доставку в очередь заново с полным числом попыток, в том числе уже доставленную\nили исчерпавшую попытки. Журнал прежних попыток сохраняется.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Повторная доставка события",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID подписки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID доставки",
+                        "name": "deliveryId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Доставка поставлена в очередь",
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhookDelivery"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка в запросе",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Доставка не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "models.APIKey": {
+            "description": "Ключ доступа к API",
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "expiresAt": {
+                    "description": "Срок действия",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "lastUsedAt": {
+                    "description": "Время последнего использования",
+                    "type": "string"
+                },
+                "name": {
+                    "description": "Назначение ключа",
+                    "type": "string"
+                },
+                "prefix": {
+                    "description": "Начало ключа, по которому его можно узнать",
+                    "type": "string"
+                },
+                "rateLimits": {
+                    "description": "Лимиты запросов за период по классам read, write, enrich",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.RateLimits"
+                        }
+                    ]
+                },
+                "revokedAt": {
+                    "description": "Время отзыва",
+                    "type": "string"
+                },
+                "scopes": {
+                    "description": "Разрешения: songs:read, songs:write, songs:delete, admin",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.AuditEntry": {
+            "description": "Запись журнала изменений",
+            "type": "object",
+            "properties": {
+                "actor": {
+                    "description": "Участник: user:\u003cid\u003e, api_key:\u003cid\u003e или system",
+                    "type": "string"
+                },
+                "actorName": {
+                    "description": "Имя пользователя или ключа доступа",
+                    "type": "string"
+                },
+                "changes": {
+                    "description": "Изменения полей песни",
+                    "type": "object"
+                },
+                "createdAt": {
+                    "description": "Время изменения",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "operation": {
+                    "description": "create, update, delete или restore",
+                    "type": "string"
+                },
+                "requestId": {
+                    "description": "ID запроса, в котором сделано изменение",
+                    "type": "string"
+                },
+                "songId": {
+                    "description": "ID песни",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.BatchDeleteItem": {
+            "description": "Элемент пакетного удаления",
+            "type": "object",
+            "properties": {
+                "id": {
+                    "description": "ID песни",
+                    "type": "integer"
+                },
+                "ifMatch": {
+                    "description": "ETag удаляемой версии",
+                    "type": "string"
+                }
+            }
+        },
+        "models.BatchItemResult": {
+            "description": "Результат обработки элемента пакета",
+            "type": "object",
+            "properties": {
+                "error": {
+                    "description": "Описание ошибки",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "ID затронутой песни",
+                    "type": "integer"
+                },
+                "index": {
+                    "description": "Индекс элемента во входных данных",
+                    "type": "integer"
+                },
+                "song": {
+                    "description": "Созданная или обновленная песня",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Song"
+                        }
+                    ]
+                },
+                "status": {
+                    "description": "created, duplicate, invalid, enrichment_failed, updated, deleted, not_found, conflict, ...",
+                    "type": "string"
+                }
+            }
+        },
+        "models.BatchResponse": {
+            "description": "Ответ на пакетный запрос",
+            "type": "object",
+            "properties": {
+                "committed": {
+                    "description": "Были ли изменения сохранены в базе данных",
+                    "type": "boolean"
+                },
+                "mode": {
+                    "description": "atomic или best-effort",
+                    "type": "string"
+                },
+                "results": {
+                    "description": "Результаты в порядке входных данных",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.BatchItemResult"
+                    }
+                },
+                "summary": {
+                    "description": "Количество элементов по статусам",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "models.BatchUpdateItem": {
+            "description": "Элемент пакетного обновления",
+            "type": "object",
+            "properties": {
+                "changes": {
+                    "description": "Изменяемые поля песни",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Song"
+                        }
+                    ]
+                },
+                "id": {
+                    "description": "ID песни",
+                    "type": "integer"
+                },
+                "ifMatch": {
+                    "description": "ETag версии, к которой применяется изменение",
+                    "type": "string"
+                }
+            }
+        },
+        "models.CreateAPIKeyRequest": {
+            "description": "Параметры нового ключа доступа",
+            "type": "object",
+            "properties": {
+                "expiresAt": {
+                    "description": "Срок действия, без него ключ бессрочный",
+                    "type": "string"
+                },
+                "name": {
+                    "description": "Назначение ключа",
+                    "type": "string"
+                },
+                "rateLimits": {
+                    "description": "Лимиты запросов вместо лимитов по умолчанию",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "scopes": {
+                    "description": "Разрешения ключа",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.CreatedAPIKey": {
+            "description": "Созданный ключ доступа",
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "expiresAt": {
+                    "description": "Срок действия",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "key": {
+                    "description": "Ключ для заголовка Authorization: Bearer или X-API-Key",
+                    "type": "string"
+                },
+                "lastUsedAt": {
+                    "description": "Время последнего использования",
+                    "type": "string"
+                },
+                "name": {
+                    "description": "Назначение ключа",
+                    "type": "string"
+                },
+                "prefix": {
+                    "description": "Начало ключа, по которому его можно узнать",
+                    "type": "string"
+                },
+                "rateLimits": {
+                    "description": "Лимиты запросов за период по классам read, write, enrich",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.RateLimits"
+                        }
+                    ]
+                },
+                "revokedAt": {
+                    "description": "Время отзыва",
+                    "type": "string"
+                },
+                "scopes": {
+                    "description": "Разрешения: songs:read, songs:write, songs:delete, admin",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.CreatedWebhook": {
+            "description": "Созданная подписка на события",
+            "type": "object",
+            "properties": {
+                "active": {
+                    "description": "Отправлять ли события",
+                    "type": "boolean"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "description": {
+                    "description": "Назначение подписки",
+                    "type": "string"
+                },
+                "events": {
+                    "description": "События: song.created, song.updated, song.deleted, song.enriched",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "secret": {
+                    "description": "Секрет для проверки заголовка X-Webhook-Signature",
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "url": {
+                    "description": "Адрес, на который отправляются события",
+                    "type": "string"
+                }
+            }
+        },
+        "models.Credentials": {
+            "description": "Учетные данные пользователя",
+            "type": "object",
+            "properties": {
+                "password": {
+                    "description": "Пароль, не короче 8 символов",
+                    "type": "string"
+                },
+                "username": {
+                    "description": "Имя для входа",
+                    "type": "string"
+                }
+            }
+        },
+        "models.DBPoolStats": {
+            "description": "Статистика пула соединений",
+            "type": "object",
+            "properties": {
+                "idle": {
+                    "description": "Простаивает соединений",
+                    "type": "integer"
+                },
+                "inUse": {
+                    "description": "Занято соединений",
+                    "type": "integer"
+                },
+                "maxIdleClosed": {
+                    "description": "Закрыто из-за превышения числа простаивающих",
+                    "type": "integer"
+                },
+                "maxLifetimeClosed": {
+                    "description": "Закрыто из-за превышения времени жизни",
+                    "type": "integer"
+                },
+                "maxOpenConnections": {
+                    "description": "Максимум открытых соединений",
+                    "type": "integer"
+                },
+                "openConnections": {
+                    "description": "Открыто соединений",
+                    "type": "integer"
+                },
+                "waitCount": {
+                    "description": "Сколько раз пришлось ждать соединения",
+                    "type": "integer"
+                },
+                "waitDuration": {
+                    "description": "Суммарное время ожидания соединения",
+                    "type": "string"
+                }
+            }
+        },
+        "models.DiffHunk": {
+            "description": "Фрагмент diff",
+            "type": "object",
+            "properties": {
+                "lines": {
+                    "description": "Строки с префиксом \" \" (без изменений), \"-\" (удалена) или \"+\" (добавлена)",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "newLines": {
+                    "description": "Количество строк фрагмента в конечной ревизии",
+                    "type": "integer"
+                },
+                "newStart": {
+                    "description": "Первая строка фрагмента в конечной ревизии, с единицы",
+                    "type": "integer"
+                },
+                "oldLines": {
+                    "description": "Количество строк фрагмента в исходной ревизии",
+                    "type": "integer"
+                },
+                "oldStart": {
+                    "description": "Первая строка фрагмента в исходной ревизии, с единицы",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ErrorResponse": {
+            "description": "Структура ответа для ошибок API.",
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "Код ошибки",
+                    "type": "integer"
+                },
+                "message": {
+                    "description": "Сообщение об ошибке",
+                    "type": "string"
+                }
+            }
+        },
+        "models.HealthCheckResult": {
+            "description": "Результат проверки зависимости",
+            "type": "object",
+            "properties": {
+                "critical": {
+                    "description": "Влияет ли отказ на готовность сервиса",
+                    "type": "boolean"
+                },
+                "error": {
+                    "description": "Причина отказа",
+                    "type": "string"
+                },
+                "latencyMs": {
+                    "description": "Длительность проверки в миллисекундах",
+                    "type": "number"
+                },
+                "name": {
+                    "description": "Имя проверки: database, migrations, provider",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "up или down",
+                    "type": "string"
+                }
+            }
+        },
+        "models.HealthResponse": {
+            "description": "Состояние процесса",
+            "type": "object",
+            "properties": {
+                "status": {
+                    "description": "ok",
+                    "type": "string"
+                }
+            }
+        },
+        "models.Identity": {
+            "description": "Текущий участник запроса",
+            "type": "object",
+            "properties": {
+                "id": {
+                    "description": "ID ключа доступа или пользователя",
+                    "type": "integer"
+                },
+                "name": {
+                    "description": "Имя ключа доступа или пользователя",
+                    "type": "string"
+                },
+                "role": {
+                    "description": "Роль пользователя",
+                    "type": "string"
+                },
+                "scopes": {
+                    "description": "Разрешения",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "type": {
+                    "description": "anonymous, api_key или user",
+                    "type": "string"
+                }
+            }
+        },
+        "models.ImportJob": {
+            "description": "Задание импорта каталога",
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "createdRows": {
+                    "description": "Создано песен",
+                    "type": "integer"
+                },
+                "deletedAt": {
+                    "type": "string"
+                },
+                "delimiter": {
+                    "description": "Разделитель колонок CSV",
+                    "type": "string"
+                },
+                "dryRun": {
+                    "description": "Пробный запуск без изменения данных",
+                    "type": "boolean"
+                },
+                "enrich": {
+                    "description": "Запрашивать ли данные песен во внешнем API",
+                    "type": "boolean"
+                },
+                "failedRows": {
+                    "description": "Записей с ошибками",
+                    "type": "integer"
+                },
+                "fileName": {
+                    "description": "Имя исходного файла",
+                    "type": "string"
+                },
+                "finishedAt": {
+                    "description": "Время завершения",
+                    "type": "string"
+                },
+                "format": {
+                    "description": "csv, json или ndjson",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "lastError": {
+                    "description": "Причина остановки задания",
+                    "type": "string"
+                },
+                "mapping": {
+                    "description": "Сопоставление полей песни с колонками файла",
+                    "type": "string"
+                },
+                "onDuplicate": {
+                    "description": "skip или update",
+                    "type": "string"
+                },
+                "processedRows": {
+                    "description": "Количество обработанных записей",
+                    "type": "integer"
+                },
+                "skippedRows": {
+                    "description": "Пропущено дубликатов",
+                    "type": "integer"
+                },
+                "startedAt": {
+                    "description": "Время запуска",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "pending, running, completed, failed",
+                    "type": "string"
+                },
+                "totalRows": {
+                    "description": "Количество записей в файле",
+                    "type": "integer"
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "updatedRows": {
+                    "description": "Обновлено песен",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ImportReport": {
+            "description": "Отчет об импорте",
+            "type": "object",
+            "properties": {
+                "job": {
+                    "description": "Задание импорта",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.ImportJob"
+                        }
+                    ]
+                },
+                "rows": {
+                    "description": "Результаты по записям",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ImportRowResult"
+                    }
+                }
+            }
+        },
+        "models.ImportRowResult": {
+            "description": "Результат обработки записи импорта",
+            "type": "object",
+            "properties": {
+                "action": {
+                    "description": "created, updated, skipped или failed",
+                    "type": "string"
+                },
+                "error": {
+                    "description": "Описание ошибки",
+                    "type": "string"
+                },
+                "group": {
+                    "description": "Группа",
+                    "type": "string"
+                },
+                "row": {
+                    "description": "Номер записи в файле",
+                    "type": "integer"
+                },
+                "song": {
+                    "description": "Название песни",
+                    "type": "string"
+                },
+                "songId": {
+                    "description": "ID существующей или созданной песни",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.PlaylistEntryMatch": {
+            "description": "Запись импортированного плейлиста",
+            "type": "object",
+            "properties": {
+                "duration": {
+                    "description": "Длительность в секундах",
+                    "type": "integer"
+                },
+                "group": {
+                    "description": "Исполнитель из плейлиста",
+                    "type": "string"
+                },
+                "index": {
+                    "description": "Порядковый номер записи в плейлисте",
+                    "type": "integer"
+                },
+                "location": {
+                    "description": "Ссылка или путь из плейлиста",
+                    "type": "string"
+                },
+                "matched": {
+                    "description": "Найдена ли песня в каталоге",
+                    "type": "boolean"
+                },
+                "song": {
+                    "description": "Название из плейлиста",
+                    "type": "string"
+                },
+                "songId": {
+                    "description": "ID найденной песни",
+                    "type": "integer"
+                },
+                "suggestions": {
+                    "description": "Похожие песни для несопоставленной записи",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.PlaylistSuggestion"
+                    }
+                }
+            }
+        },
+        "models.PlaylistImportResult": {
+            "description": "Результат импорта плейлиста",
+            "type": "object",
+            "properties": {
+                "entries": {
+                    "description": "Записи в порядке плейлиста",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.PlaylistEntryMatch"
+                    }
+                },
+                "format": {
+                    "description": "m3u8 или xspf",
+                    "type": "string"
+                },
+                "matched": {
+                    "description": "Количество сопоставленных записей",
+                    "type": "integer"
+                },
+                "total": {
+                    "description": "Количество записей в плейлисте",
+                    "type": "integer"
+                },
+                "unmatched": {
+                    "description": "Количество несопоставленных записей",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.PlaylistSuggestion": {
+            "description": "Предлагаемая песня для записи плейлиста",
+            "type": "object",
+            "properties": {
+                "group": {
+                    "description": "Группа",
+                    "type": "string"
+                },
+                "score": {
+                    "description": "Степень сходства от 0 до 1",
+                    "type": "number"
+                },
+                "song": {
+                    "description": "Название песни",
+                    "type": "string"
+                },
+                "songId": {
+                    "description": "ID песни",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.RateLimits": {
+            "type": "object",
+            "additionalProperties": {
+                "type": "integer"
+            }
+        },
+        "models.ReadinessResponse": {
+            "description": "Готовность сервиса принимать запросы",
+            "type": "object",
+            "properties": {
+                "checks": {
+                    "description": "Результаты проверок",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.HealthCheckResult"
+                    }
+                },
+                "status": {
+                    "description": "ready или not_ready",
+                    "type": "string"
+                }
+            }
+        },
+        "models.RefreshRequest": {
+            "description": "Токен обновления",
+            "type": "object",
+            "properties": {
+                "refreshToken": {
+                    "description": "Токен обновления, полученный при входе",
+                    "type": "string"
+                }
+            }
+        },
+        "models.RestoreResult": {
+            "type": "object",
+            "properties": {
+                "mode": {
+                    "description": "empty или merge",
+                    "type": "string"
+                },
+                "remappedIds": {
+                    "description": "Количество записей, получивших новый ID",
+                    "type": "integer"
+                },
+                "schemaVersion": {
+                    "description": "Версия схемы, с которой снята копия",
+                    "type": "integer"
+                },
+                "tables": {
+                    "description": "Результат по таблицам",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.RestoreTableResult"
+                    }
+                }
+            }
+        },
+        "models.RestoreTableResult": {
+            "type": "object",
+            "properties": {
+                "created": {
+                    "description": "Добавлено записей",
+                    "type": "integer"
+                },
+                "name": {
+                    "description": "Имя таблицы",
+                    "type": "string"
+                },
+                "rows": {
+                    "description": "Записей в резервной копии",
+                    "type": "integer"
+                },
+                "skipped": {
+                    "description": "Пропущено записей, уже существующих в библиотеке",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.RevisionDiff": {
+            "description": "Diff текста между ревизиями",
+            "type": "object",
+            "properties": {
+                "from": {
+                    "description": "Исходная ревизия",
+                    "type": "integer"
+                },
+                "hunks": {
+                    "description": "Фрагменты с изменениями, пустой список - тексты совпадают",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.DiffHunk"
+                    }
+                },
+                "songId": {
+                    "description": "ID песни",
+                    "type": "integer"
+                },
+                "to": {
+                    "description": "Конечная ревизия",
+                    "type": "integer"
                 }
             }
         },
@@ -282,12 +3257,48 @@ const docTemplate = `{
             "description": "Структура песни",
             "type": "object",
             "properties": {
+                "album": {
+                    "description": "Альбом",
+                    "type": "string"
+                },
+                "bitrate": {
+                    "description": "Битрейт в кбит/с",
+                    "type": "integer"
+                },
+                "contentHash": {
+                    "description": "SHA-256 содержимого аудиофайла",
+                    "type": "string"
+                },
                 "createdAt": {
                     "type": "string"
                 },
+                "createdBy": {
+                    "description": "ID пользователя, создавшего песню",
+                    "type": "integer"
+                },
                 "deletedAt": {
                     "type": "string"
                 },
+                "duration": {
+                    "description": "Длительность в секундах",
+                    "type": "integer"
+                },
+                "etag": {
+                    "description": "ETag текущей версии записи",
+                    "type": "string"
+                },
+                "fileModTime": {
+                    "description": "Время изменения аудиофайла при последнем сканировании",
+                    "type": "string"
+                },
+                "filePath": {
+                    "description": "Путь к локальному аудиофайлу",
+                    "type": "string"
+                },
+                "fileSize": {
+                    "description": "Размер аудиофайла в байтах",
+                    "type": "integer"
+                },
                 "group": {
                     "description": "Группа или исполнитель",
                     "type": "string"
@@ -313,6 +3324,354 @@ const docTemplate = `{
                 },
                 "updatedAt": {
                     "type": "string"
+                },
+                "updatedBy": {
+                    "description": "ID пользователя, последним изменившего песню",
+                    "type": "integer"
+                },
+                "version": {
+                    "description": "Версия записи для оптимистичной блокировки",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.SongEvent": {
+            "description": "Событие песни",
+            "type": "object",
+            "properties": {
+                "changes": {
+                    "description": "Изменения полей для song.updated",
+                    "type": "object"
+                },
+                "createdAt": {
+                    "description": "Время изменения",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "ID события, растет в порядке изменений",
+                    "type": "integer"
+                },
+                "song": {
+                    "description": "Песня после изменения, для song.deleted - перед удалением",
+                    "type": "object"
+                },
+                "songId": {
+                    "description": "ID песни",
+                    "type": "integer"
+                },
+                "type": {
+                    "description": "song.created, song.updated, song.deleted или song.enriched",
+                    "type": "string"
+                }
+            }
+        },
+        "models.SongRevision": {
+            "description": "Ревизия песни",
+            "type": "object",
+            "properties": {
+                "actor": {
+                    "description": "Участник: user:\u003cid\u003e, api_key:\u003cid\u003e или system",
+                    "type": "string"
+                },
+                "actorName": {
+                    "description": "Имя пользователя или ключа доступа",
+                    "type": "string"
+                },
+                "album": {
+                    "description": "Альбом",
+                    "type": "string"
+                },
+                "createdAt": {
+                    "description": "Время создания ревизии",
+                    "type": "string"
+                },
+                "group": {
+                    "description": "Группа или исполнитель",
+                    "type": "string"
+                },
+                "link": {
+                    "description": "Ссылка на песню",
+                    "type": "string"
+                },
+                "releaseDate": {
+                    "description": "Дата релиза",
+                    "type": "string"
+                },
+                "revision": {
+                    "description": "Номер ревизии, совпадает с версией песни",
+                    "type": "integer"
+                },
+                "song": {
+                    "description": "Название песни",
+                    "type": "string"
+                },
+                "songId": {
+                    "description": "ID песни",
+                    "type": "integer"
+                },
+                "text": {
+                    "description": "Текст песни",
+                    "type": "string"
+                }
+            }
+        },
+        "models.StatusResponse": {
+            "description": "Подробное состояние сервиса",
+            "type": "object",
+            "properties": {
+                "checks": {
+                    "description": "Результаты проверок с задержками",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.HealthCheckResult"
+                    }
+                },
+                "database": {
+                    "description": "Пул соединений с базой данных",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.DBPoolStats"
+                        }
+                    ]
+                },
+                "goVersion": {
+                    "description": "Версия Go",
+                    "type": "string"
+                },
+                "revision": {
+                    "description": "Ревизия VCS сборки",
+                    "type": "string"
+                },
+                "schemaVersion": {
+                    "description": "Версия схемы, ожидаемая сборкой",
+                    "type": "integer"
+                },
+                "startedAt": {
+                    "description": "Время запуска",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "ready или not_ready",
+                    "type": "string"
+                },
+                "uptime": {
+                    "description": "Время работы",
+                    "type": "string"
+                },
+                "uptimeSeconds": {
+                    "description": "Время работы в секундах",
+                    "type": "integer"
+                },
+                "version": {
+                    "description": "Версия сборки",
+                    "type": "string"
+                }
+            }
+        },
+        "models.TokenResponse": {
+            "description": "Токены пользователя",
+            "type": "object",
+            "properties": {
+                "accessToken": {
+                    "description": "Токен доступа для заголовка Authorization: Bearer",
+                    "type": "string"
+                },
+                "expiresIn": {
+                    "description": "Время жизни токена доступа в секундах",
+                    "type": "integer"
+                },
+                "refreshToken": {
+                    "description": "Одноразовый токен обновления",
+                    "type": "string"
+                },
+                "tokenType": {
+                    "description": "Всегда Bearer",
+                    "type": "string"
+                }
+            }
+        },
+        "models.UpdateUserRequest": {
+            "description": "Новая роль пользователя",
+            "type": "object",
+            "properties": {
+                "role": {
+                    "description": "viewer, editor или admin",
+                    "type": "string"
+                }
+            }
+        },
+        "models.User": {
+            "description": "Пользователь",
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "role": {
+                    "description": "viewer, editor или admin",
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "username": {
+                    "description": "Имя для входа",
+                    "type": "string"
+                }
+            }
+        },
+        "models.Webhook": {
+            "description": "Подписка на события",
+            "type": "object",
+            "properties": {
+                "active": {
+                    "description": "Отправлять ли события",
+                    "type": "boolean"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "description": {
+                    "description": "Назначение подписки",
+                    "type": "string"
+                },
+                "events": {
+                    "description": "События: song.created, song.updated, song.deleted, song.enriched",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "url": {
+                    "description": "Адрес, на который отправляются события",
+                    "type": "string"
+                }
+            }
+        },
+        "models.WebhookAttempt": {
+            "description": "Попытка доставки события",
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "description": "Время попытки",
+                    "type": "string"
+                },
+                "durationMs": {
+                    "description": "Длительность запроса в миллисекундах",
+                    "type": "integer"
+                },
+                "error": {
+                    "description": "Ошибка запроса или текст неуспешного ответа",
+                    "type": "string"
+                },
+                "responseStatus": {
+                    "description": "Код ответа подписчика",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.WebhookDelivery": {
+            "description": "Доставка события",
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "description": "Сделано попыток",
+                    "type": "integer"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "deliveredAt": {
+                    "description": "Время успешной доставки",
+                    "type": "string"
+                },
+                "event": {
+                    "description": "Тип события",
+                    "type": "string"
+                },
+                "eventId": {
+                    "description": "ID события в журнале событий, общий для всех подписок",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "lastAttemptAt": {
+                    "description": "Время последней попытки",
+                    "type": "string"
+                },
+                "lastError": {
+                    "description": "Ошибка последней попытки",
+                    "type": "string"
+                },
+                "log": {
+                    "description": "Попытки доставки, только для одной доставки",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.WebhookAttempt"
+                    }
+                },
+                "nextAttemptAt": {
+                    "description": "Время следующей попытки",
+                    "type": "string"
+                },
+                "payload": {
+                    "description": "Тело запроса к подписчику",
+                    "type": "object"
+                },
+                "responseStatus": {
+                    "description": "Код ответа на последнюю попытку",
+                    "type": "integer"
+                },
+                "songId": {
+                    "description": "ID песни",
+                    "type": "integer"
+                },
+                "status": {
+                    "description": "pending, delivered или failed",
+                    "type": "string"
+                },
+                "webhookId": {
+                    "description": "ID подписки",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.WebhookRequest": {
+            "description": "Параметры подписки на события",
+            "type": "object",
+            "properties": {
+                "active": {
+                    "description": "Отправлять ли события, по умолчанию true",
+                    "type": "boolean"
+                },
+                "description": {
+                    "description": "Назначение подписки",
+                    "type": "string"
+                },
+                "events": {
+                    "description": "События подписки",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "secret": {
+                    "description": "Секрет подписи, по умолчанию генерируется",
+                    "type": "string"
+                },
+                "url": {
+                    "description": "Адрес http или https",
+                    "type": "string"
                 }
             }
         }